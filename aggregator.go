@@ -0,0 +1,23 @@
+package movingaverage
+
+// Aggregator is a custom aggregate which is updated incrementally, in lockstep
+// with the values in a moving stats instance. Aggregators are attached to an
+// instance via Options.Aggregators.
+//
+// Aggregators allow users to maintain O(1) aggregates (e.g. weighted sums or
+// custom indices) without recomputing them over the whole window.
+//
+// When reading the Value() of an Aggregator attached to a concurrency-safe
+// instance, do so from within a function passed to UnsafeDo so the instance's
+// read lock is held.
+type Aggregator interface {
+	// OnAdd is called when a value is added to the moving stats instance.
+	OnAdd(value float64)
+
+	// OnEvict is called when a value is evicted from the moving stats instance.
+	// It is called before OnAdd is called for the value replacing it.
+	OnEvict(value float64)
+
+	// Value returns the current value of the aggregate.
+	Value() float64
+}
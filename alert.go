@@ -0,0 +1,209 @@
+package movingaverage
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Comparator compares a statistic to an AlertRule's threshold.
+type Comparator int
+
+const (
+	// Above matches statistics greater than the threshold.
+	Above Comparator = iota
+	// AtOrAbove matches statistics greater than or equal to the threshold.
+	AtOrAbove
+	// Below matches statistics less than the threshold.
+	Below
+	// AtOrBelow matches statistics less than or equal to the threshold.
+	AtOrBelow
+)
+
+func (c Comparator) compare(v, threshold float64) bool {
+	switch c {
+	case Above:
+		return v > threshold
+	case AtOrAbove:
+		return v >= threshold
+	case Below:
+		return v < threshold
+	case AtOrBelow:
+		return v <= threshold
+	}
+	return false
+}
+
+// AlertRule is a named condition on a statistic of a MovingStats instance's window,
+// e.g. "the P99 has been above 250 for 5 minutes".
+type AlertRule struct {
+	// The rule's name, which identifies it within an AlertManager.
+	Name string
+
+	// The statistic to compare to the threshold.
+	Stat StatKind
+
+	// How to compare the statistic to the threshold.
+	Comparator Comparator
+
+	// The threshold.
+	Threshold float64
+
+	// How long the condition must hold before the rule fires.
+	// If zero, the rule fires as soon as the condition holds.
+	For time.Duration
+}
+
+// AlertState is the state an AlertEvent reports a rule transitioned to.
+type AlertState int
+
+const (
+	// AlertFiring means the rule's condition has held for the rule's For duration.
+	AlertFiring AlertState = iota
+	// AlertResolved means the condition of a firing rule no longer holds.
+	AlertResolved
+)
+
+// AlertEvent reports that an AlertRule started firing or was resolved.
+type AlertEvent struct {
+	// The name of the rule.
+	Rule string
+
+	// The state the rule transitioned to.
+	State AlertState
+
+	// The value of the rule's statistic at the time of the transition.
+	Value float64
+
+	// The time of the transition.
+	Time time.Time
+}
+
+// AlertManager evaluates a set of named AlertRules against a MovingStats instance each time
+// values are added through it, calling a notify function when a rule starts firing or is
+// resolved.
+//
+// Rules are evaluated on Add, or on demand via Evaluate (e.g. periodically, for windows
+// whose values expire by age). Values must be added through the manager's Add, not the
+// instance's, for the rules to be evaluated as they arrive: values added to the instance
+// directly aren't seen until the next Add or Evaluate. AlertManager is safe for concurrent use by multiple
+// goroutines, provided the MovingStats instance is (i.e. it was created by NewConcurrent).
+type AlertManager struct {
+	ms     MovingStats
+	notify func(AlertEvent)
+	rules  []alertRuleState
+	now    func() time.Time
+	mux    sync.Mutex
+}
+
+type alertRuleState struct {
+	AlertRule
+	pendingSince time.Time
+	firing       bool
+}
+
+// NewAlertManager returns a new AlertManager, with no rules, over the given MovingStats instance.
+// notify is called, outside the manager's lock, with each event as it occurs; if rules are
+// evaluated from multiple goroutines, notify may be called concurrently.
+func NewAlertManager(ms MovingStats, notify func(AlertEvent)) *AlertManager {
+	return &AlertManager{
+		ms:     ms,
+		notify: notify,
+		now:    time.Now,
+	}
+}
+
+// AddRule adds a rule to the manager. Adding a rule with the same name as an existing
+// rule replaces that rule, without notifying that it was resolved.
+func (m *AlertManager) AddRule(rule AlertRule) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	state := alertRuleState{AlertRule: rule}
+	if i := m.index(rule.Name); i >= 0 {
+		m.rules[i] = state
+		return
+	}
+	m.rules = append(m.rules, state)
+}
+
+// RemoveRule removes the rule with the given name, if any, without notifying that it was resolved.
+func (m *AlertManager) RemoveRule(name string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if i := m.index(name); i >= 0 {
+		m.rules = slices.Delete(m.rules, i, i+1)
+	}
+}
+
+func (m *AlertManager) index(name string) int {
+	return slices.IndexFunc(m.rules, func(r alertRuleState) bool {
+		return r.Name == name
+	})
+}
+
+// Add adds the given values to the MovingStats instance, then evaluates the rules.
+func (m *AlertManager) Add(values ...float64) {
+	m.ms.Add(values...)
+	m.Evaluate()
+}
+
+// Evaluate evaluates the rules against the MovingStats instance's current window,
+// notifying of any transitions. While the window holds fewer than Options.MinSamples values
+// (or none), whose statistics would be 0.0, the rules aren't evaluated and keep their state.
+func (m *AlertManager) Evaluate() {
+	m.mux.Lock()
+	kinds := make([]StatKind, len(m.rules))
+	for i, r := range m.rules {
+		kinds[i] = r.Stat
+	}
+	var computed map[StatKind]float64
+	m.ms.DoLocked(func(view ReadOnlyView) {
+		if hasStatValues(view) {
+			computed = view.Compute(kinds...)
+		}
+	})
+	if computed == nil {
+		m.mux.Unlock()
+		return
+	}
+	now := m.now()
+
+	var events []AlertEvent
+	for i := range m.rules {
+		r := &m.rules[i]
+		v := computed[r.Stat]
+		if !r.Comparator.compare(v, r.Threshold) {
+			r.pendingSince = time.Time{}
+			if r.firing {
+				r.firing = false
+				events = append(events, AlertEvent{Rule: r.Name, State: AlertResolved, Value: v, Time: now})
+			}
+			continue
+		}
+		if r.pendingSince.IsZero() {
+			r.pendingSince = now
+		}
+		if !r.firing && now.Sub(r.pendingSince) >= r.For {
+			r.firing = true
+			events = append(events, AlertEvent{Rule: r.Name, State: AlertFiring, Value: v, Time: now})
+		}
+	}
+	m.mux.Unlock()
+
+	for _, e := range events {
+		m.notify(e)
+	}
+}
+
+// Firing returns the names of the rules which are currently firing, in the order they were added.
+func (m *AlertManager) Firing() []string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	var retv []string
+	for _, r := range m.rules {
+		if r.firing {
+			retv = append(retv, r.Name)
+		}
+	}
+	return retv
+}
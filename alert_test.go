@@ -0,0 +1,81 @@
+package movingaverage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAlertManager(t *testing.T) {
+	var events []AlertEvent
+	m := NewAlertManager(New(Options{Window: 2}), func(e AlertEvent) {
+		events = append(events, e)
+	})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	m.AddRule(AlertRule{Name: "high", Stat: StatAvg, Comparator: Above, Threshold: 10})
+	m.AddRule(AlertRule{Name: "sustained", Stat: StatMax, Comparator: AtOrAbove, Threshold: 20, For: time.Minute})
+	m.AddRule(AlertRule{Name: "low", Stat: StatMin, Comparator: Below, Threshold: 0})
+
+	m.Add(5)
+	if len(events) != 0 {
+		t.Error(events)
+	}
+
+	m.Add(20) // avg 12.5, max 20
+	if len(events) != 1 || events[0].Rule != "high" || events[0].State != AlertFiring || events[0].Value != 12.5 {
+		t.Fatal(events)
+	}
+
+	now = now.Add(30 * time.Second)
+	m.Add(20) // still pending
+	if len(events) != 1 {
+		t.Fatal(events)
+	}
+	now = now.Add(30 * time.Second)
+	m.Evaluate()
+	if len(events) != 2 || events[1].Rule != "sustained" || events[1].State != AlertFiring {
+		t.Fatal(events)
+	}
+	if !slices.Equal(m.Firing(), []string{"high", "sustained"}) {
+		t.Error(m.Firing())
+	}
+
+	m.Add(1, 1)
+	if len(events) != 4 || events[2].State != AlertResolved || events[3].State != AlertResolved {
+		t.Fatal(events)
+	}
+	if len(m.Firing()) != 0 {
+		t.Error(m.Firing())
+	}
+
+	// replacing and removing rules
+	m.AddRule(AlertRule{Name: "high", Stat: StatAvg, Comparator: AtOrBelow, Threshold: 1})
+	m.RemoveRule("sustained")
+	m.RemoveRule("nonexistent")
+	m.Evaluate()
+	if !slices.Equal(m.Firing(), []string{"high"}) {
+		t.Error(m.Firing())
+	}
+}
+
+func TestAlertManagerMinSamples(t *testing.T) {
+	var events []AlertEvent
+	m := NewAlertManager(New(Options{Window: 4, MinSamples: 2}), func(e AlertEvent) {
+		events = append(events, e)
+	})
+	m.AddRule(AlertRule{Name: "low", Stat: StatAvg, Comparator: Below, Threshold: 1})
+
+	// the statistics of an empty or barely-filled window are 0.0, which is below 1
+	m.Evaluate()
+	m.Add(5)
+	if len(events) != 0 {
+		t.Fatal(events)
+	}
+
+	m.Add(-5)
+	if len(events) != 1 || events[0].State != AlertFiring || events[0].Value != 0 {
+		t.Error(events)
+	}
+}
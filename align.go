@@ -0,0 +1,67 @@
+package movingaverage
+
+import (
+	"time"
+)
+
+// AlignedSample is a pair of samples from two series, matched by AlignedPair.
+type AlignedSample struct {
+	// The time of the sample from the first series.
+	Time time.Time
+
+	// The values of the samples from the first and second series.
+	A, B float64
+}
+
+// AlignedPair matches the samples of two irregularly sampled series by time, for feeding
+// to pairwise trackers such as Spread and Ratio (or to CrossCorrelation, via two instances),
+// which otherwise assume their inputs arrive in lockstep:
+//
+//	for _, s := range movingaverage.AlignedPair(aTimes, aVals, bTimes, bVals, time.Second) {
+//		spread.Add(s.A, s.B)
+//	}
+//
+// Each series' times must be in ascending order; a series' values are matched with its times
+// by index, and any values or times without a counterpart are ignored. Samples are matched
+// one-to-one: a sample from each series is paired when each is the other's nearest sample in
+// time and they are at most tolerance apart. (Of two equally near samples, a sample from the
+// first series is matched with the later, and one from the second series with the earlier.)
+// Unmatched samples are dropped. The matched pairs are returned in chronological order.
+func AlignedPair(aTimes []time.Time, aVals []float64, bTimes []time.Time, bVals []float64, tolerance time.Duration) []AlignedSample {
+	na := min(len(aTimes), len(aVals))
+	nb := min(len(bTimes), len(bVals))
+	if na == 0 || nb == 0 {
+		return nil
+	}
+
+	var retv []AlignedSample
+	j := 0
+	for i := 0; i < na; i++ {
+		at := aTimes[i]
+		// Find the b sample nearest to a[i]; since both series are in ascending order,
+		// it's never before the one nearest to a[i-1]
+		for j+1 < nb && absDuration(bTimes[j+1].Sub(at)) <= absDuration(bTimes[j].Sub(at)) {
+			j++
+		}
+		d := absDuration(bTimes[j].Sub(at))
+		if d > tolerance {
+			continue
+		}
+		// Check a[i] is also the a sample nearest to b[j]
+		if i > 0 && absDuration(aTimes[i-1].Sub(bTimes[j])) <= d {
+			continue
+		}
+		if i+1 < na && absDuration(aTimes[i+1].Sub(bTimes[j])) < d {
+			continue
+		}
+		retv = append(retv, AlignedSample{Time: at, A: aVals[i], B: bVals[j]})
+	}
+	return retv
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,42 @@
+package movingaverage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAlignedPair(t *testing.T) {
+	start := time.Now()
+	at := func(ms ...int) []time.Time {
+		retv := make([]time.Time, len(ms))
+		for i, m := range ms {
+			retv[i] = start.Add(time.Duration(m) * time.Millisecond)
+		}
+		return retv
+	}
+
+	got := AlignedPair(
+		at(0, 100, 200, 300), []float64{1, 2, 3, 4},
+		at(10, 120, 130, 400), []float64{10, 20, 30, 40},
+		30*time.Millisecond,
+	)
+	want := []AlignedSample{{at(0)[0], 1, 10}, {at(100)[0], 2, 20}}
+	if !slices.Equal(got, want) {
+		t.Error(got)
+	}
+
+	// samples are matched one-to-one, with the nearest sample
+	got = AlignedPair(at(0, 10), []float64{1, 2}, at(6), []float64{10}, time.Second)
+	if !slices.Equal(got, []AlignedSample{{at(10)[0], 2, 10}}) {
+		t.Error(got)
+	}
+	got = AlignedPair(at(0, 10), []float64{1, 2}, at(5), []float64{10}, time.Second)
+	if !slices.Equal(got, []AlignedSample{{at(0)[0], 1, 10}}) {
+		t.Error(got)
+	}
+
+	if got := AlignedPair(at(0), []float64{1}, nil, nil, time.Second); got != nil {
+		t.Error(got)
+	}
+}
@@ -0,0 +1,73 @@
+package movingaverage
+
+import (
+	"testing"
+	"time"
+)
+
+// readPathInstances returns populated instances covering each read path implementation.
+func readPathInstances() map[string]MovingStats {
+	instances := map[string]MovingStats{
+		"New":           New(Options{Window: 100}),
+		"NewConcurrent": NewConcurrent(Options{Window: 100}),
+		"MaxAge":        NewConcurrent(Options{Window: 100, MaxAge: time.Hour}),
+	}
+	for _, ms := range instances {
+		for i := 0; i < 150; i++ {
+			ms.Add(float64(i))
+		}
+	}
+	return instances
+}
+
+func TestReadPathAllocs(t *testing.T) {
+	for name, ms := range readPathInstances() {
+		for method, f := range map[string]func(){
+			"Avg":          func() { _ = ms.Avg() },
+			"Sum":          func() { _ = ms.Sum() },
+			"SumOfSquares": func() { _ = ms.SumOfSquares() },
+			"Product":      func() { _ = ms.Product() },
+			"Min":          func() { _ = ms.Min() },
+			"Max":          func() { _ = ms.Max() },
+			"MinMax":       func() { _, _ = ms.MinMax() },
+			"Count":        func() { _ = ms.Count() },
+			"CountAbove":   func() { _ = ms.CountAbove(50) },
+			"CountBelow":   func() { _ = ms.CountBelow(50) },
+			"SlotsFilled":  func() { _ = ms.SlotsFilled() },
+			"Summary":      func() { _ = ms.Summary() },
+		} {
+			if n := testing.AllocsPerRun(100, f); n != 0 {
+				t.Errorf("%s: %s allocated %v times per run", name, method, n)
+			}
+		}
+	}
+}
+
+func BenchmarkReadPath(b *testing.B) {
+	for name, ms := range readPathInstances() {
+		b.Run(name+"/Avg", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = ms.Avg()
+			}
+		})
+		b.Run(name+"/MinMax", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = ms.MinMax()
+			}
+		})
+		b.Run(name+"/Count", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = ms.Count()
+			}
+		})
+		b.Run(name+"/Summary", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = ms.Summary()
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package movingaverage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// The binary snapshot format written by WriteTo and read by ReadFrom is:
+//
+//	magic   [4]byte  "MVST"
+//	version byte     1
+//	flags   byte     bit 0 set if times follow the values
+//	window  uvarint
+//	count   uvarint
+//	values  count big-endian float64s, oldest first
+//	times   count big-endian int64 Unix times in nanoseconds, if flagged
+const (
+	binarySnapshotMagic   = "MVST"
+	binarySnapshotVersion = 1
+	binaryFlagTimes       = 1 << 0
+)
+
+// binaryChunkValues is the number of values encoded per write, bounding the scratch
+// space used by writeBinarySnapshot regardless of the window size.
+const binaryChunkValues = 512
+
+var errBinaryShort = errors.New("movingaverage: unexpected end of binary snapshot")
+
+// writeBinarySnapshot writes the given state to w in the binary snapshot format,
+// and returns the number of bytes written.
+func writeBinarySnapshot(w io.Writer, window int, values []float64, times []time.Time) (int64, error) {
+	buf := make([]byte, 0, 8*binaryChunkValues)
+	buf = append(buf, binarySnapshotMagic...)
+	buf = append(buf, binarySnapshotVersion)
+	if times != nil {
+		buf = append(buf, binaryFlagTimes)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = binary.AppendUvarint(buf, uint64(max(window, 0)))
+	buf = binary.AppendUvarint(buf, uint64(len(values)))
+
+	var written int64
+	flush := func() error {
+		n, err := w.Write(buf)
+		written += int64(n)
+		buf = buf[:0]
+		return err
+	}
+
+	for _, v := range values {
+		if len(buf)+8 > cap(buf) {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+		buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(v))
+	}
+	for _, t := range times {
+		if len(buf)+8 > cap(buf) {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+		buf = binary.BigEndian.AppendUint64(buf, uint64(t.UnixNano()))
+	}
+	if err := flush(); err != nil {
+		return written, err
+	}
+	return written, nil
+}
+
+// readBinarySnapshot reads one snapshot in the binary snapshot format from r,
+// and returns it along with the number of bytes read.
+func readBinarySnapshot(r io.Reader) (Snapshot, int64, error) {
+	cr := &countingReader{r: r}
+
+	header := make([]byte, len(binarySnapshotMagic)+2)
+	if _, err := io.ReadFull(cr, header); err != nil {
+		return Snapshot{}, cr.n, binaryReadError(err)
+	}
+	if string(header[:len(binarySnapshotMagic)]) != binarySnapshotMagic {
+		return Snapshot{}, cr.n, errors.New("movingaverage: not a binary snapshot")
+	}
+	if version := header[len(binarySnapshotMagic)]; version != binarySnapshotVersion {
+		return Snapshot{}, cr.n, fmt.Errorf("movingaverage: unsupported binary snapshot version %d", version)
+	}
+	hasTimes := header[len(binarySnapshotMagic)+1]&binaryFlagTimes != 0
+
+	window, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return Snapshot{}, cr.n, binaryReadError(err)
+	}
+	count, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return Snapshot{}, cr.n, binaryReadError(err)
+	}
+	if window > math.MaxInt32 || count > math.MaxInt32 {
+		return Snapshot{}, cr.n, errors.New("movingaverage: binary snapshot too large")
+	}
+
+	// Read in chunks, so a corrupt count can't cause a huge allocation up front
+	chunk := make([]byte, 8*binaryChunkValues)
+	readUint64s := func(f func(uint64)) error {
+		for remaining := int(count); remaining > 0; {
+			n := min(remaining, binaryChunkValues)
+			if _, err := io.ReadFull(cr, chunk[:8*n]); err != nil {
+				return binaryReadError(err)
+			}
+			for i := 0; i < n; i++ {
+				f(binary.BigEndian.Uint64(chunk[8*i:]))
+			}
+			remaining -= n
+		}
+		return nil
+	}
+
+	s := Snapshot{
+		Window: int(window),
+		Values: make([]float64, 0, min(int(count), binaryChunkValues)),
+	}
+	if err := readUint64s(func(u uint64) { s.Values = append(s.Values, math.Float64frombits(u)) }); err != nil {
+		return Snapshot{}, cr.n, err
+	}
+	if hasTimes {
+		s.Times = make([]time.Time, 0, len(s.Values))
+		if err := readUint64s(func(u uint64) { s.Times = append(s.Times, time.Unix(0, int64(u))) }); err != nil {
+			return Snapshot{}, cr.n, err
+		}
+	}
+	return s, cr.n, nil
+}
+
+func binaryReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errBinaryShort
+	}
+	return err
+}
+
+// countingReader counts the bytes read from r. It implements io.ByteReader,
+// reading one byte at a time, so binary.ReadUvarint doesn't read past the varint.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	one [1]byte
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	if _, err := io.ReadFull(c, c.one[:]); err != nil {
+		return 0, err
+	}
+	return c.one[0], nil
+}
+
+func (ma *movingStats) WriteTo(w io.Writer) (int64, error) {
+	values, times := ma.live()
+	return writeBinarySnapshot(w, ma.window, values, times)
+}
+
+func (ma *movingStats) ReadFrom(r io.Reader) (int64, error) {
+	s, n, err := readBinarySnapshot(r)
+	if err != nil {
+		return n, err
+	}
+	ma.Restore(s)
+	return n, nil
+}
@@ -0,0 +1,81 @@
+package movingaverage
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWriteToReadFrom(t *testing.T) {
+	src := NewConcurrent(Options{Window: 2000})
+	for i := 0; i < 1500; i++ {
+		src.Add(float64(i) / 3)
+	}
+
+	var buf bytes.Buffer
+	n, err := src.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatal(n, buf.Len(), err)
+	}
+	buf.WriteString("trailing")
+
+	dst := New(Options{Window: 2000})
+	dst.Add(-1)
+	m, err := dst.ReadFrom(&buf)
+	if err != nil || m != n {
+		t.Fatal(m, n, err)
+	}
+	if !slices.Equal(dst.Values(), src.Values()) {
+		t.Error("values differ")
+	}
+	// ReadFrom reads exactly one snapshot
+	if rest, _ := io.ReadAll(&buf); string(rest) != "trailing" {
+		t.Error(string(rest))
+	}
+}
+
+func TestWriteToReadFromTimes(t *testing.T) {
+	now := time.Now()
+	src := newMovingStats(Options{Window: 3, MaxAge: time.Hour})
+	src.now = func() time.Time { return now }
+	src.Add(1, 2)
+	now = now.Add(time.Minute)
+	src.Add(3)
+
+	var buf bytes.Buffer
+	if _, err := src.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dst := newMovingStats(Options{Window: 3, MaxAge: time.Hour})
+	dst.now = src.now
+	if _, err := dst.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(dst.Snapshot().Times, src.Snapshot().Times, time.Time.Equal) {
+		t.Error(dst.Snapshot().Times, src.Snapshot().Times)
+	}
+}
+
+func TestReadFromInvalid(t *testing.T) {
+	src := New(Options{Window: 10})
+	src.Add(1, 2, 3)
+	var buf bytes.Buffer
+	_, _ = src.WriteTo(&buf)
+	data := buf.Bytes()
+
+	dst := New(Options{Window: 10, DetectRaces: true})
+	dst.Add(42)
+	for _, input := range [][]byte{nil, data[:len(data)-1], []byte("nope, not a snapshot")} {
+		if _, err := dst.ReadFrom(bytes.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+	if !slices.Equal(dst.Values(), []float64{42}) {
+		t.Error(dst.Values())
+	}
+
+	var _ io.WriterTo = dst
+	var _ io.ReaderFrom = dst
+}
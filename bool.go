@@ -0,0 +1,73 @@
+package movingaverage
+
+// MovingBool tracks a moving window of boolean outcomes (e.g. health check results),
+// and provides the ratio of true outcomes, the number of consecutive false outcomes,
+// and the number of times the outcome flipped within the window.
+//
+// MovingBool is not safe for concurrent use by multiple goroutines.
+type MovingBool struct {
+	ms                  *movingStats
+	consecutiveFailures int
+}
+
+// NewMovingBool returns a new MovingBool with the given options. Only Window, MaxAge, and
+// MinSamples (which applies to Ratio) apply; the other options are ignored. (Outcomes are
+// stored as 1 and 0, so filters like IgnoreNonPositive would drop false outcomes, and
+// eviction policies and statistics options would see the encoding rather than the outcomes.)
+func NewMovingBool(opts Options) *MovingBool {
+	return &MovingBool{
+		ms: newMovingStats(Options{
+			Window:     opts.Window,
+			MaxAge:     opts.MaxAge,
+			MinSamples: opts.MinSamples,
+		}),
+	}
+}
+
+// Add adds the given outcomes to the window.
+func (b *MovingBool) Add(outcomes ...bool) {
+	for _, outcome := range outcomes {
+		if outcome {
+			b.ms.Add(1)
+			b.consecutiveFailures = 0
+		} else {
+			b.ms.Add(0)
+			b.consecutiveFailures++
+		}
+	}
+}
+
+// Window returns the number of outcomes kept in the window.
+func (b *MovingBool) Window() int {
+	return b.ms.Window()
+}
+
+// Count returns the number of outcomes in the window.
+func (b *MovingBool) Count() int {
+	return b.ms.Count()
+}
+
+// Ratio returns the fraction of outcomes in the window which are true.
+// If no outcomes have been added, 0.0 is returned.
+func (b *MovingBool) Ratio() float64 {
+	return b.ms.Avg()
+}
+
+// ConsecutiveFailures returns the number of false outcomes added since the most recent
+// true outcome. This count is not limited to the window.
+func (b *MovingBool) ConsecutiveFailures() int {
+	return b.consecutiveFailures
+}
+
+// Flips returns the number of times the outcome changed between consecutive outcomes
+// in the window.
+func (b *MovingBool) Flips() int {
+	values := b.ms.filledValues()
+	flips := 0
+	for i := 1; i < len(values); i++ {
+		if values[i] != values[i-1] {
+			flips++
+		}
+	}
+	return flips
+}
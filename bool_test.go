@@ -0,0 +1,50 @@
+package movingaverage
+
+import (
+	"testing"
+)
+
+func TestMovingBool(t *testing.T) {
+	b := NewMovingBool(Options{Window: 4})
+	if b.Ratio() != 0 || b.Flips() != 0 || b.ConsecutiveFailures() != 0 {
+		t.Error(b.Ratio(), b.Flips(), b.ConsecutiveFailures())
+	}
+
+	b.Add(true, false, true, true)
+	if b.Ratio() != 0.75 {
+		t.Error(b.Ratio())
+	}
+	if b.Flips() != 2 {
+		t.Error(b.Flips())
+	}
+	if b.ConsecutiveFailures() != 0 {
+		t.Error(b.ConsecutiveFailures())
+	}
+
+	b.Add(false, false, false, false, false)
+	if b.Ratio() != 0 {
+		t.Error(b.Ratio())
+	}
+	if b.Flips() != 0 {
+		t.Error(b.Flips())
+	}
+	if b.ConsecutiveFailures() != 5 {
+		t.Error(b.ConsecutiveFailures())
+	}
+	if b.Count() != 4 || b.Window() != 4 {
+		t.Error(b.Count(), b.Window())
+	}
+}
+
+func TestMovingBoolOptions(t *testing.T) {
+	// IgnoreNonPositive would drop false outcomes, stored as 0
+	b := NewMovingBool(Options{Window: 4, MinSamples: 2, IgnoreNonPositive: true})
+	b.Add(false)
+	if b.Count() != 1 || b.Ratio() != 0 {
+		t.Error(b.Count(), b.Ratio())
+	}
+	b.Add(true, false, false)
+	if b.Count() != 4 || b.Ratio() != 0.25 || b.Flips() != 2 {
+		t.Error(b.Count(), b.Ratio(), b.Flips())
+	}
+}
@@ -0,0 +1,237 @@
+package movingaverage
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed is the normal state, in which all requests are allowed.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen is the state after the breaker trips, in which no requests are allowed.
+	BreakerOpen
+	// BreakerHalfOpen is the state after BreakerOptions.OpenDuration has passed, in which
+	// a limited number of trial requests are allowed to test whether the dependency recovered.
+	BreakerHalfOpen
+)
+
+var breakerStateNames = [...]string{
+	BreakerClosed:   "closed",
+	BreakerOpen:     "open",
+	BreakerHalfOpen: "half-open",
+}
+
+// String returns the state's name, e.g. "half-open".
+func (s BreakerState) String() string {
+	if s < 0 || int(s) >= len(breakerStateNames) {
+		return "BreakerState(" + strconv.Itoa(int(s)) + ")"
+	}
+	return breakerStateNames[s]
+}
+
+// BreakerOptions configures a new Breaker.
+type BreakerOptions struct {
+	// The number of requests in the rolling window the breaker's thresholds are judged over.
+	Window int
+
+	// If positive, requests older than MaxAge are evicted from the window.
+	MaxAge time.Duration
+
+	// If positive, the breaker trips when the fraction of requests in the window which
+	// failed reaches MaxFailureRatio, e.g. 0.5.
+	MaxFailureRatio float64
+
+	// If positive, the breaker trips when the LatencyPercentile of the latencies of the
+	// requests in the window exceeds MaxLatency.
+	MaxLatency time.Duration
+
+	// The percentile (0-100] of latencies compared against MaxLatency. If zero, 99 is used.
+	LatencyPercentile float64
+
+	// The minimum number of requests in the window before the breaker can trip, so a handful
+	// of requests (e.g. one failure out of one) doesn't trip it.
+	MinRequests int
+
+	// How long the breaker stays open after tripping before allowing trial requests.
+	OpenDuration time.Duration
+
+	// The number of trial requests allowed while half-open, all of which must succeed within
+	// MaxLatency to close the breaker. If zero, 1 is used.
+	HalfOpenRequests int
+
+	// How long a half-open breaker waits for a trial request's outcome to be reported,
+	// after the last trial request was allowed, before opening again as if it failed, so a
+	// trial request whose outcome is never reported (e.g. because the caller panicked)
+	// can't leave the breaker half-open forever. If zero, OpenDuration is used; if that is
+	// also zero, trial requests never time out, and their outcomes must always be reported.
+	TrialTimeout time.Duration
+}
+
+// Breaker is a circuit breaker which trips based on the failure ratio and latency of the
+// requests in a rolling window, tracked by a RequestStats.
+//
+// Callers ask Allow before each request to a dependency, then report its outcome via the
+// returned BreakerTicket's RecordSuccess or RecordFailure. While closed, the breaker allows all requests, and trips
+// (opens) once the window's failure ratio or latency exceed their thresholds. While open,
+// it allows none; after BreakerOptions.OpenDuration, it becomes half-open, allowing
+// HalfOpenRequests trial requests. If they all succeed within MaxLatency, the breaker
+// closes, with an empty window; if any fails, or isn't reported within TrialTimeout, it
+// opens again. Outcomes of requests allowed before the breaker's state last changed, e.g. of
+// a request allowed while closed and reported after the breaker opened, are recorded in its
+// window, but don't change its state: they are neither trials nor reasons to trip again.
+//
+// Breaker is safe for concurrent use by multiple goroutines.
+type Breaker struct {
+	opts       BreakerOptions
+	stats      *RequestStats
+	state      BreakerState
+	openedAt   time.Time
+	trials     int       // allowed while half-open
+	trialsDone int       // succeeded while half-open
+	trialAt    time.Time // when the last trial request was allowed
+	generation uint64    // incremented on each state change, to tell stale outcomes apart
+	now        func() time.Time
+	mux        sync.Mutex
+}
+
+// NewBreaker returns a new, closed Breaker with the given options.
+func NewBreaker(opts BreakerOptions) *Breaker {
+	if opts.LatencyPercentile == 0 {
+		opts.LatencyPercentile = 99
+	}
+	if opts.HalfOpenRequests <= 0 {
+		opts.HalfOpenRequests = 1
+	}
+	if opts.TrialTimeout <= 0 {
+		opts.TrialTimeout = opts.OpenDuration
+	}
+	return &Breaker{
+		opts:  opts,
+		stats: NewRequestStats(Options{Window: opts.Window, MaxAge: opts.MaxAge}),
+		now:   time.Now,
+	}
+}
+
+// BreakerTicket is returned by Breaker.Allow for an allowed request, whose outcome must be
+// reported through it, so the breaker can tell outcomes of requests allowed in its current
+// state from those of requests allowed before its state last changed. The zero
+// BreakerTicket, returned for a request which isn't allowed, ignores outcomes.
+type BreakerTicket struct {
+	b          *Breaker
+	generation uint64
+}
+
+// RecordSuccess records that the request succeeded with the given latency.
+func (t BreakerTicket) RecordSuccess(latency time.Duration) {
+	if t.b != nil {
+		t.b.record(t.generation, latency, true)
+	}
+}
+
+// RecordFailure records that the request failed after the given latency.
+func (t BreakerTicket) RecordFailure(latency time.Duration) {
+	if t.b != nil {
+		t.b.record(t.generation, latency, false)
+	}
+}
+
+// Allow returns whether a request should be made: always while the breaker is closed,
+// never while it is open, and for up to HalfOpenRequests trial requests while it is
+// half-open. If so, the request's outcome must be reported through the returned ticket.
+func (b *Breaker) Allow() (BreakerTicket, bool) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.updateState()
+
+	switch b.state {
+	case BreakerClosed:
+		return BreakerTicket{b: b, generation: b.generation}, true
+	case BreakerHalfOpen:
+		if b.trials < b.opts.HalfOpenRequests {
+			b.trials++
+			b.trialAt = b.now()
+			return BreakerTicket{b: b, generation: b.generation}, true
+		}
+	}
+	return BreakerTicket{}, false
+}
+
+// State returns the breaker's current state.
+func (b *Breaker) State() BreakerState {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.updateState()
+	return b.state
+}
+
+// Stats returns the RequestStats tracking the requests in the breaker's window.
+// Requests must not be recorded directly into the returned RequestStats; report them
+// through the BreakerTickets returned by Allow instead.
+func (b *Breaker) Stats() *RequestStats {
+	return b.stats
+}
+
+// record records the outcome of a request allowed in the given generation.
+func (b *Breaker) record(generation uint64, latency time.Duration, success bool) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.updateState()
+	b.stats.Record(latency, success)
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case BreakerClosed:
+		if b.tripped() {
+			b.open()
+		}
+	case BreakerHalfOpen:
+		if !success || (b.opts.MaxLatency > 0 && latency > b.opts.MaxLatency) {
+			b.open()
+			return
+		}
+		b.trialsDone++
+		if b.trialsDone >= b.opts.HalfOpenRequests {
+			b.state = BreakerClosed
+			b.generation++
+			b.stats.reset()
+		}
+	}
+}
+
+// tripped returns whether the requests in the window exceed the breaker's thresholds.
+func (b *Breaker) tripped() bool {
+	if b.stats.Count() < b.opts.MinRequests {
+		return false
+	}
+	if b.opts.MaxFailureRatio > 0 && b.stats.ErrorRate() >= b.opts.MaxFailureRatio {
+		return true
+	}
+	return b.opts.MaxLatency > 0 && b.stats.LatencyPercentile(b.opts.LatencyPercentile) > b.opts.MaxLatency
+}
+
+func (b *Breaker) open() {
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.generation++
+}
+
+// updateState moves an open breaker to half-open once OpenDuration has passed, and a
+// half-open breaker back to open once a trial request is overdue, per TrialTimeout.
+func (b *Breaker) updateState() {
+	now := b.now()
+	if b.state == BreakerHalfOpen && b.opts.TrialTimeout > 0 && b.trials > b.trialsDone &&
+		now.Sub(b.trialAt) >= b.opts.TrialTimeout {
+		b.open()
+	}
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.opts.OpenDuration {
+		b.state = BreakerHalfOpen
+		b.generation++
+		b.trials, b.trialsDone = 0, 0
+	}
+}
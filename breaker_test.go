@@ -0,0 +1,179 @@
+package movingaverage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(BreakerOptions{
+		Window:          10,
+		MaxFailureRatio: 0.5,
+		MinRequests:     4,
+		OpenDuration:    time.Second,
+	})
+	b.now = func() time.Time { return now }
+
+	// too few requests to trip
+	for i := 0; i < 3; i++ {
+		allow(t, b).RecordFailure(time.Millisecond)
+	}
+	if _, ok := b.Allow(); !ok || b.State() != BreakerClosed {
+		t.Fatal(b.State())
+	}
+
+	// the fourth request trips it, even though it succeeded
+	allow(t, b).RecordSuccess(time.Millisecond)
+	if _, ok := b.Allow(); ok || b.State() != BreakerOpen {
+		t.Fatal(b.State())
+	}
+
+	// after OpenDuration, one trial request is allowed
+	now = now.Add(time.Second)
+	trial := allow(t, b)
+	if _, ok := b.Allow(); ok || b.State() != BreakerHalfOpen {
+		t.Fatal(b.State())
+	}
+	trial.RecordFailure(time.Millisecond)
+	if b.State() != BreakerOpen {
+		t.Fatal(b.State())
+	}
+
+	now = now.Add(time.Second)
+	allow(t, b).RecordSuccess(time.Millisecond)
+	if b.State() != BreakerClosed || b.Stats().Count() != 0 {
+		t.Fatal(b.State(), b.Stats().Count())
+	}
+}
+
+func TestBreakerLatency(t *testing.T) {
+	b := NewBreaker(BreakerOptions{
+		Window:            4,
+		MaxLatency:        100 * time.Millisecond,
+		LatencyPercentile: 50,
+		HalfOpenRequests:  2,
+	})
+	allow(t, b).RecordSuccess(10 * time.Millisecond)
+	allow(t, b).RecordSuccess(200 * time.Millisecond)
+	if b.State() != BreakerClosed {
+		t.Fatal(b.State())
+	}
+	allow(t, b).RecordSuccess(300 * time.Millisecond)
+	if b.State() != BreakerHalfOpen { // OpenDuration is zero
+		t.Fatal(b.State())
+	}
+
+	// a slow trial request counts as a failure
+	first, second := allow(t, b), allow(t, b)
+	if _, ok := b.Allow(); ok {
+		t.Fatal("expected two trial requests")
+	}
+	first.RecordSuccess(10 * time.Millisecond)
+	if b.State() != BreakerHalfOpen {
+		t.Fatal(b.State())
+	}
+	second.RecordSuccess(time.Second)
+	if b.State() == BreakerClosed {
+		t.Fatal(b.State())
+	}
+
+	if BreakerHalfOpen.String() != "half-open" || BreakerState(7).String() != "BreakerState(7)" {
+		t.Error(BreakerHalfOpen, BreakerState(7))
+	}
+}
+
+func TestBreakerTrialTimeout(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(BreakerOptions{
+		Window:          10,
+		MaxFailureRatio: 0.5,
+		OpenDuration:    time.Second,
+		TrialTimeout:    5 * time.Second,
+	})
+	b.now = func() time.Time { return now }
+	allow(t, b).RecordFailure(time.Millisecond)
+	if b.State() != BreakerOpen {
+		t.Fatal(b.State())
+	}
+
+	// the trial request's outcome is never reported
+	now = now.Add(time.Second)
+	allow(t, b)
+	if _, ok := b.Allow(); ok {
+		t.Fatal(b.State())
+	}
+	now = now.Add(4 * time.Second)
+	if b.State() != BreakerHalfOpen {
+		t.Fatal(b.State())
+	}
+	now = now.Add(time.Second)
+	if b.State() != BreakerOpen {
+		t.Fatal(b.State())
+	}
+
+	// after OpenDuration, another trial request is allowed, and can close the breaker
+	now = now.Add(time.Second)
+	allow(t, b).RecordSuccess(time.Millisecond)
+	if b.State() != BreakerClosed {
+		t.Fatal(b.State())
+	}
+}
+
+func TestBreakerStaleOutcomes(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(BreakerOptions{
+		Window:          10,
+		MaxFailureRatio: 0.5,
+		OpenDuration:    time.Second,
+		TrialTimeout:    5 * time.Second,
+	})
+	b.now = func() time.Time { return now }
+
+	// two requests allowed while closed, reported after the breaker goes half-open
+	stale, stale2 := allow(t, b), allow(t, b)
+	allow(t, b).RecordFailure(time.Millisecond)
+	now = now.Add(time.Second)
+	if b.State() != BreakerHalfOpen {
+		t.Fatal(b.State())
+	}
+
+	// a stale success is recorded, but isn't a trial, so doesn't close the breaker
+	stale.RecordSuccess(time.Millisecond)
+	if b.State() != BreakerHalfOpen || b.Stats().Count() != 2 {
+		t.Fatal(b.State(), b.Stats().Count())
+	}
+
+	// nor does it stand in for an outstanding trial request, which still times out
+	trial := allow(t, b)
+	stale2.RecordSuccess(time.Millisecond)
+	if b.State() != BreakerHalfOpen {
+		t.Fatal(b.State())
+	}
+	now = now.Add(5 * time.Second)
+	if b.State() != BreakerOpen {
+		t.Fatal(b.State())
+	}
+
+	// the timed-out trial's late success is stale too
+	trial.RecordSuccess(time.Millisecond)
+	if b.State() != BreakerOpen {
+		t.Fatal(b.State())
+	}
+
+	// the zero ticket ignores outcomes
+	BreakerTicket{}.RecordFailure(time.Millisecond)
+	if b.Stats().Count() != 4 {
+		t.Error(b.Stats().Count())
+	}
+}
+
+// allow returns the ticket for a request allowed by the given breaker, failing if it isn't.
+func allow(t *testing.T, b *Breaker) BreakerTicket {
+	t.Helper()
+	ticket, ok := b.Allow()
+	if !ok {
+		t.Fatal("request not allowed:", b.State())
+	}
+	return ticket
+}
@@ -0,0 +1,197 @@
+package movingaverage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Builder assembles a MovingStats instance and its common companions (registries and
+// exporters) in one readable expression:
+//
+//	ms, handle, err := movingaverage.NewBuilder(1000).
+//		MaxAge(5 * time.Minute).
+//		IgnoreNanValues().
+//		Concurrent().
+//		Register(registry, "api_latency", labels).
+//		Emit(emitter, labels).
+//		Build()
+//	defer handle.Close()
+//
+// Weighted or Decayed add a DecayedStats view of the instance's values, returned by
+// Handle.Decayed, whose statistics weight recent values more heavily.
+//
+// A Builder must not be reused after Build is called.
+type Builder struct {
+	opts          Options
+	concurrent    bool
+	weighted      bool
+	decay         float64
+	decayed       bool
+	halfLife      time.Duration
+	registrations []builderRegistration
+	emitters      []builderEmitter
+}
+
+type builderRegistration struct {
+	reg    *Registry
+	name   string
+	labels map[string]string
+}
+
+type builderEmitter struct {
+	emitter *JSONEmitter
+	labels  map[string]string
+}
+
+// NewBuilder returns a new Builder for an instance keeping the given number of values.
+func NewBuilder(window int) *Builder {
+	return &Builder{
+		opts: Options{Window: window},
+	}
+}
+
+// MaxAge sets the maximum age of values in the instance; see Options.MaxAge.
+func (b *Builder) MaxAge(maxAge time.Duration) *Builder {
+	b.opts.MaxAge = maxAge
+	return b
+}
+
+// Eviction sets an additional eviction policy for the instance; see Options.Eviction.
+func (b *Builder) Eviction(policy EvictionPolicy) *Builder {
+	b.opts.Eviction = policy
+	return b
+}
+
+// IgnoreNanValues makes the instance ignore NaN values.
+func (b *Builder) IgnoreNanValues() *Builder {
+	b.opts.IgnoreNanValues = true
+	return b
+}
+
+// IgnoreInfValues makes the instance ignore Inf values.
+func (b *Builder) IgnoreInfValues() *Builder {
+	b.opts.IgnoreInfValues = true
+	return b
+}
+
+// Aggregate attaches the given aggregators to the instance; see Options.Aggregators.
+func (b *Builder) Aggregate(aggregators ...Aggregator) *Builder {
+	b.opts.Aggregators = append(b.opts.Aggregators, aggregators...)
+	return b
+}
+
+// Weighted weights the instance's values by their position in the window, for the
+// DecayedStats returned by Handle.Decayed; see NewDecayed. The decay factor must be in (0, 1].
+func (b *Builder) Weighted(decay float64) *Builder {
+	b.weighted, b.decay = true, decay
+	return b
+}
+
+// Decayed weights the instance's values by their age, halving every halfLife, for the
+// DecayedStats returned by Handle.Decayed; see NewTimeDecayed. It can't be combined with
+// Weighted.
+func (b *Builder) Decayed(halfLife time.Duration) *Builder {
+	b.decayed, b.halfLife = true, halfLife
+	return b
+}
+
+// Concurrent makes the instance safe for concurrent use; see NewConcurrent.
+func (b *Builder) Concurrent() *Builder {
+	b.concurrent = true
+	return b
+}
+
+// Register registers the instance in the given Registry under the given name and labels.
+func (b *Builder) Register(reg *Registry, name string, labels map[string]string) *Builder {
+	b.registrations = append(b.registrations, builderRegistration{reg: reg, name: name, labels: labels})
+	return b
+}
+
+// Emit registers the instance with the given JSONEmitter, with the given labels.
+func (b *Builder) Emit(emitter *JSONEmitter, labels map[string]string) *Builder {
+	b.emitters = append(b.emitters, builderEmitter{emitter: emitter, labels: labels})
+	return b
+}
+
+// Build validates the Builder's configuration, then creates the instance, registers it
+// as configured, and returns it along with a Handle for undoing those registrations.
+func (b *Builder) Build() (MovingStats, *Handle, error) {
+	if b.opts.Window <= 0 {
+		return nil, nil, fmt.Errorf("window must be positive, got %d", b.opts.Window)
+	}
+	if b.opts.MaxAge < 0 {
+		return nil, nil, errors.New("max age must not be negative")
+	}
+	if b.weighted && (b.decay <= 0 || b.decay > 1) {
+		return nil, nil, fmt.Errorf("decay factor must be in (0, 1], got %g", b.decay)
+	}
+	if b.decayed && b.halfLife <= 0 {
+		return nil, nil, errors.New("half-life must be positive")
+	}
+	if b.weighted && b.decayed {
+		return nil, nil, errors.New("an instance can't be both weighted and decayed")
+	}
+
+	var ms MovingStats
+	var decayed *DecayedStats
+	switch {
+	case b.weighted || b.decayed:
+		if b.concurrent {
+			// DecayedStats reads the instance's values without its lock
+			return nil, nil, errors.New("a weighted or decayed instance can't be concurrent")
+		}
+		if b.weighted {
+			decayed = NewDecayed(b.opts, b.decay)
+		} else {
+			decayed = NewTimeDecayed(b.opts, b.halfLife)
+		}
+		ms = decayed.ms
+	case b.concurrent:
+		ms = NewConcurrent(b.opts)
+	default:
+		ms = New(b.opts)
+	}
+
+	h := &Handle{ms: ms, decayed: decayed}
+	for _, r := range b.registrations {
+		r.reg.Register(r.name, ms, r.labels)
+		h.registrations = append(h.registrations, r)
+	}
+	for _, e := range b.emitters {
+		e.emitter.Register(ms, e.labels)
+		h.emitters = append(h.emitters, e.emitter)
+	}
+	return ms, h, nil
+}
+
+// Handle manages the lifecycle of an instance assembled by a Builder.
+type Handle struct {
+	ms            MovingStats
+	decayed       *DecayedStats
+	registrations []builderRegistration
+	emitters      []*JSONEmitter
+}
+
+// Decayed returns the DecayedStats view of the instance's values configured by
+// Builder.Weighted or Builder.Decayed, or nil if neither was called. Values added to
+// the instance are reflected in it, and vice versa. Registries and emitters see the
+// instance's unweighted statistics.
+func (h *Handle) Decayed() *DecayedStats {
+	return h.decayed
+}
+
+// Close removes the instance from the registries and emitters it was registered with.
+// Registrations which have since been replaced by another instance are left alone.
+func (h *Handle) Close() {
+	for _, r := range h.registrations {
+		if ms, ok := r.reg.Get(r.name); ok && ms == h.ms {
+			r.reg.Unregister(r.name)
+		}
+	}
+	for _, e := range h.emitters {
+		e.Unregister(h.ms)
+	}
+	h.registrations = nil
+	h.emitters = nil
+}
@@ -0,0 +1,118 @@
+package movingaverage
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	reg := NewRegistry()
+	var sb strings.Builder
+	emitter := NewJSONEmitter(&sb, time.Minute)
+
+	ms, handle, err := NewBuilder(3).
+		MaxAge(time.Minute).
+		IgnoreNanValues().
+		Concurrent().
+		Register(reg, "latency", map[string]string{"route": "/"}).
+		Emit(emitter, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ms.(*concurrentMovingStats); !ok {
+		t.Errorf("expected a concurrent instance, got %T", ms)
+	}
+
+	ms.Add(1, 2, 3, 4)
+	if ms.Count() != 3 || ms.Window() != 3 {
+		t.Error(ms.Count(), ms.Window())
+	}
+	if got, ok := reg.Get("latency"); !ok || got != ms {
+		t.Error(got, ok)
+	}
+	if err := emitter.Emit(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), `"count":3`) {
+		t.Error(sb.String())
+	}
+
+	handle.Close()
+	if _, ok := reg.Get("latency"); ok {
+		t.Error("expected instance to be unregistered")
+	}
+	sb.Reset()
+	if err := emitter.Emit(); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "" {
+		t.Error(sb.String())
+	}
+}
+
+func TestBuilderInvalid(t *testing.T) {
+	if _, _, err := NewBuilder(0).Build(); err == nil {
+		t.Error("expected error for zero window")
+	}
+	if _, _, err := NewBuilder(10).MaxAge(-time.Second).Build(); err == nil {
+		t.Error("expected error for negative max age")
+	}
+}
+
+func TestBuilderWeighted(t *testing.T) {
+	reg := NewRegistry()
+	ms, handle, err := NewBuilder(3).
+		Weighted(0.5).
+		Register(reg, "weighted", nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms.Add(1, 2, 4)
+
+	// weights 0.25, 0.5, 1
+	if d := handle.Decayed(); d == nil || math.Abs(d.Avg()-(0.25+1+4)/1.75) > 0.0001 {
+		t.Error(d)
+	}
+	if got, ok := reg.Get("weighted"); !ok || got.Avg() != 7.0/3 {
+		t.Error(got, ok)
+	}
+	handle.Close()
+
+	if _, handle, err := NewBuilder(3).Build(); err != nil || handle.Decayed() != nil {
+		t.Error(err)
+	}
+}
+
+func TestBuilderDecayed(t *testing.T) {
+	ms, handle, err := NewBuilder(10).MaxAge(time.Minute).Decayed(time.Second).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := handle.Decayed()
+	now := time.Now()
+	d.ms.now = func() time.Time { return now }
+	ms.Add(10)
+	now = now.Add(time.Second)
+	ms.Add(20)
+
+	// weights 0.5, 1
+	if math.Abs(d.TotalWeight()-1.5) > 0.0001 || math.Abs(d.Avg()-(5+20)/1.5) > 0.0001 {
+		t.Error(d.TotalWeight(), d.Avg())
+	}
+
+	for name, b := range map[string]*Builder{
+		"zero decay":      NewBuilder(10).Weighted(0),
+		"zero half-life":  NewBuilder(10).Decayed(0),
+		"both":            NewBuilder(10).Weighted(0.5).Decayed(time.Second),
+		"concurrent":      NewBuilder(10).Decayed(time.Second).Concurrent(),
+		"decay too large": NewBuilder(10).Weighted(2),
+	} {
+		if _, _, err := b.Build(); err == nil {
+			t.Error("expected error for", name)
+		}
+	}
+}
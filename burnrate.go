@@ -0,0 +1,103 @@
+package movingaverage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+// BurnRateOptions configures a new BurnRate.
+type BurnRateOptions struct {
+	// The SLO target, e.g. 0.999 for 99.9% availability.
+	Objective float64
+
+	// The duration of the short window, e.g. 5 minutes.
+	ShortWindow time.Duration
+
+	// The duration of the long window, e.g. 1 hour.
+	LongWindow time.Duration
+
+	// The maximum number of observations to keep in each window.
+	MaxSamples int
+}
+
+// BurnRate tracks the rate at which an SLO's error budget is being consumed over a
+// short and a long time window, for multi-window, multi-burn-rate alerting as
+// described in the Google SRE workbook.
+//
+// A burn rate of 1 means the error budget is being consumed at exactly the rate
+// which would exhaust it at the end of the SLO period.
+//
+// BurnRate is safe for concurrent use by multiple goroutines.
+type BurnRate struct {
+	objective   float64
+	shortErrors *movingStats
+	shortTotals *movingStats
+	longErrors  *movingStats
+	longTotals  *movingStats
+	mux         sync.Mutex
+}
+
+// NewBurnRate returns a new BurnRate with the given options.
+func NewBurnRate(opts BurnRateOptions) *BurnRate {
+	return &BurnRate{
+		objective:   opts.Objective,
+		shortErrors: newMovingStats(Options{Window: opts.MaxSamples, MaxAge: opts.ShortWindow}),
+		shortTotals: newMovingStats(Options{Window: opts.MaxSamples, MaxAge: opts.ShortWindow}),
+		longErrors:  newMovingStats(Options{Window: opts.MaxSamples, MaxAge: opts.LongWindow}),
+		longTotals:  newMovingStats(Options{Window: opts.MaxSamples, MaxAge: opts.LongWindow}),
+	}
+}
+
+// Add records an observation of the given number of errors out of the given total
+// number of events (e.g. failed requests and total requests during an interval).
+func (b *BurnRate) Add(errors, total float64) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.shortErrors.Add(errors)
+	b.shortTotals.Add(total)
+	b.longErrors.Add(errors)
+	b.longTotals.Add(total)
+}
+
+// ShortBurnRate returns the burn rate over the short window.
+// If no events have been recorded in the window, 0.0 is returned.
+func (b *BurnRate) ShortBurnRate() float64 {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.burnRate(b.shortErrors, b.shortTotals)
+}
+
+// LongBurnRate returns the burn rate over the long window.
+// If no events have been recorded in the window, 0.0 is returned.
+func (b *BurnRate) LongBurnRate() float64 {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.burnRate(b.longErrors, b.longTotals)
+}
+
+// Exceeds returns whether the burn rates over both the short and long windows
+// exceed the given threshold (e.g. 14.4 for a page-worthy fast burn).
+func (b *BurnRate) Exceeds(threshold float64) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.burnRate(b.shortErrors, b.shortTotals) > threshold &&
+		b.burnRate(b.longErrors, b.longTotals) > threshold
+}
+
+func (b *BurnRate) burnRate(errors, totals *movingStats) float64 {
+	errSum, err := stats.Sum(errors.filledValues())
+	if err != nil {
+		return 0.0
+	}
+	totalSum, err := stats.Sum(totals.filledValues())
+	if err != nil || totalSum == 0 {
+		return 0.0
+	}
+	budget := 1 - b.objective
+	if budget <= 0 {
+		return 0.0
+	}
+	return (errSum / totalSum) / budget
+}
@@ -0,0 +1,48 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBurnRate(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	b := NewBurnRate(BurnRateOptions{
+		Objective:   0.99,
+		ShortWindow: 5 * time.Minute,
+		LongWindow:  time.Hour,
+		MaxSamples:  100,
+	})
+	for _, ms := range []*movingStats{b.shortErrors, b.shortTotals, b.longErrors, b.longTotals} {
+		ms.now = clock
+	}
+
+	if b.ShortBurnRate() != 0 || b.LongBurnRate() != 0 {
+		t.Error(b.ShortBurnRate(), b.LongBurnRate())
+	}
+
+	// 1% errors: burning exactly at the budget rate
+	b.Add(1, 100)
+	if math.Abs(b.ShortBurnRate()-1) > 0.0001 || math.Abs(b.LongBurnRate()-1) > 0.0001 {
+		t.Error(b.ShortBurnRate(), b.LongBurnRate())
+	}
+
+	// ten minutes later, the first observation has left the short window only
+	now = now.Add(10 * time.Minute)
+	b.Add(20, 100)
+	if math.Abs(b.ShortBurnRate()-20) > 0.0001 {
+		t.Error(b.ShortBurnRate())
+	}
+	if math.Abs(b.LongBurnRate()-10.5) > 0.0001 {
+		t.Error(b.LongBurnRate())
+	}
+	if !b.Exceeds(10) {
+		t.Error("expected burn rate to exceed 10")
+	}
+	if b.Exceeds(14.4) {
+		t.Error("expected burn rate not to exceed 14.4")
+	}
+}
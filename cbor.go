@@ -0,0 +1,322 @@
+package movingaverage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// CBOR major types, per RFC 8949.
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+// cborNull is the encoding of CBOR's null simple value.
+const cborNull = 0xf6
+
+// MarshalCBOR encodes the Snapshot as CBOR: a map with the keys "window" (an integer),
+// "values" (an array of float64s), and "times" (an array of integers holding Unix times
+// in nanoseconds, or null).
+func (s Snapshot) MarshalCBOR() ([]byte, error) {
+	buf := make([]byte, 0, 32+9*len(s.Values)+9*len(s.Times))
+	buf = cborAppendHeader(buf, cborMap, 3)
+
+	buf = cborAppendText(buf, "window")
+	buf = cborAppendInt(buf, int64(s.Window))
+
+	buf = cborAppendText(buf, "values")
+	buf = cborAppendHeader(buf, cborArray, uint64(len(s.Values)))
+	for _, v := range s.Values {
+		buf = append(buf, cborSimple<<5|27)
+		buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(v))
+	}
+
+	buf = cborAppendText(buf, "times")
+	if s.Times == nil {
+		buf = append(buf, cborNull)
+	} else {
+		buf = cborAppendHeader(buf, cborArray, uint64(len(s.Times)))
+		for _, t := range s.Times {
+			buf = cborAppendInt(buf, t.UnixNano())
+		}
+	}
+
+	return buf, nil
+}
+
+// UnmarshalCBOR decodes a Snapshot encoded by MarshalCBOR.
+// Unknown map keys are ignored. Indefinite-length items are not supported.
+func (s *Snapshot) UnmarshalCBOR(data []byte) error {
+	d := &cborDecoder{data: data}
+	n, err := d.expect(cborMap)
+	if err != nil {
+		return err
+	}
+
+	var retv Snapshot
+	for i := uint64(0); i < n; i++ {
+		key, err := d.text()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "window":
+			window, err := d.int()
+			if err != nil {
+				return err
+			}
+			retv.Window = int(window)
+		case "values":
+			l, err := d.expect(cborArray)
+			if err != nil {
+				return err
+			}
+			retv.Values = make([]float64, l)
+			for j := range retv.Values {
+				if retv.Values[j], err = d.float(); err != nil {
+					return err
+				}
+			}
+		case "times":
+			if d.null() {
+				continue
+			}
+			l, err := d.expect(cborArray)
+			if err != nil {
+				return err
+			}
+			retv.Times = make([]time.Time, l)
+			for j := range retv.Times {
+				ns, err := d.int()
+				if err != nil {
+					return err
+				}
+				retv.Times[j] = time.Unix(0, ns)
+			}
+		default:
+			if err := d.skip(0); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.pos != len(d.data) {
+		return errors.New("cbor: trailing data")
+	}
+	*s = retv
+	return nil
+}
+
+func cborAppendHeader(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major<<5|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major<<5|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major<<5|27), n)
+	}
+}
+
+func cborAppendInt(buf []byte, v int64) []byte {
+	if v < 0 {
+		return cborAppendHeader(buf, cborNegint, uint64(-1-v))
+	}
+	return cborAppendHeader(buf, cborUint, uint64(v))
+}
+
+func cborAppendText(buf []byte, v string) []byte {
+	buf = cborAppendHeader(buf, cborText, uint64(len(v)))
+	return append(buf, v...)
+}
+
+// cborDecoder decodes the subset of CBOR needed to read a Snapshot.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+var errCBORShort = errors.New("cbor: unexpected end of data")
+
+// cborMaxDepth is the deepest that arrays, maps, and tags in skipped items may be nested,
+// so malicious data can't exhaust the stack.
+const cborMaxDepth = 32
+
+// header reads an item's initial byte and argument, returning its major type,
+// additional information, and argument.
+func (d *cborDecoder) header() (major byte, info byte, arg uint64, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, 0, errCBORShort
+	}
+	b := d.data[d.pos]
+	d.pos++
+	major, info = b>>5, b&0x1f
+
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		size = 1 << (info - 24)
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: unsupported additional information %d", info)
+	}
+	if len(d.data)-d.pos < size {
+		return 0, 0, 0, errCBORShort
+	}
+	b8 := d.data[d.pos : d.pos+size]
+	d.pos += size
+	switch size {
+	case 1:
+		arg = uint64(b8[0])
+	case 2:
+		arg = uint64(binary.BigEndian.Uint16(b8))
+	case 4:
+		arg = uint64(binary.BigEndian.Uint32(b8))
+	default:
+		arg = binary.BigEndian.Uint64(b8)
+	}
+	return major, info, arg, nil
+}
+
+// expect reads the header of an array or map, returning its length.
+func (d *cborDecoder) expect(major byte) (uint64, error) {
+	m, _, n, err := d.header()
+	if err != nil {
+		return 0, err
+	}
+	if m != major {
+		return 0, fmt.Errorf("cbor: expected major type %d, got %d", major, m)
+	}
+	// Each element or entry takes at least one byte
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, errCBORShort
+	}
+	return n, nil
+}
+
+// null consumes a null value and returns true, if the next value is null.
+func (d *cborDecoder) null() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborNull {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *cborDecoder) int() (int64, error) {
+	major, _, arg, err := d.header()
+	if err != nil {
+		return 0, err
+	}
+	if (major != cborUint && major != cborNegint) || arg > math.MaxInt64 {
+		return 0, errors.New("cbor: expected integer")
+	}
+	if major == cborNegint {
+		return -1 - int64(arg), nil
+	}
+	return int64(arg), nil
+}
+
+func (d *cborDecoder) float() (float64, error) {
+	start := d.pos
+	major, info, arg, err := d.header()
+	if err != nil {
+		return 0, err
+	}
+	if major != cborSimple {
+		// Integers are accepted as float values
+		d.pos = start
+		v, err := d.int()
+		return float64(v), err
+	}
+	switch info {
+	case 25:
+		return halfToFloat64(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	}
+	return 0, errors.New("cbor: expected float")
+}
+
+func (d *cborDecoder) text() (string, error) {
+	major, _, n, err := d.header()
+	if err != nil {
+		return "", err
+	}
+	if major != cborText {
+		return "", fmt.Errorf("cbor: expected text string, got major type %d", major)
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return "", errCBORShort
+	}
+	retv := string(d.data[d.pos : d.pos+int(n)])
+	d.pos += int(n)
+	return retv, nil
+}
+
+// skip consumes a single item of any type, nested depth arrays, maps, and tags deep.
+func (d *cborDecoder) skip(depth int) error {
+	if depth > cborMaxDepth {
+		return fmt.Errorf("cbor: items nested more than %d deep", cborMaxDepth)
+	}
+	major, _, arg, err := d.header()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cborBytes, cborText:
+		if arg > uint64(len(d.data)-d.pos) {
+			return errCBORShort
+		}
+		d.pos += int(arg)
+	case cborArray, cborMap:
+		n := arg
+		if major == cborMap {
+			n *= 2
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+	case cborTag:
+		return d.skip(depth + 1)
+	}
+	return nil
+}
+
+// halfToFloat64 converts an IEEE 754 half-precision float to a float64.
+func halfToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	}
+	return sign * math.Ldexp(mant+1024, exp-25)
+}
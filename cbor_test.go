@@ -0,0 +1,97 @@
+package movingaverage
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSnapshotCBORRoundTrip(t *testing.T) {
+	now := time.Now()
+	in := Snapshot{
+		Window: 70000,
+		Values: []float64{1.5, -2, math.Inf(-1), 0},
+		Times:  []time.Time{now, now.Add(time.Second), time.Unix(0, -5), time.Unix(0, 5)},
+	}
+	data, err := in.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out Snapshot
+	if err := out.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Window != in.Window || !slices.Equal(out.Values, in.Values) {
+		t.Error(out)
+	}
+	if !slices.EqualFunc(out.Times, in.Times, time.Time.Equal) {
+		t.Error(out.Times)
+	}
+}
+
+func TestSnapshotCBORNullTimes(t *testing.T) {
+	in := Snapshot{Window: 3, Values: make([]float64, 30)}
+	data, err := in.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Snapshot
+	if err := out.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Times != nil || len(out.Values) != 30 {
+		t.Error(out)
+	}
+}
+
+func TestSnapshotCBORForeignEncoding(t *testing.T) {
+	// {"window": 2, "extra": [1, h'00', {"k": null}, 1(0)], "values": [1.5 (half), 2 (single), 3 (int)]}
+	data := []byte{0xa3,
+		0x66, 'w', 'i', 'n', 'd', 'o', 'w', 0x02,
+		0x65, 'e', 'x', 't', 'r', 'a', 0x84, 0x01, 0x41, 0x00, 0xa1, 0x61, 'k', 0xf6, 0xc1, 0x00,
+		0x66, 'v', 'a', 'l', 'u', 'e', 's', 0x83, 0xf9, 0x3e, 0x00, 0xfa, 0x40, 0x00, 0x00, 0x00, 0x03,
+	}
+	var out Snapshot
+	if err := out.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Window != 2 || !slices.Equal(out.Values, []float64{1.5, 2, 3}) {
+		t.Error(out)
+	}
+}
+
+func TestSnapshotCBORNesting(t *testing.T) {
+	// {"extra": [[...[null]...]], "window": 2}, or with tags instead of arrays,
+	// with the given number of them
+	nested := func(head byte, depth int) []byte {
+		data := []byte{0xa2, 0x65, 'e', 'x', 't', 'r', 'a'}
+		data = append(data, bytes.Repeat([]byte{head}, depth)...)
+		return append(data, 0xf6, 0x66, 'w', 'i', 'n', 'd', 'o', 'w', 0x02)
+	}
+	for _, head := range []byte{0x81, 0xc6} {
+		var out Snapshot
+		if err := out.UnmarshalCBOR(nested(head, cborMaxDepth)); err != nil || out.Window != 2 {
+			t.Error(err, out)
+		}
+		if err := out.UnmarshalCBOR(nested(head, cborMaxDepth+1)); err == nil {
+			t.Error("expected error for deeply nested data")
+		}
+		if err := out.UnmarshalCBOR(nested(head, 10_000_000)); err == nil {
+			t.Error("expected error for deeply nested data")
+		}
+	}
+}
+
+func TestSnapshotCBORInvalid(t *testing.T) {
+	data, _ := Snapshot{Window: 3, Values: []float64{1, 2}}.MarshalCBOR()
+	var out Snapshot
+	if err := out.UnmarshalCBOR(data[:len(data)-3]); err == nil {
+		t.Error("expected error for truncated data")
+	}
+	if err := out.UnmarshalCBOR(append(data, 0x00)); err == nil {
+		t.Error("expected error for trailing data")
+	}
+}
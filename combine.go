@@ -0,0 +1,75 @@
+package movingaverage
+
+import "math"
+
+// Combine returns a new MovingStats instance holding the element-wise combination
+// of the values in a and b, as computed by op.
+//
+// Values are aligned by recency: the newest value in a is combined with the newest
+// value in b, the second-newest with the second-newest, and so on. The returned
+// instance's window is the smaller of a's and b's windows, and it holds as many
+// values as the instance with fewer values.
+//
+// The returned instance is a snapshot; it does not change as values are added to a or b.
+func Combine(a, b MovingStats, op func(x, y float64) float64) MovingStats {
+	aValues := a.Values()
+	bValues := b.Values()
+
+	n := min(len(aValues), len(bValues))
+	aValues = aValues[len(aValues)-n:]
+	bValues = bValues[len(bValues)-n:]
+
+	retv := New(Options{Window: min(a.Window(), b.Window())})
+	for i := 0; i < n; i++ {
+		retv.Add(op(aValues[i], bValues[i]))
+	}
+	return retv
+}
+
+// CrossCorrelation finds the lag, between -maxLag and maxLag samples, at which the values
+// in a and b are most strongly correlated, and returns it along with the correlation at
+// that lag, e.g. to align cause and effect between queue depth and latency.
+//
+// Values are aligned by recency, as by Combine. A positive lag means b follows a: a's
+// values are most strongly correlated with b's values lag samples later.
+// The correlation is the standard (biased) cross-correlation estimate: the covariance of
+// the overlapping values, about each series' overall mean, summed and divided by the
+// number of values and both series' standard deviations. Lags with less overlap are thus
+// penalized, rather than spuriously correlating a handful of values.
+// If fewer than two values overlap or either series is constant, 0 and 0.0 are returned.
+func CrossCorrelation(a, b MovingStats, maxLag int) (lag int, correlation float64) {
+	aValues := a.Values()
+	bValues := b.Values()
+
+	n := min(len(aValues), len(bValues))
+	aValues = aValues[len(aValues)-n:]
+	bValues = bValues[len(bValues)-n:]
+	if n < 2 {
+		return 0, 0.0
+	}
+
+	meanA, _ := aValues.Mean()
+	meanB, _ := bValues.Mean()
+	var varA, varB float64
+	for i := 0; i < n; i++ {
+		varA += (aValues[i] - meanA) * (aValues[i] - meanA)
+		varB += (bValues[i] - meanB) * (bValues[i] - meanB)
+	}
+	if varA == 0 || varB == 0 {
+		return 0, 0.0
+	}
+	norm := math.Sqrt(varA * varB)
+
+	maxLag = min(maxLag, n-1)
+	for l := -maxLag; l <= maxLag; l++ {
+		// Pair a[i] with b[i+l]
+		cov := 0.0
+		for i := max(0, -l); i < min(n, n-l); i++ {
+			cov += (aValues[i] - meanA) * (bValues[i+l] - meanB)
+		}
+		if r := cov / norm; l == -maxLag || r > correlation {
+			lag, correlation = l, r
+		}
+	}
+	return lag, correlation
+}
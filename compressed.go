@@ -0,0 +1,295 @@
+package movingaverage
+
+import (
+	"math"
+	"math/bits"
+	"slices"
+	"time"
+)
+
+// buffer is the storage backend for a moving stats instance's values or times:
+// a fixed-capacity FIFO buffer, as implemented by ringbuf.Ring and compressedBuffer.
+type buffer[T any] interface {
+	// Push adds a value, evicting and returning the oldest value if the buffer was full.
+	Push(v T) (evicted T, ok bool)
+	// PopOldest removes and returns the oldest value, if any.
+	PopOldest() (T, bool)
+	// Len returns the number of values in the buffer.
+	Len() int
+	// Slice returns the values in the buffer, oldest first. The returned slice is only
+	// valid until the buffer is next modified, and must not be modified.
+	Slice() []T
+}
+
+// compressedChunkSize is the number of values encoded in each compressedChunk.
+// Larger chunks compress slightly better, but make PopOldest decode more values.
+const compressedChunkSize = 128
+
+// codec encodes and decodes a stream of values to and from a bit stream.
+// It is stateful: values must be decoded in the order they were encoded, by a fresh codec.
+type codec[T any] interface {
+	encode(w *bitWriter, v T)
+	decode(r *bitReader) T
+}
+
+// compressedBuffer is a fixed-capacity FIFO buffer which stores its values losslessly
+// compressed, in independently encoded chunks of compressedChunkSize values, trading CPU
+// for memory: Slice decodes every value, into a newly allocated slice.
+type compressedBuffer[T any] struct {
+	capacity int
+	newCodec func() codec[T]
+	chunks   []compressedChunk
+	enc      codec[T] // encoder state for the newest chunk
+	head     []T      // decoded values of the oldest chunk, or nil
+	skip     int      // number of values popped from the oldest chunk
+	n        int
+}
+
+type compressedChunk struct {
+	bits bitWriter
+	n    int
+}
+
+func newCompressedBuffer[T any](capacity int, newCodec func() codec[T]) *compressedBuffer[T] {
+	return &compressedBuffer[T]{
+		capacity: max(capacity, 0),
+		newCodec: newCodec,
+	}
+}
+
+func (b *compressedBuffer[T]) Push(v T) (evicted T, ok bool) {
+	if b.capacity == 0 {
+		return v, true
+	}
+	if b.n == b.capacity {
+		evicted, ok = b.PopOldest()
+	}
+
+	if len(b.chunks) == 0 || b.chunks[len(b.chunks)-1].n == compressedChunkSize {
+		if len(b.chunks) > 0 {
+			// Release the spare capacity left by appending to the full chunk
+			last := &b.chunks[len(b.chunks)-1]
+			last.bits.buf = slices.Clone(last.bits.buf)
+		}
+		b.chunks = append(b.chunks, compressedChunk{})
+		b.enc = b.newCodec()
+	}
+	last := &b.chunks[len(b.chunks)-1]
+	b.enc.encode(&last.bits, v)
+	last.n++
+	b.n++
+	if len(b.chunks) == 1 {
+		b.head = nil
+	}
+	return evicted, ok
+}
+
+func (b *compressedBuffer[T]) PopOldest() (T, bool) {
+	if b.n == 0 {
+		var zero T
+		return zero, false
+	}
+	if b.head == nil {
+		b.head = b.decode(b.chunks[0], nil)
+	}
+	v := b.head[b.skip]
+	b.skip++
+	b.n--
+	if b.skip == b.chunks[0].n {
+		b.chunks = slices.Delete(b.chunks, 0, 1)
+		b.head = nil
+		b.skip = 0
+	}
+	return v, true
+}
+
+func (b *compressedBuffer[T]) Len() int {
+	return b.n
+}
+
+func (b *compressedBuffer[T]) Slice() []T {
+	if b.n == 0 {
+		return nil
+	}
+	retv := make([]T, 0, b.skip+b.n)
+	for _, c := range b.chunks {
+		retv = b.decode(c, retv)
+	}
+	return retv[b.skip:]
+}
+
+// decode appends the values encoded in the given chunk to dst.
+func (b *compressedBuffer[T]) decode(c compressedChunk, dst []T) []T {
+	dec := b.newCodec()
+	r := bitReader{buf: c.bits.buf}
+	for i := 0; i < c.n; i++ {
+		dst = append(dst, dec.decode(&r))
+	}
+	return dst
+}
+
+// newCompressedValues returns a buffer of float64 values compressed by XORing each
+// value with the previous one, as in Facebook's Gorilla time series database.
+// Values are stored bit-for-bit, so compression is lossless (even for NaN payloads).
+func newCompressedValues(capacity int) buffer[float64] {
+	return newCompressedBuffer(capacity, func() codec[float64] { return &xorCodec{} })
+}
+
+// newCompressedTimes returns a buffer of times compressed by storing the difference
+// between successive intervals (delta-of-delta encoding), as in Facebook's Gorilla time
+// series database. Times are stored to the nanosecond, so decoded times are Equal to the
+// times pushed, but have no monotonic clock reading and are in the local time zone.
+// Times must be representable by time.Time.UnixNano.
+func newCompressedTimes(capacity int) buffer[time.Time] {
+	return newCompressedBuffer(capacity, func() codec[time.Time] { return &deltaOfDeltaCodec{} })
+}
+
+// xorCodec encodes each float64 as its XOR with the previous value: a single 0 bit if
+// they are equal; otherwise the XOR's meaningful bits, reusing the previous value's
+// leading and trailing zero counts if they fit.
+type xorCodec struct {
+	started     bool
+	prev        uint64
+	lead, trail uint
+}
+
+func (c *xorCodec) encode(w *bitWriter, v float64) {
+	vb := math.Float64bits(v)
+	if !c.started {
+		c.started = true
+		c.prev = vb
+		c.lead, c.trail = 64, 0 // no usable previous window
+		w.writeBits(vb, 64)
+		return
+	}
+
+	x := vb ^ c.prev
+	c.prev = vb
+	if x == 0 {
+		w.writeBits(0, 1)
+		return
+	}
+	w.writeBits(1, 1)
+
+	lead := min(uint(bits.LeadingZeros64(x)), 31)
+	trail := uint(bits.TrailingZeros64(x))
+	if c.lead+c.trail < 64 && lead >= c.lead && trail >= c.trail {
+		w.writeBits(0, 1)
+		w.writeBits(x>>c.trail, 64-c.lead-c.trail)
+		return
+	}
+
+	c.lead, c.trail = lead, trail
+	sig := 64 - lead - trail
+	w.writeBits(1, 1)
+	w.writeBits(uint64(lead), 5)
+	w.writeBits(uint64(sig&63), 6) // 64 is written as 0
+	w.writeBits(x>>trail, sig)
+}
+
+func (c *xorCodec) decode(r *bitReader) float64 {
+	if !c.started {
+		c.started = true
+		c.prev = r.readBits(64)
+		return math.Float64frombits(c.prev)
+	}
+
+	if r.readBits(1) == 0 {
+		return math.Float64frombits(c.prev)
+	}
+	if r.readBits(1) == 1 {
+		c.lead = uint(r.readBits(5))
+		sig := uint(r.readBits(6))
+		if sig == 0 {
+			sig = 64
+		}
+		c.trail = 64 - c.lead - sig
+	}
+	c.prev ^= r.readBits(64-c.lead-c.trail) << c.trail
+	return math.Float64frombits(c.prev)
+}
+
+// deltaOfDeltaBuckets are the sizes, in bits, of the zigzag-encoded delta-of-deltas written
+// by deltaOfDeltaCodec, which are prefixed by i 1 bits and a 0 bit for bucket i (no 0 bit
+// for the last bucket). They are sized for nanosecond timestamps: ~8µs, ~8ms, and ~34s of jitter.
+var deltaOfDeltaBuckets = [...]uint{0, 14, 24, 36, 64}
+
+// deltaOfDeltaCodec encodes each time as the difference between its interval from the
+// previous time and the previous interval, which is small for regularly spaced times.
+type deltaOfDeltaCodec struct {
+	prev, delta int64
+}
+
+func (c *deltaOfDeltaCodec) encode(w *bitWriter, t time.Time) {
+	ns := t.UnixNano()
+	delta := ns - c.prev
+	dod := delta - c.delta
+	c.prev, c.delta = ns, delta
+
+	zz := uint64(dod<<1) ^ uint64(dod>>63)
+	last := len(deltaOfDeltaBuckets) - 1
+	for i, size := range deltaOfDeltaBuckets {
+		if i == last || zz < 1<<size {
+			w.writeBits(1<<i-1, uint(i))
+			if i < last {
+				w.writeBits(0, 1)
+			}
+			w.writeBits(zz, size)
+			return
+		}
+	}
+}
+
+func (c *deltaOfDeltaCodec) decode(r *bitReader) time.Time {
+	i := 0
+	for i < len(deltaOfDeltaBuckets)-1 && r.readBits(1) == 1 {
+		i++
+	}
+	zz := r.readBits(deltaOfDeltaBuckets[i])
+	dod := int64(zz>>1) ^ -int64(zz&1)
+
+	c.delta += dod
+	c.prev += c.delta
+	return time.Unix(0, c.prev)
+}
+
+// bitWriter appends bits to a byte slice, most significant bit first.
+type bitWriter struct {
+	buf []byte
+	n   uint // number of bits written
+}
+
+// writeBits writes the low nbits bits of v.
+func (w *bitWriter) writeBits(v uint64, nbits uint) {
+	for nbits > 0 {
+		if w.n%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		free := 8 - w.n%8
+		take := min(free, nbits)
+		chunk := byte(v>>(nbits-take)) & (1<<take - 1)
+		w.buf[len(w.buf)-1] |= chunk << (free - take)
+		w.n += take
+		nbits -= take
+	}
+}
+
+// bitReader reads bits written by a bitWriter.
+type bitReader struct {
+	buf []byte
+	pos uint // number of bits read
+}
+
+// readBits reads nbits bits, returning them as the low bits of the result.
+func (r *bitReader) readBits(nbits uint) uint64 {
+	var v uint64
+	for nbits > 0 {
+		avail := 8 - r.pos%8
+		take := min(avail, nbits)
+		chunk := r.buf[r.pos/8] >> (avail - take) & (1<<take - 1)
+		v = v<<take | uint64(chunk)
+		r.pos += take
+		nbits -= take
+	}
+	return v
+}
@@ -0,0 +1,125 @@
+package movingaverage
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cdzombak/golang-moving-average/ringbuf"
+)
+
+func TestCompressedValuesLossless(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	special := []float64{0, math.Copysign(0, -1), math.Inf(1), math.Inf(-1), math.NaN(),
+		math.Float64frombits(0x7ff8000000000123), math.MaxFloat64, math.SmallestNonzeroFloat64}
+
+	compressed := newCompressedValues(300)
+	plain := ringbuf.New[float64](300)
+	for i := 0; i < 1000; i++ {
+		var v float64
+		switch i % 4 {
+		case 0:
+			v = special[rng.Intn(len(special))]
+		case 1:
+			v = rng.NormFloat64() * 1e6
+		default:
+			v = float64(i / 10)
+		}
+		ev1, ok1 := compressed.Push(v)
+		ev2, ok2 := plain.Push(v)
+		if ok1 != ok2 || math.Float64bits(ev1) != math.Float64bits(ev2) {
+			t.Fatalf("push %d: evicted %v %v, expected %v %v", i, ev1, ok1, ev2, ok2)
+		}
+		if i%97 == 0 {
+			_, _ = compressed.PopOldest()
+			_, _ = plain.PopOldest()
+		}
+		if compressed.Len() != plain.Len() {
+			t.Fatalf("push %d: len %d, expected %d", i, compressed.Len(), plain.Len())
+		}
+		if !slices.EqualFunc(compressed.Slice(), plain.Slice(), func(a, b float64) bool {
+			return math.Float64bits(a) == math.Float64bits(b)
+		}) {
+			t.Fatalf("push %d: values differ", i)
+		}
+	}
+
+	for plain.Len() > 0 {
+		v1, _ := compressed.PopOldest()
+		v2, _ := plain.PopOldest()
+		if math.Float64bits(v1) != math.Float64bits(v2) {
+			t.Fatal(v1, v2)
+		}
+	}
+	if _, ok := compressed.PopOldest(); ok || compressed.Len() != 0 || compressed.Slice() != nil {
+		t.Error("expected empty")
+	}
+}
+
+func TestCompressedTimesLossless(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	compressed := newCompressedTimes(200)
+	plain := ringbuf.New[time.Time](200)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	for i := 0; i < 1000; i++ {
+		switch i % 5 {
+		case 0:
+			now = now.Add(time.Duration(rng.Int63n(int64(time.Hour))))
+		case 1:
+			now = now.Add(-time.Duration(rng.Int63n(int64(time.Second))))
+		default:
+			now = now.Add(time.Second + time.Duration(rng.Intn(1000)))
+		}
+		compressed.Push(now)
+		plain.Push(now)
+		if !slices.EqualFunc(compressed.Slice(), plain.Slice(), time.Time.Equal) {
+			t.Fatalf("push %d: times differ", i)
+		}
+	}
+}
+
+func TestCompressedSavesMemory(t *testing.T) {
+	b := newCompressedBuffer(10000, func() codec[float64] { return &xorCodec{} })
+	for i := 0; i < 10000; i++ {
+		b.Push(float64(20 + i/1000))
+	}
+	size := 0
+	for _, c := range b.chunks {
+		size += len(c.bits.buf)
+	}
+	if size > 10000*8/20 {
+		t.Error(size)
+	}
+}
+
+func TestCompressedOption(t *testing.T) {
+	now := time.Now()
+	a := newMovingStats(Options{Window: 500, MaxAge: time.Minute, Compressed: true})
+	a.now = func() time.Time { return now }
+	b := newMovingStats(Options{Window: 500, MaxAge: time.Minute})
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2000; i++ {
+		v := math.Sin(float64(i) / 50)
+		a.Add(v)
+		b.Add(v)
+		now = now.Add(100 * time.Millisecond)
+	}
+	if !slices.Equal(a.Values(), b.Values()) {
+		t.Error(a.Values(), b.Values())
+	}
+	if a.Summary() != b.Summary() {
+		t.Error(a.Summary(), b.Summary())
+	}
+
+	now = now.Add(55 * time.Second)
+	if a.Count() != b.Count() || a.Count() != 50 {
+		t.Error(a.Count(), b.Count())
+	}
+	if !slices.EqualFunc(a.Snapshot().Times, b.Snapshot().Times, time.Time.Equal) {
+		t.Error("times differ")
+	}
+}
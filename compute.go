@@ -0,0 +1,137 @@
+package movingaverage
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/montanaflynn/stats"
+)
+
+// StatKind identifies a statistic which Compute can calculate.
+type StatKind int
+
+const (
+	// StatCount is the number of values in the window.
+	StatCount StatKind = iota
+	// StatSum is the sum of the values in the window.
+	StatSum
+	// StatAvg is the average of the values in the window, as returned by Avg.
+	StatAvg
+	// StatMin is the minimum of the values in the window, as returned by Min.
+	StatMin
+	// StatMax is the maximum of the values in the window, as returned by Max.
+	StatMax
+	// StatMedian is the median of the values in the window, as returned by Median.
+	StatMedian
+	// StatP90 is the 90th percentile of the values in the window.
+	StatP90
+	// StatP95 is the 95th percentile of the values in the window.
+	StatP95
+	// StatP99 is the 99th percentile of the values in the window.
+	StatP99
+)
+
+var statKindNames = [...]string{
+	StatCount:  "count",
+	StatSum:    "sum",
+	StatAvg:    "avg",
+	StatMin:    "min",
+	StatMax:    "max",
+	StatMedian: "median",
+	StatP90:    "p90",
+	StatP95:    "p95",
+	StatP99:    "p99",
+}
+
+// String returns the statistic's name, e.g. "avg" or "p99".
+func (k StatKind) String() string {
+	if k < 0 || int(k) >= len(statKindNames) {
+		return "StatKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return statKindNames[k]
+}
+
+func (ma *movingStats) Compute(kinds ...StatKind) map[StatKind]float64 {
+	values := ma.statValues()
+	retv := make(map[StatKind]float64, len(kinds))
+	if len(values) == 0 {
+		for _, k := range kinds {
+			if k < 0 || int(k) >= len(statKindNames) {
+				retv[k] = math.NaN()
+			} else {
+				retv[k] = 0.0
+			}
+		}
+		if _, ok := retv[StatCount]; ok {
+			retv[StatCount] = float64(ma.Count())
+		}
+		if _, ok := retv[StatAvg]; ok {
+			retv[StatAvg] = ma.blendPrior(0, 0)
+		}
+		return retv
+	}
+
+	// One pass for the moments, and at most one sort for the order statistics
+	var sum float64
+	var sorted stats.Float64Data
+	for _, k := range kinds {
+		switch k {
+		case StatMedian, StatP90, StatP95, StatP99:
+			if sorted == nil {
+				sorted = slices.Clone(values)
+				slices.Sort(sorted)
+			}
+		}
+	}
+	for _, v := range values {
+		sum += v
+	}
+	minV, maxV := minMax(values)
+
+	for _, k := range kinds {
+		switch k {
+		case StatCount:
+			retv[k] = float64(len(values))
+		case StatSum:
+			retv[k] = sum
+		case StatAvg:
+			if ma.priorWeight > 0 {
+				retv[k] = ma.blendPrior(sum, len(values))
+			} else {
+				retv[k] = sum / float64(len(values))
+			}
+		case StatMin:
+			retv[k] = minV
+		case StatMax:
+			retv[k] = maxV
+		case StatMedian:
+			if ma.medianDecay > 0 && ma.medianDecay < 1 {
+				retv[k] = recencyWeightedMedian(values, ma.medianDecay)
+			} else if ma.interpolation == QuantileDefault {
+				retv[k] = sortedMedian(sorted)
+			} else {
+				retv[k] = percentileSorted(sorted, 50, ma.interpolation)
+			}
+		case StatP90:
+			retv[k] = percentileSorted(sorted, 90, ma.interpolation)
+		case StatP95:
+			retv[k] = percentileSorted(sorted, 95, ma.interpolation)
+		case StatP99:
+			retv[k] = percentileSorted(sorted, 99, ma.interpolation)
+		default:
+			retv[k] = math.NaN()
+		}
+	}
+	return retv
+}
+
+// sortedMedian returns the median of the given non-empty sorted values,
+// averaging the two middle values if there is an even number of them.
+func sortedMedian(sorted stats.Float64Data) float64 {
+	n := len(sorted)
+	if n%2 == 0 {
+		return (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return sorted[n/2]
+}
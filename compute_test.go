@@ -0,0 +1,48 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	a := NewConcurrent(Options{Window: 10})
+	got := a.Compute(StatCount, StatAvg, StatP99, StatKind(-1))
+	if len(got) != 4 || got[StatCount] != 0 || got[StatAvg] != 0 || got[StatP99] != 0 || !math.IsNaN(got[StatKind(-1)]) {
+		t.Error(got)
+	}
+
+	a.Add(7, 3, 9, 1, 4, 8)
+	got = a.Compute(StatCount, StatSum, StatAvg, StatMin, StatMax, StatMedian, StatP90, StatP95, StatP99, StatKind(-1))
+	p90, _ := a.Values().PercentileNearestRank(90)
+	for k, want := range map[StatKind]float64{
+		StatCount:  6,
+		StatSum:    32,
+		StatAvg:    a.Avg(),
+		StatMin:    a.Min(),
+		StatMax:    a.Max(),
+		StatMedian: a.Median(),
+		StatP90:    p90,
+		StatP95:    9,
+		StatP99:    9,
+	} {
+		if got[k] != want {
+			t.Errorf("%s: got %v, want %v", k, got[k], want)
+		}
+	}
+	if !math.IsNaN(got[StatKind(-1)]) {
+		t.Error(got[StatKind(-1)])
+	}
+
+	b := New(Options{Window: 10, QuantileInterpolation: QuantileLinear})
+	b.Add(1, 2, 3, 4)
+	if got := b.Compute(StatMedian, StatP90); got[StatMedian] != b.Median() || math.Abs(got[StatP90]-3.7) > 1e-9 {
+		t.Error(got)
+	}
+}
+
+func TestStatKindString(t *testing.T) {
+	if StatAvg.String() != "avg" || StatP99.String() != "p99" || StatKind(42).String() != "StatKind(42)" {
+		t.Error(StatAvg, StatP99, StatKind(42))
+	}
+}
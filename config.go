@@ -0,0 +1,333 @@
+package movingaverage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config is a serializable form of Options, for services driving many moving stats
+// instances from config files. It covers every option which is data rather than code:
+// Options.Eviction and Options.Aggregators must be set in code, and Options.DetectRaces,
+// a debugging aid, is also left out.
+//
+// Config has both JSON and YAML struct tags. OptionsFromConfig parses either form; to use
+// your YAML library of choice instead, unmarshal into a Config, then call Config.Options
+// to validate it.
+type Config struct {
+	// The number of values to keep. Must be positive.
+	Window int `json:"window" yaml:"window"`
+
+	// The maximum age of values, as a Go duration string (e.g. "5m"). Optional.
+	MaxAge string `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+
+	// Whether to ignore NaN values.
+	IgnoreNanValues bool `json:"ignore_nan_values,omitempty" yaml:"ignore_nan_values,omitempty"`
+
+	// Whether to ignore Inf values.
+	IgnoreInfValues bool `json:"ignore_inf_values,omitempty" yaml:"ignore_inf_values,omitempty"`
+
+	// Whether to ignore negative values.
+	IgnoreNegative bool `json:"ignore_negative,omitempty" yaml:"ignore_negative,omitempty"`
+
+	// Whether to ignore zero and negative values.
+	IgnoreNonPositive bool `json:"ignore_non_positive,omitempty" yaml:"ignore_non_positive,omitempty"`
+
+	// What to do with NaN values: "keep" (the default), "ignore", "replace_with_last",
+	// "replace_with_mean", or "replace_with_constant". Optional.
+	NaNPolicy string `json:"nan_policy,omitempty" yaml:"nan_policy,omitempty"`
+
+	// The value NaN values are replaced with for the "replace_with_constant" NaN policy.
+	NaNReplacement float64 `json:"nan_replacement,omitempty" yaml:"nan_replacement,omitempty"`
+
+	// What to do with ±Inf values: "keep" (the default), "ignore", "clamp_to_bounds", or
+	// "clamp_to_window". Optional.
+	InfPolicy string `json:"inf_policy,omitempty" yaml:"inf_policy,omitempty"`
+
+	// The values -Inf and +Inf values are clamped to for the "clamp_to_bounds" Inf policy.
+	// InfClampMin must not be greater than InfClampMax.
+	InfClampMin float64 `json:"inf_clamp_min,omitempty" yaml:"inf_clamp_min,omitempty"`
+	InfClampMax float64 `json:"inf_clamp_max,omitempty" yaml:"inf_clamp_max,omitempty"`
+
+	// The statistic returned by MovingStats.Value: "mean" (the default), "median",
+	// "trimmed_mean", or "ema". Optional.
+	PrimaryStat string `json:"primary_stat,omitempty" yaml:"primary_stat,omitempty"`
+
+	// The fraction of values trimmed from each end for the "trimmed_mean" primary stat.
+	// Must be between 0 and 0.5. Optional.
+	TrimFraction float64 `json:"trim_fraction,omitempty" yaml:"trim_fraction,omitempty"`
+
+	// The smoothing factor for the "ema" primary stat. Must be between 0 and 1. Optional.
+	EMAAlpha float64 `json:"ema_alpha,omitempty" yaml:"ema_alpha,omitempty"`
+
+	// The unit of the values: "seconds", "nanoseconds", "bytes", or "percent". Optional.
+	Unit string `json:"unit,omitempty" yaml:"unit,omitempty"`
+
+	// The multiple values are rounded to as they are added. Must not be negative. Optional.
+	RoundTo float64 `json:"round_to,omitempty" yaml:"round_to,omitempty"`
+
+	// The number of values needed before statistics are calculated. Must not be negative.
+	MinSamples int `json:"min_samples,omitempty" yaml:"min_samples,omitempty"`
+
+	// A prior estimate of the average, and its weight as a number of pseudo-values, for
+	// warming up after a restart. PriorWeight must not be negative. Optional.
+	PriorMean   float64 `json:"prior_mean,omitempty" yaml:"prior_mean,omitempty"`
+	PriorWeight float64 `json:"prior_weight,omitempty" yaml:"prior_weight,omitempty"`
+
+	// Whether to record the time each value is added, for IngestRate.
+	TrackIngestRate bool `json:"track_ingest_rate,omitempty" yaml:"track_ingest_rate,omitempty"`
+
+	// Quantiles to maintain incrementally. Each must be between 0 and 1. Optional.
+	TrackQuantiles []float64 `json:"track_quantiles,omitempty" yaml:"track_quantiles,omitempty"`
+
+	// Whether to keep evicted values, for TrendPct.
+	TrackTrend bool `json:"track_trend,omitempty" yaml:"track_trend,omitempty"`
+
+	// The thresholds of the detectors combined by HealthScore. Optional.
+	Health HealthConfig `json:"health,omitempty" yaml:"health,omitempty"`
+
+	// How to calculate percentiles which fall between two values: "default", "linear",
+	// "lower", "higher", "nearest", or "midpoint". Optional.
+	QuantileInterpolation string `json:"quantile_interpolation,omitempty" yaml:"quantile_interpolation,omitempty"`
+
+	// Whether Variance and the statistics based on it use the "population" (the default)
+	// or "sample" variance. Optional.
+	VarianceEstimator string `json:"variance_estimator,omitempty" yaml:"variance_estimator,omitempty"`
+
+	// The decay of the weights of older values in the median. Must be at least 0 and less
+	// than 1. Optional.
+	MedianDecay float64 `json:"median_decay,omitempty" yaml:"median_decay,omitempty"`
+
+	// Whether to store values compressed.
+	Compressed bool `json:"compressed,omitempty" yaml:"compressed,omitempty"`
+}
+
+// HealthConfig is a serializable form of HealthOptions.
+type HealthConfig struct {
+	// The z-score detector's limit. Must not be negative. Optional.
+	MaxZScore float64 `json:"max_z_score,omitempty" yaml:"max_z_score,omitempty"`
+
+	// The CUSUM detector's decision threshold and slack. Must not be negative. Optional.
+	CUSUMThreshold float64 `json:"cusum_threshold,omitempty" yaml:"cusum_threshold,omitempty"`
+	CUSUMSlack     float64 `json:"cusum_slack,omitempty" yaml:"cusum_slack,omitempty"`
+
+	// The staleness detector's limit, as a Go duration string (e.g. "1m"). Optional.
+	MaxStaleness string `json:"max_staleness,omitempty" yaml:"max_staleness,omitempty"`
+}
+
+var (
+	nanPolicyNames = map[string]NaNPolicy{
+		"":                      NaNKeep,
+		"keep":                  NaNKeep,
+		"ignore":                NaNIgnore,
+		"replace_with_last":     NaNReplaceWithLast,
+		"replace_with_mean":     NaNReplaceWithMean,
+		"replace_with_constant": NaNReplaceWithConstant,
+	}
+	infPolicyNames = map[string]InfPolicy{
+		"":                InfKeep,
+		"keep":            InfKeep,
+		"ignore":          InfIgnore,
+		"clamp_to_bounds": InfClampToBounds,
+		"clamp_to_window": InfClampToWindow,
+	}
+	quantileInterpolationNames = map[string]QuantileInterpolation{
+		"":         QuantileDefault,
+		"default":  QuantileDefault,
+		"linear":   QuantileLinear,
+		"lower":    QuantileLower,
+		"higher":   QuantileHigher,
+		"nearest":  QuantileNearest,
+		"midpoint": QuantileMidpoint,
+	}
+	varianceEstimatorNames = map[string]VarianceEstimator{
+		"":           VariancePopulation,
+		"population": VariancePopulation,
+		"sample":     VarianceSample,
+	}
+	unitNames = map[string]Unit{
+		"":            UnitNone,
+		"seconds":     UnitSeconds,
+		"nanoseconds": UnitNanoseconds,
+		"bytes":       UnitBytes,
+		"percent":     UnitPercent,
+	}
+)
+
+// Options validates the Config and returns the corresponding Options.
+// If the Config is invalid, the returned error describes every problem found.
+func (c Config) Options() (Options, error) {
+	var errs []error
+
+	if c.Window <= 0 {
+		errs = append(errs, fmt.Errorf("window must be positive, got %d", c.Window))
+	}
+	maxAge, err := parseConfigDuration("max_age", c.MaxAge)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	nanPolicy, ok := nanPolicyNames[c.NaNPolicy]
+	if !ok {
+		errs = append(errs, fmt.Errorf("unknown nan_policy %q", c.NaNPolicy))
+	}
+	infPolicy, ok := infPolicyNames[c.InfPolicy]
+	if !ok {
+		errs = append(errs, fmt.Errorf("unknown inf_policy %q", c.InfPolicy))
+	}
+	if c.InfClampMin > c.InfClampMax {
+		errs = append(errs, fmt.Errorf("inf_clamp_min must not be greater than inf_clamp_max, got %g and %g", c.InfClampMin, c.InfClampMax))
+	}
+
+	if err := PrimaryStat(c.PrimaryStat).validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid primary_stat: %w", err))
+	}
+	if c.TrimFraction < 0 || c.TrimFraction > 0.5 {
+		errs = append(errs, fmt.Errorf("trim_fraction must be between 0 and 0.5, got %g", c.TrimFraction))
+	}
+	if c.EMAAlpha < 0 || c.EMAAlpha > 1 {
+		errs = append(errs, fmt.Errorf("ema_alpha must be between 0 and 1, got %g", c.EMAAlpha))
+	}
+
+	unit, ok := unitNames[c.Unit]
+	if !ok {
+		errs = append(errs, fmt.Errorf("unknown unit %q", c.Unit))
+	}
+	if c.RoundTo < 0 {
+		errs = append(errs, fmt.Errorf("round_to must not be negative, got %g", c.RoundTo))
+	}
+	if c.MinSamples < 0 {
+		errs = append(errs, fmt.Errorf("min_samples must not be negative, got %d", c.MinSamples))
+	}
+	if c.PriorWeight < 0 {
+		errs = append(errs, fmt.Errorf("prior_weight must not be negative, got %g", c.PriorWeight))
+	}
+	for _, q := range c.TrackQuantiles {
+		if q < 0 || q > 1 {
+			errs = append(errs, fmt.Errorf("track_quantiles must be between 0 and 1, got %g", q))
+		}
+	}
+
+	health, err := c.Health.options()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	interpolation, ok := quantileInterpolationNames[c.QuantileInterpolation]
+	if !ok {
+		errs = append(errs, fmt.Errorf("unknown quantile_interpolation %q", c.QuantileInterpolation))
+	}
+	varEstimator, ok := varianceEstimatorNames[c.VarianceEstimator]
+	if !ok {
+		errs = append(errs, fmt.Errorf("unknown variance_estimator %q", c.VarianceEstimator))
+	}
+	if c.MedianDecay < 0 || c.MedianDecay >= 1 {
+		errs = append(errs, fmt.Errorf("median_decay must be at least 0 and less than 1, got %g", c.MedianDecay))
+	}
+
+	if len(errs) > 0 {
+		return Options{}, errors.Join(errs...)
+	}
+	return Options{
+		Window:                c.Window,
+		MaxAge:                maxAge,
+		IgnoreNanValues:       c.IgnoreNanValues,
+		IgnoreInfValues:       c.IgnoreInfValues,
+		IgnoreNegative:        c.IgnoreNegative,
+		IgnoreNonPositive:     c.IgnoreNonPositive,
+		NaNPolicy:             nanPolicy,
+		NaNReplacement:        c.NaNReplacement,
+		InfPolicy:             infPolicy,
+		InfClampMin:           c.InfClampMin,
+		InfClampMax:           c.InfClampMax,
+		PrimaryStat:           PrimaryStat(c.PrimaryStat),
+		TrimFraction:          c.TrimFraction,
+		EMAAlpha:              c.EMAAlpha,
+		Unit:                  unit,
+		RoundTo:               c.RoundTo,
+		MinSamples:            c.MinSamples,
+		PriorMean:             c.PriorMean,
+		PriorWeight:           c.PriorWeight,
+		TrackIngestRate:       c.TrackIngestRate,
+		TrackQuantiles:        c.TrackQuantiles,
+		TrackTrend:            c.TrackTrend,
+		Health:                health,
+		QuantileInterpolation: interpolation,
+		VarianceEstimator:     varEstimator,
+		MedianDecay:           c.MedianDecay,
+		Compressed:            c.Compressed,
+	}, nil
+}
+
+// options validates the HealthConfig and returns the corresponding HealthOptions.
+func (c HealthConfig) options() (HealthOptions, error) {
+	var errs []error
+	if c.MaxZScore < 0 {
+		errs = append(errs, fmt.Errorf("health.max_z_score must not be negative, got %g", c.MaxZScore))
+	}
+	if c.CUSUMThreshold < 0 {
+		errs = append(errs, fmt.Errorf("health.cusum_threshold must not be negative, got %g", c.CUSUMThreshold))
+	}
+	if c.CUSUMSlack < 0 {
+		errs = append(errs, fmt.Errorf("health.cusum_slack must not be negative, got %g", c.CUSUMSlack))
+	}
+	maxStaleness, err := parseConfigDuration("health.max_staleness", c.MaxStaleness)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return HealthOptions{}, errors.Join(errs...)
+	}
+	return HealthOptions{
+		MaxZScore:      c.MaxZScore,
+		CUSUMThreshold: c.CUSUMThreshold,
+		CUSUMSlack:     c.CUSUMSlack,
+		MaxStaleness:   maxStaleness,
+	}, nil
+}
+
+// parseConfigDuration parses the given optional, non-negative Go duration string from the
+// config field with the given name.
+func parseConfigDuration(name, s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s must not be negative, got %s", name, s)
+	}
+	return d, nil
+}
+
+// OptionsFromConfig parses a JSON- or YAML-encoded Config and returns the corresponding
+// Options. Unknown fields are rejected, so typos in config files are caught.
+//
+// Data beginning with '{' is parsed as JSON; anything else, as YAML. The YAML parser is
+// built in, so this package has no YAML dependency, and supports the subset of YAML config
+// files use: block mappings, block and flow sequences of scalars, plain and quoted
+// scalars, and comments. Anchors, aliases, tags, and multi-line scalars aren't supported.
+func OptionsFromConfig(data []byte) (Options, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		tree, err := parseYAML(data)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid config: %w", err)
+		}
+		if data, err = json.Marshal(tree); err != nil {
+			return Options{}, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return Options{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return c.Options()
+}
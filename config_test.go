@@ -0,0 +1,142 @@
+package movingaverage
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptionsFromConfig(t *testing.T) {
+	opts, err := OptionsFromConfig([]byte(`{"window": 100, "max_age": "5m", "ignore_nan_values": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Window != 100 || opts.MaxAge != 5*time.Minute || !opts.IgnoreNanValues || opts.IgnoreInfValues {
+		t.Error(opts)
+	}
+}
+
+func TestOptionsFromConfigPrimaryStat(t *testing.T) {
+	opts, err := OptionsFromConfig([]byte(`{"window": 10, "primary_stat": "trimmed_mean", "trim_fraction": 0.2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.PrimaryStat != PrimaryTrimmedMean || opts.TrimFraction != 0.2 {
+		t.Error(opts)
+	}
+}
+
+func TestOptionsFromConfigInvalid(t *testing.T) {
+	_, err := OptionsFromConfig([]byte(`{"window": 0, "max_age": "-1s"}`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	// all problems are reported
+	if !strings.Contains(err.Error(), "window") || !strings.Contains(err.Error(), "max_age") {
+		t.Error(err)
+	}
+
+	if _, err := OptionsFromConfig([]byte(`{"window": 10, "max_age": "soon"}`)); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+	if _, err := OptionsFromConfig([]byte(`{"window": 10, "windoww": 5}`)); err == nil {
+		t.Error("expected error for unknown field")
+	}
+	if _, err := OptionsFromConfig([]byte(`{"window": 10, "primary_stat": "mode", "ema_alpha": 2}`)); err == nil ||
+		!strings.Contains(err.Error(), "primary_stat") || !strings.Contains(err.Error(), "ema_alpha") {
+		t.Error(err)
+	}
+}
+
+func TestOptionsFromConfigYAML(t *testing.T) {
+	opts, err := OptionsFromConfig([]byte(`
+# latency window
+window: 100
+max_age: 5m
+ignore_nan_values: true
+primary_stat: "trimmed_mean"
+trim_fraction: 0.5
+min_samples: 10
+prior_mean: 0.25 # seconds
+prior_weight: 20
+track_quantiles: [0.5, 0.99]
+quantile_interpolation: linear
+variance_estimator: sample
+median_decay: 0.9
+nan_policy: replace_with_last
+health:
+  max_z_score: 4
+  cusum_threshold: 5
+  max_staleness: 1m
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Options{
+		Window:                100,
+		MaxAge:                5 * time.Minute,
+		IgnoreNanValues:       true,
+		PrimaryStat:           PrimaryTrimmedMean,
+		TrimFraction:          0.5,
+		MinSamples:            10,
+		PriorMean:             0.25,
+		PriorWeight:           20,
+		TrackQuantiles:        []float64{0.5, 0.99},
+		QuantileInterpolation: QuantileLinear,
+		VarianceEstimator:     VarianceSample,
+		MedianDecay:           0.9,
+		NaNPolicy:             NaNReplaceWithLast,
+		Health:                HealthOptions{MaxZScore: 4, CUSUMThreshold: 5, MaxStaleness: time.Minute},
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("%+v", opts)
+	}
+
+	// A block sequence, indented as much as its key
+	opts, err = OptionsFromConfig([]byte("window: 10\ntrack_quantiles:\n- 0.9\n- 0.99\n"))
+	if err != nil || !slices.Equal(opts.TrackQuantiles, []float64{0.9, 0.99}) {
+		t.Error(opts.TrackQuantiles, err)
+	}
+}
+
+func TestOptionsFromConfigYAMLInvalid(t *testing.T) {
+	for _, config := range []string{
+		"window: 10\nwindoww: 5",               // unknown field
+		"window: ten",                          // wrong type
+		"window: 10\nwindow: 20",               // duplicate key
+		"window: 10\n  max_age: 5m",            // bad indentation
+		"window: 10\nhealth: {max_z_score: 3}", // flow mapping
+		"window: 10\ntrack_quantiles: [0.5",    // unterminated
+		"window: 10\nvariance_estimator: unbiased",
+	} {
+		if _, err := OptionsFromConfig([]byte(config)); err == nil {
+			t.Errorf("expected error for %q", config)
+		}
+	}
+}
+
+func TestOptionsFromConfigAllOptions(t *testing.T) {
+	_, err := OptionsFromConfig([]byte(`{"window": 10, "trim_fraction": 0.6, "min_samples": -1,
+		"nan_policy": "drop", "track_quantiles": [1.5], "median_decay": 1, "unit": "miles",
+		"health": {"max_staleness": "-1s"}}`))
+	for _, field := range []string{"trim_fraction", "min_samples", "nan_policy", "track_quantiles",
+		"median_decay", "unit", "health.max_staleness"} {
+		if err == nil || !strings.Contains(err.Error(), field) {
+			t.Error(field, err)
+		}
+	}
+
+	opts, err := OptionsFromConfig([]byte(`{"window": 10, "unit": "seconds", "round_to": 0.001,
+		"inf_policy": "clamp_to_bounds", "inf_clamp_min": -1, "inf_clamp_max": 1,
+		"track_ingest_rate": true, "track_trend": true, "compressed": true, "ignore_negative": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Unit != UnitSeconds || opts.RoundTo != 0.001 || opts.InfPolicy != InfClampToBounds ||
+		opts.InfClampMin != -1 || opts.InfClampMax != 1 || !opts.TrackIngestRate || !opts.TrackTrend ||
+		!opts.Compressed || !opts.IgnoreNegative {
+		t.Errorf("%+v", opts)
+	}
+}
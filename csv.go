@@ -0,0 +1,95 @@
+package movingaverage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportCSV returns a new MovingStats instance with the given options, holding the values
+// read from r, e.g. to warm-start a service from a previous export.
+//
+// Each CSV row holds a timestamp and a value. Timestamps are either RFC 3339 times or
+// Unix times in (possibly fractional) seconds. A header row is skipped if present.
+// Rows are loaded in chronological order, as by Restore: only the newest Window values
+// are kept, subject to the options' filters and eviction policies (e.g. values older
+// than MaxAge are dropped).
+func ImportCSV(r io.Reader, opts Options) (MovingStats, error) {
+	snap, err := readCSVSnapshot(r)
+	if err != nil {
+		return nil, err
+	}
+	snap.Window = opts.Window
+
+	ms := New(opts)
+	ms.Restore(snap)
+	return ms, nil
+}
+
+// readCSVSnapshot reads (timestamp, value) rows from r into a Snapshot, sorted by time.
+func readCSVSnapshot(r io.Reader) (Snapshot, error) {
+	type row struct {
+		t time.Time
+		v float64
+	}
+	var rows []row
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("movingaverage: reading CSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		t, err := parseCSVTime(record[0])
+		if err != nil {
+			if first {
+				// Header row
+				continue
+			}
+			return Snapshot{}, fmt.Errorf("movingaverage: CSV line %d: invalid timestamp %q", line, record[0])
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("movingaverage: CSV line %d: invalid value %q", line, record[1])
+		}
+		rows = append(rows, row{t: t, v: v})
+	}
+
+	slices.SortStableFunc(rows, func(a, b row) int {
+		return a.t.Compare(b.t)
+	})
+	snap := Snapshot{
+		Values: make([]float64, len(rows)),
+		Times:  make([]time.Time, len(rows)),
+	}
+	for i, r := range rows {
+		snap.Values[i] = r.v
+		snap.Times[i] = r.t
+	}
+	return snap, nil
+}
+
+// parseCSVTime parses an RFC 3339 time or a Unix time in (possibly fractional) seconds.
+func parseCSVTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if math.IsNaN(secs) || math.IsInf(secs, 0) {
+			return time.Time{}, fmt.Errorf("invalid Unix time %q", s)
+		}
+		whole, frac := math.Modf(secs)
+		return time.Unix(int64(whole), int64(math.Round(frac*1e9))), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
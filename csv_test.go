@@ -0,0 +1,68 @@
+package movingaverage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+func TestImportCSV(t *testing.T) {
+	now := time.Now()
+	csv := fmt.Sprintf("time,value\n%s,3\n%d,1\n%s,2\n",
+		now.Add(-5*time.Minute).Format(time.RFC3339Nano),
+		now.Add(-2*time.Hour).Unix(),
+		now.Add(-10*time.Minute).Format(time.RFC3339))
+
+	ms, err := ImportCSV(strings.NewReader(csv), Options{Window: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rows are loaded in chronological order
+	if !slices.Equal(ms.Values(), stats.Float64Data{1, 2, 3}) {
+		t.Error(ms.Values())
+	}
+
+	ms, err = ImportCSV(strings.NewReader(csv), Options{Window: 10, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ms.Values(), stats.Float64Data{2, 3}) {
+		t.Error(ms.Values())
+	}
+
+	ms, err = ImportCSV(strings.NewReader(csv), Options{Window: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ms.Values(), stats.Float64Data{3}) {
+		t.Error(ms.Values())
+	}
+}
+
+func TestImportCSVErrors(t *testing.T) {
+	for _, csv := range []string{
+		"1700000000,1\nyesterday,2\n",
+		"1700000000,one\n",
+		"1700000000,1,extra\n",
+	} {
+		if _, err := ImportCSV(strings.NewReader(csv), Options{Window: 10}); err == nil {
+			t.Errorf("expected error for %q", csv)
+		}
+	}
+
+	_, err := ImportCSV(strings.NewReader("1700000000,1\n1700000001,x\n"), Options{Window: 10})
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Error(err)
+	}
+}
+
+func TestParseCSVTime(t *testing.T) {
+	got, err := parseCSVTime("1700000000.25")
+	if err != nil || !got.Equal(time.Unix(1700000000, 250_000_000)) {
+		t.Error(got, err)
+	}
+}
@@ -0,0 +1,242 @@
+package movingaverage
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+// DecayedStats tracks a moving window of values whose weights decay exponentially
+// with their position in the window: the newest value has weight 1, the value before
+// it has weight decay, the one before that decay², and so on.
+//
+// This gives smoother behavior than a plain (boxcar) window, since values fade out
+// gradually rather than dropping out of the stats all at once. Choose a Window large
+// enough that the weight of the oldest value (decay^(Window-1)) is negligible to
+// approximate a window without hard eviction.
+//
+// Alternatively, weights can decay with the values' ages, at query time; see NewTimeDecayed.
+//
+// DecayedStats is not safe for concurrent use by multiple goroutines.
+type DecayedStats struct {
+	ms       *movingStats
+	decay    float64
+	halfLife time.Duration
+}
+
+// NewDecayed returns a new DecayedStats with the given options and decay factor.
+// The decay factor must be in (0, 1]; a decay factor of 1 weights all values equally.
+func NewDecayed(opts Options, decay float64) *DecayedStats {
+	return &DecayedStats{
+		ms:    newMovingStats(opts),
+		decay: decay,
+	}
+}
+
+// NewTimeDecayed returns a new DecayedStats with the given options, whose values' weights
+// decay exponentially with their age at query time, halving every halfLife. A value added
+// halfLife ago has weight 0.5, one added twice that long ago has weight 0.25, and so on.
+//
+// This is typically combined with Options.MaxAge, so that within e.g. a 5-minute window
+// a burst from 4 minutes ago counts for less than one from 10 seconds ago.
+func NewTimeDecayed(opts Options, halfLife time.Duration) *DecayedStats {
+	d := &DecayedStats{
+		ms:       newMovingStats(opts),
+		halfLife: halfLife,
+	}
+	d.ms.trackTimes()
+	return d
+}
+
+// Add adds the given values to the window.
+func (d *DecayedStats) Add(values ...float64) {
+	d.ms.Add(values...)
+}
+
+// Window returns the number of values kept in the window.
+func (d *DecayedStats) Window() int {
+	return d.ms.Window()
+}
+
+// Count returns the number of values in the window.
+func (d *DecayedStats) Count() int {
+	return d.ms.Count()
+}
+
+// Values returns a copy of the values in the window, oldest first.
+func (d *DecayedStats) Values() stats.Float64Data {
+	return d.ms.Values()
+}
+
+// Weights returns the weights of the values in the window, in the same order as Values.
+func (d *DecayedStats) Weights() []float64 {
+	values := d.ms.filledValues()
+	retv := make([]float64, len(values))
+	d.forEach(func(i int, _, w float64) {
+		retv[i] = w
+	})
+	return retv
+}
+
+// TotalWeight returns the sum of the weights of the values in the window.
+func (d *DecayedStats) TotalWeight() float64 {
+	total := 0.0
+	d.forEach(func(_ int, _, w float64) {
+		total += w
+	})
+	return total
+}
+
+// Sum returns the weighted sum of the values in the window.
+// If no values have been added, 0.0 is returned.
+func (d *DecayedStats) Sum() float64 {
+	sum := 0.0
+	d.forEach(func(_ int, v, w float64) {
+		sum += v * w
+	})
+	return sum
+}
+
+// Avg returns the weighted average of the values in the window.
+// If no values have been added, 0.0 is returned.
+func (d *DecayedStats) Avg() float64 {
+	sum, total := 0.0, 0.0
+	d.forEach(func(_ int, v, w float64) {
+		sum += v * w
+		total += w
+	})
+	if total == 0 {
+		return 0.0
+	}
+	return sum / total
+}
+
+// Variance returns the weighted (population) variance of the values in the window.
+// If no values have been added, 0.0 is returned.
+func (d *DecayedStats) Variance() float64 {
+	mean := d.Avg()
+	sum, total := 0.0, 0.0
+	d.forEach(func(_ int, v, w float64) {
+		sum += w * (v - mean) * (v - mean)
+		total += w
+	})
+	if total == 0 {
+		return 0.0
+	}
+	return sum / total
+}
+
+// StdDev returns the weighted (population) standard deviation of the values in the window.
+// If no values have been added, 0.0 is returned.
+func (d *DecayedStats) StdDev() float64 {
+	return math.Sqrt(d.Variance())
+}
+
+// Median returns the weighted median of the values in the window: the value at which
+// the cumulative weight of the sorted values reaches half the total weight. If it reaches
+// exactly half between two values, their average is returned, so with equal weights
+// this matches the unweighted median.
+// If no values have been added, 0.0 is returned.
+func (d *DecayedStats) Median() float64 {
+	return weightedMedian(d.sortedByValue())
+}
+
+// recencyWeightedMedian returns the weighted median of the given values, oldest first,
+// where the newest value has weight 1 and each older value decay times the weight of the
+// value after it, as for DecayedStats.
+func recencyWeightedMedian(values stats.Float64Data, decay float64) float64 {
+	sorted := make([]weightedValue, len(values))
+	total, w := 0.0, 1.0
+	for i := len(values) - 1; i >= 0; i-- {
+		sorted[i] = weightedValue{v: values[i], w: w}
+		total += w
+		w *= decay
+	}
+	slices.SortFunc(sorted, func(a, b weightedValue) int {
+		return cmp.Compare(a.v, b.v)
+	})
+	return weightedMedian(sorted, total)
+}
+
+// weightedMedian returns the weighted median of the given values, sorted by value, whose
+// weights sum to total, per DecayedStats.Median. If there are no values, 0.0 is returned.
+func weightedMedian(sorted []weightedValue, total float64) float64 {
+	half := total / 2
+	cum := 0.0
+	for i, s := range sorted {
+		cum += s.w
+		if cum == half && i+1 < len(sorted) {
+			return (s.v + sorted[i+1].v) / 2
+		}
+		if cum >= half {
+			return s.v
+		}
+	}
+	return 0.0
+}
+
+// Percentile returns the given weighted percentile (0-100] of the values in the window:
+// the smallest value at which the cumulative weight of the sorted values reaches p percent
+// of the total weight. With equal weights, this matches the nearest-rank percentile.
+// If no values have been added or p is out of range, 0.0 is returned.
+func (d *DecayedStats) Percentile(p float64) float64 {
+	if p <= 0 || p > 100 {
+		return 0.0
+	}
+	sorted, total := d.sortedByValue()
+	target := total * p / 100
+	cum := 0.0
+	for _, s := range sorted {
+		cum += s.w
+		if cum >= target {
+			return s.v
+		}
+	}
+	// Guard against rounding error in the cumulative weight
+	if len(sorted) > 0 {
+		return sorted[len(sorted)-1].v
+	}
+	return 0.0
+}
+
+type weightedValue struct {
+	v, w float64
+}
+
+// sortedByValue returns the values in the window and their weights, sorted by value,
+// along with the total weight.
+func (d *DecayedStats) sortedByValue() ([]weightedValue, float64) {
+	sorted := make([]weightedValue, d.ms.Count())
+	total := 0.0
+	d.forEach(func(i int, v, w float64) {
+		sorted[i] = weightedValue{v: v, w: w}
+		total += w
+	})
+	slices.SortFunc(sorted, func(a, b weightedValue) int {
+		return cmp.Compare(a.v, b.v)
+	})
+	return sorted, total
+}
+
+// forEach calls f with the index, value, and weight of each value in the window.
+func (d *DecayedStats) forEach(f func(i int, v, w float64)) {
+	values, times := d.ms.live()
+
+	if d.halfLife > 0 {
+		now := d.ms.now()
+		for i := len(values) - 1; i >= 0; i-- {
+			age := now.Sub(times[i])
+			f(i, values[i], math.Exp2(-float64(age)/float64(d.halfLife)))
+		}
+		return
+	}
+
+	w := 1.0
+	for i := len(values) - 1; i >= 0; i-- {
+		f(i, values[i], w)
+		w *= d.decay
+	}
+}
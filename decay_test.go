@@ -0,0 +1,120 @@
+package movingaverage
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDecayedStats(t *testing.T) {
+	d := NewDecayed(Options{Window: 3}, 0.5)
+	if d.Avg() != 0 || d.Sum() != 0 || d.Variance() != 0 {
+		t.Error(d.Avg(), d.Sum(), d.Variance())
+	}
+
+	d.Add(8, 4, 2)
+	if !slices.Equal(d.Weights(), []float64{0.25, 0.5, 1}) {
+		t.Error(d.Weights())
+	}
+	if d.TotalWeight() != 1.75 {
+		t.Error(d.TotalWeight())
+	}
+	// 8*0.25 + 4*0.5 + 2*1
+	if d.Sum() != 6 {
+		t.Error(d.Sum())
+	}
+	if math.Abs(d.Avg()-6/1.75) > 0.0001 {
+		t.Error(d.Avg())
+	}
+
+	// the oldest value is evicted
+	d.Add(2)
+	if d.Count() != 3 || d.Sum() != 4 {
+		t.Error(d.Count(), d.Sum())
+	}
+}
+
+func TestDecayedStatsNoDecay(t *testing.T) {
+	d := NewDecayed(Options{Window: 4}, 1)
+	d.Add(2, 4, 4, 6)
+
+	plain := New(Options{Window: 4})
+	plain.Add(2, 4, 4, 6)
+	if d.Avg() != plain.Avg() {
+		t.Error(d.Avg(), plain.Avg())
+	}
+	if d.Variance() != 2 {
+		t.Error(d.Variance())
+	}
+	if math.Abs(d.StdDev()-math.Sqrt2) > 0.0001 {
+		t.Error(d.StdDev())
+	}
+}
+
+func TestDecayedStatsPercentiles(t *testing.T) {
+	d := NewDecayed(Options{Window: 4}, 1)
+	if d.Median() != 0 || d.Percentile(50) != 0 {
+		t.Error(d.Median(), d.Percentile(50))
+	}
+
+	// with equal weights, these match the unweighted stats
+	d.Add(6, 2, 8, 4)
+	plain := New(Options{Window: 4})
+	plain.Add(6, 2, 8, 4)
+	if d.Median() != plain.Median() {
+		t.Error(d.Median(), plain.Median())
+	}
+	for _, p := range []float64{1, 25, 50, 75, 100} {
+		want, _ := plain.Values().PercentileNearestRank(p)
+		if d.Percentile(p) != want {
+			t.Error(p, d.Percentile(p), want)
+		}
+	}
+	if d.Percentile(0) != 0 || d.Percentile(101) != 0 {
+		t.Error(d.Percentile(0), d.Percentile(101))
+	}
+
+	// weights 1/8, 1/4, 1/2, 1: the newest value (10) holds more than half the weight
+	w := NewDecayed(Options{Window: 4}, 0.5)
+	w.Add(1, 2, 3, 10)
+	if w.Median() != 10 {
+		t.Error(w.Median())
+	}
+	// 1/8 + 1/4 = 3/8 of 15/8 total is 20%
+	if w.Percentile(20) != 2 || w.Percentile(21) != 3 {
+		t.Error(w.Percentile(20), w.Percentile(21))
+	}
+}
+
+func TestTimeDecayedStats(t *testing.T) {
+	now := time.Now()
+	d := NewTimeDecayed(Options{Window: 10, MaxAge: 5 * time.Minute}, time.Minute)
+	d.ms.now = func() time.Time { return now }
+
+	d.Add(10)
+	now = now.Add(2 * time.Minute)
+	d.Add(1)
+	if !slices.Equal(d.Weights(), []float64{0.25, 1}) {
+		t.Error(d.Weights())
+	}
+	// 10*0.25 + 1*1
+	if d.Sum() != 3.5 {
+		t.Error(d.Sum())
+	}
+	if d.Avg() != 3.5/1.25 {
+		t.Error(d.Avg())
+	}
+
+	// weights are applied at query time
+	now = now.Add(time.Minute)
+	if !slices.Equal(d.Weights(), []float64{0.125, 0.5}) {
+		t.Error(d.Weights())
+	}
+
+	// values older than MaxAge are still evicted
+	now = now.Add(150 * time.Second)
+	if d.Count() != 1 {
+		t.Error(d.Count())
+	}
+}
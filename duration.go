@@ -0,0 +1,130 @@
+package movingaverage
+
+import (
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+// DurationStats wraps a MovingStats instance to track time.Duration values,
+// e.g. request latencies.
+//
+// Durations are stored in the underlying MovingStats instance as float64 nanoseconds.
+// DurationStats is safe for concurrent use if it was created by NewConcurrentDurationStats.
+type DurationStats struct {
+	ms MovingStats
+}
+
+// NewDurationStats returns a new DurationStats with the given options.
+// Unless opts.Unit is set, the underlying MovingStats instance's unit is UnitNanoseconds.
+func NewDurationStats(opts Options) *DurationStats {
+	if opts.Unit == UnitNone {
+		opts.Unit = UnitNanoseconds
+	}
+	return &DurationStats{
+		ms: New(opts),
+	}
+}
+
+// NewConcurrentDurationStats returns a new concurrency-safe DurationStats with the given options.
+// Unless opts.Unit is set, the underlying MovingStats instance's unit is UnitNanoseconds.
+func NewConcurrentDurationStats(opts Options) *DurationStats {
+	if opts.Unit == UnitNone {
+		opts.Unit = UnitNanoseconds
+	}
+	return &DurationStats{
+		ms: NewConcurrent(opts),
+	}
+}
+
+// Observe adds the given durations to the window.
+func (d *DurationStats) Observe(durations ...time.Duration) {
+	values := make([]float64, len(durations))
+	for i, v := range durations {
+		values[i] = float64(v)
+	}
+	d.ms.Add(values...)
+}
+
+// ObserveSince adds the time elapsed since start to the window.
+func (d *DurationStats) ObserveSince(start time.Time) {
+	d.Observe(time.Since(start))
+}
+
+// Timer measures the time elapsed since it was started by DurationStats.StartTimer.
+type Timer struct {
+	d     *DurationStats
+	start time.Time
+}
+
+// StartTimer returns a Timer which, when stopped, adds the time elapsed since this call to the window:
+//
+//	t := d.StartTimer()
+//	defer t.ObserveDuration()
+func (d *DurationStats) StartTimer() Timer {
+	return Timer{d: d, start: time.Now()}
+}
+
+// ObserveDuration adds the time elapsed since the timer was started to its window, and returns it.
+func (t Timer) ObserveDuration() time.Duration {
+	elapsed := time.Since(t.start)
+	t.d.Observe(elapsed)
+	return elapsed
+}
+
+// Stats returns the underlying MovingStats instance, whose values are in nanoseconds.
+func (d *DurationStats) Stats() MovingStats {
+	return d.ms
+}
+
+// Avg returns the average of the durations in the window.
+// If no values have been added or any other error occurs, 0 is returned.
+func (d *DurationStats) Avg() time.Duration {
+	return time.Duration(d.ms.Avg())
+}
+
+// Median returns the median of the durations in the window.
+// If no values have been added or any other error occurs, 0 is returned.
+func (d *DurationStats) Median() time.Duration {
+	return time.Duration(d.ms.Median())
+}
+
+// Min returns the minimum of the durations in the window.
+// If no values have been added or any other error occurs, 0 is returned.
+func (d *DurationStats) Min() time.Duration {
+	return time.Duration(d.ms.Min())
+}
+
+// Max returns the maximum of the durations in the window.
+// If no values have been added or any other error occurs, 0 is returned.
+func (d *DurationStats) Max() time.Duration {
+	return time.Duration(d.ms.Max())
+}
+
+// Apdex returns the Apdex score of the durations in the window for the given
+// target threshold: (satisfied + tolerating/2) / total.
+//
+// Durations up to the threshold are satisfied, durations up to four times the
+// threshold are tolerating, and longer durations are frustrated.
+// If no values have been added, 0.0 is returned.
+func (d *DurationStats) Apdex(threshold time.Duration) float64 {
+	satisfiedMax := float64(threshold)
+	toleratingMax := float64(4 * threshold)
+
+	var satisfied, tolerating, total int
+	_ = d.ms.UnsafeDo(func(values stats.Float64Data) error {
+		total = len(values)
+		for _, v := range values {
+			if v <= satisfiedMax {
+				satisfied++
+			} else if v <= toleratingMax {
+				tolerating++
+			}
+		}
+		return nil
+	})
+	if total == 0 {
+		return 0.0
+	}
+	return (float64(satisfied) + float64(tolerating)/2) / float64(total)
+}
@@ -0,0 +1,61 @@
+package movingaverage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationStats(t *testing.T) {
+	d := NewDurationStats(Options{Window: 3})
+	if d.Avg() != 0 {
+		t.Error(d.Avg())
+	}
+
+	d.Observe(10*time.Millisecond, 20*time.Millisecond, 30*time.Millisecond)
+	if d.Avg() != 20*time.Millisecond {
+		t.Error(d.Avg())
+	}
+	if d.Median() != 20*time.Millisecond {
+		t.Error(d.Median())
+	}
+	if d.Min() != 10*time.Millisecond || d.Max() != 30*time.Millisecond {
+		t.Error(d.Min(), d.Max())
+	}
+	if d.Stats().Count() != 3 {
+		t.Error(d.Stats().Count())
+	}
+}
+
+func TestApdex(t *testing.T) {
+	d := NewConcurrentDurationStats(Options{Window: 4})
+	if d.Apdex(100*time.Millisecond) != 0 {
+		t.Error(d.Apdex(100 * time.Millisecond))
+	}
+
+	d.Observe(
+		50*time.Millisecond,  // satisfied
+		100*time.Millisecond, // satisfied
+		300*time.Millisecond, // tolerating
+		time.Second,          // frustrated
+	)
+	if d.Apdex(100*time.Millisecond) != 0.625 {
+		t.Error(d.Apdex(100 * time.Millisecond))
+	}
+}
+
+func TestTimer(t *testing.T) {
+	d := NewDurationStats(Options{Window: 3})
+	d.ObserveSince(time.Now().Add(-time.Second))
+	if d.Max() < time.Second {
+		t.Error(d.Max())
+	}
+
+	timer := d.StartTimer()
+	elapsed := timer.ObserveDuration()
+	if d.Stats().Count() != 2 {
+		t.Error(d.Stats().Count())
+	}
+	if d.Min() != elapsed {
+		t.Error(d.Min(), elapsed)
+	}
+}
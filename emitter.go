@@ -0,0 +1,137 @@
+package movingaverage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JSONEmitter periodically writes a summary of each registered MovingStats instance
+// to an io.Writer, as JSON lines (one JSON object per line), so windows can be streamed
+// into tools like jq, Vector, or fluentd.
+//
+// Each line holds the time of emission, the instance's labels, and its Summary:
+//
+//	{"time":"2024-05-01T12:00:00Z","labels":{"route":"/"},"count":3,"avg":2,"min":1,"max":3}
+//
+// Non-finite stats (NaN and ±Inf) are written as null.
+// JSONEmitter is safe for concurrent use by multiple goroutines.
+type JSONEmitter struct {
+	w        io.Writer
+	interval time.Duration
+	sources  []jsonEmitterSource
+	now      func() time.Time
+	mux      sync.Mutex
+}
+
+// errNonPositiveInterval is returned by the Run methods of periodic tasks, and by streaming
+// handlers, whose interval isn't positive.
+var errNonPositiveInterval = errors.New("movingaverage: interval must be positive")
+
+type jsonEmitterSource struct {
+	ms     MovingStats
+	labels map[string]string
+}
+
+type jsonEmitterRecord struct {
+	Time   time.Time         `json:"time"`
+	Name   string            `json:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Count  int               `json:"count"`
+	Avg    jsonFloat         `json:"avg"`
+	Min    jsonFloat         `json:"min"`
+	Max    jsonFloat         `json:"max"`
+}
+
+func newJSONEmitterRecord(now time.Time, name string, labels map[string]string, summary Summary) jsonEmitterRecord {
+	return jsonEmitterRecord{
+		Time:   now,
+		Name:   name,
+		Labels: labels,
+		Count:  summary.Count,
+		Avg:    jsonFloat(summary.Avg),
+		Min:    jsonFloat(summary.Min),
+		Max:    jsonFloat(summary.Max),
+	}
+}
+
+// jsonFloat is a float64 which is marshaled to JSON as null if it is not finite.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return []byte("null"), nil
+	}
+	return strconv.AppendFloat(nil, float64(f), 'g', -1, 64), nil
+}
+
+// NewJSONEmitter returns a new JSONEmitter which writes to w every interval once Run is called.
+func NewJSONEmitter(w io.Writer, interval time.Duration) *JSONEmitter {
+	return &JSONEmitter{
+		w:        w,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Register adds a MovingStats instance, identified by the given labels, to the emitter.
+func (e *JSONEmitter) Register(ms MovingStats, labels map[string]string) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.sources = append(e.sources, jsonEmitterSource{ms: ms, labels: labels})
+}
+
+// Unregister removes a MovingStats instance from the emitter.
+func (e *JSONEmitter) Unregister(ms MovingStats) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.sources = slices.DeleteFunc(e.sources, func(src jsonEmitterSource) bool {
+		return src.ms == ms
+	})
+}
+
+// Emit immediately writes one line for each registered MovingStats instance.
+func (e *JSONEmitter) Emit() error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	now := e.now()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, src := range e.sources {
+		err := enc.Encode(newJSONEmitterRecord(now, "", src.labels, src.ms.Summary()))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// Run calls Emit every interval until the given context is canceled or Emit returns an error.
+// It returns the context's error or the error returned by Emit, or an error immediately if
+// the interval isn't positive.
+func (e *JSONEmitter) Run(ctx context.Context) error {
+	if e.interval <= 0 {
+		return errNonPositiveInterval
+	}
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := e.Emit(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package movingaverage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJSONEmitter(t *testing.T) {
+	var sb strings.Builder
+	e := NewJSONEmitter(&sb, time.Minute)
+	e.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	a := New(Options{Window: 3})
+	a.Add(1, 2, 3)
+	b := New(Options{Window: 3})
+	b.Add(math.NaN())
+	e.Register(a, map[string]string{"route": "/"})
+	e.Register(b, nil)
+
+	if err := e.Emit(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"time":"2024-05-01T12:00:00Z","labels":{"route":"/"},"count":3,"avg":2,"min":1,"max":3}
+{"time":"2024-05-01T12:00:00Z","count":1,"avg":null,"min":null,"max":null}
+`
+	if sb.String() != expected {
+		t.Error(sb.String())
+	}
+}
+
+func TestJSONEmitterRun(t *testing.T) {
+	w := &syncBuilder{}
+	e := NewJSONEmitter(w, time.Millisecond)
+	e.Register(New(Options{Window: 3}), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := e.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Error(err)
+	}
+	if !strings.Contains(w.String(), `"count":0`) {
+		t.Error(w.String())
+	}
+}
+
+// syncBuilder is a strings.Builder which is safe for concurrent use.
+type syncBuilder struct {
+	sb  strings.Builder
+	mux sync.Mutex
+}
+
+func (s *syncBuilder) Write(p []byte) (int, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.sb.Write(p)
+}
+
+func (s *syncBuilder) String() string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.sb.String()
+}
+
+func TestJSONEmitterRunInvalidInterval(t *testing.T) {
+	if err := NewJSONEmitter(&syncBuilder{}, 0).Run(context.Background()); !errors.Is(err, errNonPositiveInterval) {
+		t.Error(err)
+	}
+}
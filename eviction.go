@@ -0,0 +1,72 @@
+package movingaverage
+
+import (
+	"sort"
+	"time"
+)
+
+// EvictionPolicy decides when values are evicted from a moving stats instance,
+// in addition to the instance's Window. Eviction policies are set via Options.Eviction.
+//
+// Values are always evicted oldest first.
+type EvictionPolicy interface {
+	// Evict returns the number of the oldest values which should be evicted, given the
+	// values in the window and the times they were added (both oldest first) and the
+	// current time.
+	//
+	// Evict is called when values are added and when stats are queried, so it must not
+	// modify values or times and must not have side effects.
+	Evict(values []float64, times []time.Time, now time.Time) int
+}
+
+// MaxAgeEviction returns an EvictionPolicy which evicts values older than maxAge.
+func MaxAgeEviction(maxAge time.Duration) EvictionPolicy {
+	return maxAgeEviction(maxAge)
+}
+
+type maxAgeEviction time.Duration
+
+func (e maxAgeEviction) Evict(_ []float64, times []time.Time, now time.Time) int {
+	cutoff := now.Add(-time.Duration(e))
+	return sort.Search(len(times), func(i int) bool {
+		return !times[i].Before(cutoff)
+	})
+}
+
+// MaxCountEviction returns an EvictionPolicy which keeps at most n values.
+func MaxCountEviction(n int) EvictionPolicy {
+	return maxCountEviction(n)
+}
+
+type maxCountEviction int
+
+func (e maxCountEviction) Evict(values []float64, _ []time.Time, _ time.Time) int {
+	return max(0, len(values)-int(e))
+}
+
+// WeightBudgetEviction returns an EvictionPolicy which evicts the oldest values until the
+// total weight of the remaining values, as computed by the weight function, is at most budget.
+// For example, with a weight function returning each value's size in bytes, this bounds
+// the total size of the values in the window.
+func WeightBudgetEviction(budget float64, weight func(float64) float64) EvictionPolicy {
+	return &weightBudgetEviction{
+		budget: budget,
+		weight: weight,
+	}
+}
+
+type weightBudgetEviction struct {
+	budget float64
+	weight func(float64) float64
+}
+
+func (e *weightBudgetEviction) Evict(values []float64, _ []time.Time, _ time.Time) int {
+	total := 0.0
+	for i := len(values) - 1; i >= 0; i-- {
+		total += e.weight(values[i])
+		if total > e.budget {
+			return i + 1
+		}
+	}
+	return 0
+}
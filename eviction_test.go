@@ -0,0 +1,58 @@
+package movingaverage
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+func TestMaxCountEviction(t *testing.T) {
+	a := New(Options{Window: 5, Eviction: MaxCountEviction(2)})
+	a.Add(1, 2, 3)
+	if !slices.Equal(a.Values(), stats.Float64Data{2, 3}) {
+		t.Error(a.Values())
+	}
+}
+
+func TestWeightBudgetEviction(t *testing.T) {
+	agg := &sumAggregator{}
+	a := New(Options{
+		Window:      10,
+		Eviction:    WeightBudgetEviction(10, func(v float64) float64 { return v }),
+		Aggregators: []Aggregator{agg},
+	})
+	a.Add(4, 3, 2)
+	if !slices.Equal(a.Values(), stats.Float64Data{4, 3, 2}) {
+		t.Error(a.Values())
+	}
+
+	// 4+3+2+5 exceeds the budget, so the 4 is evicted
+	a.Add(5)
+	if !slices.Equal(a.Values(), stats.Float64Data{3, 2, 5}) {
+		t.Error(a.Values())
+	}
+	if agg.Value() != 10 {
+		t.Error(agg.Value())
+	}
+
+	// a single value over the budget evicts everything
+	a.Add(11)
+	if a.Count() != 0 {
+		t.Error(a.Values())
+	}
+}
+
+func TestMaxAgeEviction(t *testing.T) {
+	now := time.Now()
+	a := newMovingStats(Options{Window: 5, Eviction: MaxAgeEviction(time.Minute)})
+	a.now = func() time.Time { return now }
+
+	a.Add(1)
+	now = now.Add(time.Minute + time.Second)
+	a.Add(2)
+	if !slices.Equal(a.Values(), stats.Float64Data{2}) {
+		t.Error(a.Values())
+	}
+}
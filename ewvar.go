@@ -0,0 +1,59 @@
+package movingaverage
+
+import "math"
+
+// ewMoments is an exponentially weighted mean and variance, updated incrementally
+// per Finch's "Incremental calculation of weighted mean and variance" (2009).
+type ewMoments struct {
+	started  bool
+	mean     float64
+	variance float64
+}
+
+// add updates the moments with the given value and smoothing factor.
+// The first value seeds the mean, with a variance of 0. Non-finite values are ignored,
+// since the moments never forget a value, so one NaN or Inf would corrupt them forever.
+func (m *ewMoments) add(v, alpha float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	if !m.started {
+		m.started = true
+		m.mean, m.variance = v, 0
+		return
+	}
+	diff := v - m.mean
+	incr := alpha * diff
+	m.mean += incr
+	m.variance = (1 - alpha) * (m.variance + diff*incr)
+}
+
+// ewAlpha returns the smoothing factor for the instance's exponentially weighted moments:
+// Options.EMAAlpha, or 2/(Window+1) if it is not in (0, 1].
+func (ma *movingStats) ewAlpha() float64 {
+	if ma.emaAlpha > 0 && ma.emaAlpha <= 1 {
+		return ma.emaAlpha
+	}
+	return 2 / float64(ma.window+1)
+}
+
+func (ma *movingStats) EWVariance() float64 {
+	values := ma.statValues()
+	if len(values) == 0 {
+		return 0.0
+	}
+	if ma.ew != nil {
+		return ma.ew.variance
+	}
+
+	// Views created by LastN have no incremental state; calculate from their values
+	var m ewMoments
+	for _, v := range values {
+		m.add(v, ma.ewAlpha())
+	}
+	return m.variance
+}
+
+func (ma *movingStats) EWStdDev() float64 {
+	return math.Sqrt(ma.EWVariance())
+}
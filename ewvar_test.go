@@ -0,0 +1,62 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEWVariance(t *testing.T) {
+	a := New(Options{Window: 10, EMAAlpha: 0.5})
+	if a.EWVariance() != 0 || a.EWStdDev() != 0 {
+		t.Error(a.EWVariance(), a.EWStdDev())
+	}
+
+	a.Add(1)
+	if a.EWVariance() != 0 {
+		t.Error(a.EWVariance())
+	}
+	a.Add(3)
+	if a.EWVariance() != 1 {
+		t.Error(a.EWVariance())
+	}
+	a.Add(5)
+	if a.EWVariance() != 2.75 || a.EWStdDev() != math.Sqrt(2.75) {
+		t.Error(a.EWVariance(), a.EWStdDev())
+	}
+
+	// views calculate from their own values
+	if v := a.LastN(2); v.EWVariance() != 1 {
+		t.Error(v.EWVariance())
+	}
+
+	// restoring replaces the incremental state
+	a.Restore(Snapshot{Values: []float64{7, 7, 7}})
+	if a.EWVariance() != 0 {
+		t.Error(a.EWVariance())
+	}
+}
+
+func TestEWVarianceDefaultAlpha(t *testing.T) {
+	a := NewConcurrent(Options{Window: 3})
+	for i := 0; i < 1000; i++ {
+		a.Add(float64(i % 2))
+	}
+	// alpha = 0.5 for a window of 3: the variance of an alternating series converges to 2/9
+	if v := a.EWVariance(); math.Abs(v-2.0/9) > 1e-9 {
+		t.Error(v)
+	}
+}
+
+func TestEWVarianceNonFinite(t *testing.T) {
+	a := New(Options{Window: 5})
+	b := New(Options{Window: 5})
+	a.Add(math.NaN(), math.Inf(1))
+	for i := 0; i < 20; i++ {
+		a.Add(float64(i % 3))
+		b.Add(float64(i % 3))
+	}
+	// the non-finite values are ignored, as if never added
+	if v := a.EWVariance(); math.IsNaN(v) || v != b.EWVariance() {
+		t.Error(v, b.EWVariance())
+	}
+}
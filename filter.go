@@ -0,0 +1,99 @@
+package movingaverage
+
+import "math"
+
+// NaNPolicy selects what a moving stats instance does with NaN values passed to Add.
+type NaNPolicy int
+
+const (
+	// NaNKeep adds NaN values to the window as-is, unless Options.IgnoreNanValues is set.
+	NaNKeep NaNPolicy = iota
+	// NaNIgnore drops NaN values, like Options.IgnoreNanValues.
+	NaNIgnore
+	// NaNReplaceWithLast replaces each NaN value with the most recently added value.
+	NaNReplaceWithLast
+	// NaNReplaceWithMean replaces each NaN value with the current average of the window.
+	NaNReplaceWithMean
+	// NaNReplaceWithConstant replaces each NaN value with Options.NaNReplacement.
+	NaNReplaceWithConstant
+)
+
+// replaceNaN returns the value to add in place of a NaN value per the instance's NaN policy,
+// and false if the value should be dropped instead. Policies which replace NaN values with
+// a value from the window drop them while the window is empty.
+func (ma *movingStats) replaceNaN(val float64) (float64, bool) {
+	if ma.ignoreNanValues {
+		return val, false
+	}
+	switch ma.nanPolicy {
+	case NaNIgnore:
+		return val, false
+	case NaNReplaceWithLast:
+		return ma.newest()
+	case NaNReplaceWithMean:
+		values := ma.filledValues()
+		if len(values) == 0 {
+			return val, false
+		}
+		mean, _ := values.Mean()
+		return mean, true
+	case NaNReplaceWithConstant:
+		return ma.nanReplacement, true
+	default:
+		return val, true
+	}
+}
+
+// roundTo returns v rounded to the nearest multiple of step.
+func roundTo(v, step float64) float64 {
+	if inv := 1 / step; inv == math.Round(inv) {
+		// For steps like 0.01, dividing by the inverse yields the float64 nearest the
+		// rounded decimal (0.3 rather than 0.30000000000000004)
+		return math.Round(v*inv) / inv
+	}
+	return math.Round(v/step) * step
+}
+
+// InfPolicy selects what a moving stats instance does with ±Inf values passed to Add.
+type InfPolicy int
+
+const (
+	// InfKeep adds ±Inf values to the window as-is, unless Options.IgnoreInfValues is set.
+	InfKeep InfPolicy = iota
+	// InfIgnore drops ±Inf values, like Options.IgnoreInfValues.
+	InfIgnore
+	// InfClampToBounds replaces +Inf with Options.InfClampMax and -Inf with Options.InfClampMin.
+	InfClampToBounds
+	// InfClampToWindow replaces +Inf with the window's current maximum and -Inf with its current minimum.
+	InfClampToWindow
+)
+
+// replaceInf returns the value to add in place of a ±Inf value per the instance's Inf policy,
+// and false if the value should be dropped instead. InfClampToWindow drops ±Inf values
+// while the window is empty.
+func (ma *movingStats) replaceInf(val float64) (float64, bool) {
+	if ma.ignoreInfValues {
+		return val, false
+	}
+	switch ma.infPolicy {
+	case InfIgnore:
+		return val, false
+	case InfClampToBounds:
+		if val > 0 {
+			return ma.infClampMax, true
+		}
+		return ma.infClampMin, true
+	case InfClampToWindow:
+		values := ma.filledValues()
+		if len(values) == 0 {
+			return val, false
+		}
+		minV, maxV := minMax(values)
+		if val > 0 {
+			return maxV, true
+		}
+		return minV, true
+	default:
+		return val, true
+	}
+}
@@ -0,0 +1,71 @@
+package movingaverage
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// BindFlags registers flags on fs for the configurable fields of opts, named with the
+// given prefix: <prefix>window, <prefix>max-age, <prefix>ignore-nan-values, and
+// <prefix>ignore-inf-values. The flags default to opts' current values, and parsed
+// values are written to opts when fs is parsed.
+//
+// For example, BindFlags(flag.CommandLine, "latency-", &opts) registers -latency-window, etc.
+func BindFlags(fs *flag.FlagSet, prefix string, opts *Options) {
+	fs.IntVar(&opts.Window, prefix+"window", opts.Window, "number of values to keep")
+	fs.DurationVar(&opts.MaxAge, prefix+"max-age", opts.MaxAge, "maximum age of values (0 to disable)")
+	fs.BoolVar(&opts.IgnoreNanValues, prefix+"ignore-nan-values", opts.IgnoreNanValues, "ignore NaN values")
+	fs.BoolVar(&opts.IgnoreInfValues, prefix+"ignore-inf-values", opts.IgnoreInfValues, "ignore Inf values")
+}
+
+// LoadEnv updates the configurable fields of opts from environment variables named
+// with the given prefix: <prefix>WINDOW, <prefix>MAX_AGE (a Go duration string),
+// <prefix>IGNORE_NAN_VALUES, and <prefix>IGNORE_INF_VALUES. Fields whose variables
+// are unset or empty are left unchanged.
+//
+// For example, LoadEnv("LATENCY_", &opts) reads LATENCY_WINDOW, etc.
+// If any variable is invalid, the returned error describes every problem found,
+// and opts is not modified.
+func LoadEnv(prefix string, opts *Options) error {
+	retv := *opts
+	var errs []error
+
+	if v := os.Getenv(prefix + "WINDOW"); v != "" {
+		window, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sWINDOW: %w", prefix, err))
+		}
+		retv.Window = window
+	}
+	if v := os.Getenv(prefix + "MAX_AGE"); v != "" {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sMAX_AGE: %w", prefix, err))
+		}
+		retv.MaxAge = maxAge
+	}
+	if v := os.Getenv(prefix + "IGNORE_NAN_VALUES"); v != "" {
+		ignore, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sIGNORE_NAN_VALUES: %w", prefix, err))
+		}
+		retv.IgnoreNanValues = ignore
+	}
+	if v := os.Getenv(prefix + "IGNORE_INF_VALUES"); v != "" {
+		ignore, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%sIGNORE_INF_VALUES: %w", prefix, err))
+		}
+		retv.IgnoreInfValues = ignore
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	*opts = retv
+	return nil
+}
@@ -0,0 +1,49 @@
+package movingaverage
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBindFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts := Options{Window: 10, IgnoreNanValues: true}
+	BindFlags(fs, "latency-", &opts)
+
+	if err := fs.Parse([]string{"-latency-window=50", "-latency-max-age=1m"}); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Window != 50 || opts.MaxAge != time.Minute || !opts.IgnoreNanValues {
+		t.Error(opts)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("LATENCY_WINDOW", "25")
+	t.Setenv("LATENCY_MAX_AGE", "30s")
+	t.Setenv("LATENCY_IGNORE_INF_VALUES", "true")
+
+	opts := Options{Window: 10, IgnoreNanValues: true}
+	if err := LoadEnv("LATENCY_", &opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Window != 25 || opts.MaxAge != 30*time.Second || !opts.IgnoreNanValues || !opts.IgnoreInfValues {
+		t.Error(opts)
+	}
+}
+
+func TestLoadEnvInvalid(t *testing.T) {
+	t.Setenv("BAD_WINDOW", "many")
+	t.Setenv("BAD_MAX_AGE", "soon")
+
+	opts := Options{Window: 10}
+	err := LoadEnv("BAD_", &opts)
+	if err == nil || !strings.Contains(err.Error(), "BAD_WINDOW") || !strings.Contains(err.Error(), "BAD_MAX_AGE") {
+		t.Error(err)
+	}
+	if opts.Window != 10 {
+		t.Error(opts)
+	}
+}
@@ -0,0 +1,150 @@
+package movingaverage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GrafanaLivePusher periodically pushes the Summary of each instance in a Registry to a
+// Grafana Live stream via Grafana's HTTP push API, for live-updating dashboard panels
+// without a metrics database.
+//
+// Each instance is pushed as one line of InfluxDB line protocol, whose measurement is the
+// instance's name and whose tags are its labels:
+//
+//	api,route=/ count=3i,avg=2,min=1,max=3 1714564800000000000
+//
+// Since line protocol can't represent them, non-finite stats (NaN and ±Inf) are omitted, as
+// are the average, minimum, and maximum of empty windows.
+//
+// GrafanaLivePusher is safe for concurrent use by multiple goroutines.
+type GrafanaLivePusher struct {
+	reg      *Registry
+	url      string
+	token    string
+	interval time.Duration
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewGrafanaLivePusher returns a new GrafanaLivePusher which pushes the instances in the
+// given Registry to the Grafana Live stream with the given ID, on the Grafana server at
+// grafanaURL (e.g. "https://grafana.example.com"), every interval once Run is called.
+// The token is a Grafana service account token with permission to publish to the stream;
+// the data is then available in Grafana under the stream/<streamID>/<name> channels.
+func NewGrafanaLivePusher(reg *Registry, grafanaURL, streamID, token string, interval time.Duration) *GrafanaLivePusher {
+	return &GrafanaLivePusher{
+		reg:      reg,
+		url:      strings.TrimSuffix(grafanaURL, "/") + "/api/live/push/" + url.PathEscape(streamID),
+		token:    token,
+		interval: interval,
+		client:   http.DefaultClient,
+		now:      time.Now,
+	}
+}
+
+// Push immediately pushes the Summary of each registered instance, in a single request.
+// It returns an error if the request fails or Grafana responds with a non-2xx status.
+func (p *GrafanaLivePusher) Push(ctx context.Context) error {
+	body := appendLineProtocol(nil, p.now(), p.reg.Summaries())
+	if len(body) == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("movingaverage: Grafana Live push failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Run calls Push every interval until the given context is canceled or Push returns an error.
+// It returns the context's error or the error returned by Push, or an error immediately if
+// the interval isn't positive.
+func (p *GrafanaLivePusher) Run(ctx context.Context) error {
+	if p.interval <= 0 {
+		return errNonPositiveInterval
+	}
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := p.Push(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// appendLineProtocol appends the given summaries to buf in InfluxDB line protocol,
+// one line per summary, per GrafanaLivePusher.
+func appendLineProtocol(buf []byte, now time.Time, summaries []RegisteredSummary) []byte {
+	for _, s := range summaries {
+		buf = append(buf, lineProtocolEscaper.Replace(s.Name)...)
+
+		keys := make([]string, 0, len(s.Labels))
+		for k := range s.Labels {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if s.Labels[k] == "" {
+				continue // line protocol doesn't allow empty tag values
+			}
+			buf = append(buf, ',')
+			buf = append(buf, lineProtocolEscaper.Replace(k)...)
+			buf = append(buf, '=')
+			buf = append(buf, lineProtocolEscaper.Replace(s.Labels[k])...)
+		}
+
+		buf = append(buf, " count="...)
+		buf = strconv.AppendInt(buf, int64(s.Count), 10)
+		buf = append(buf, 'i')
+		if s.Count > 0 {
+			for _, f := range []struct {
+				key string
+				v   float64
+			}{{"avg", s.Avg}, {"min", s.Min}, {"max", s.Max}} {
+				if math.IsNaN(f.v) || math.IsInf(f.v, 0) {
+					continue
+				}
+				buf = append(buf, ',')
+				buf = append(buf, f.key...)
+				buf = append(buf, '=')
+				buf = strconv.AppendFloat(buf, f.v, 'g', -1, 64)
+			}
+		}
+
+		buf = append(buf, ' ')
+		buf = strconv.AppendInt(buf, now.UnixNano(), 10)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+// lineProtocolEscaper escapes measurement names, tag keys, and tag values in line protocol.
+var lineProtocolEscaper = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
@@ -0,0 +1,70 @@
+package movingaverage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGrafanaLivePusher(t *testing.T) {
+	var path, auth, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		path, auth, body = r.URL.Path, r.Header.Get("Authorization"), string(b)
+	}))
+	defer srv.Close()
+
+	reg := NewRegistry()
+	a := New(Options{Window: 3})
+	a.Add(1, 2, 3)
+	reg.Register("api latency", a, map[string]string{"route": "/", "region": "us,east"})
+	b := New(Options{Window: 3})
+	b.Add(math.Inf(1))
+	reg.Register("queue", b, nil)
+	reg.Register("idle", New(Options{Window: 3}), nil)
+
+	p := NewGrafanaLivePusher(reg, srv.URL+"/", "app", "secret", time.Minute)
+	p.now = func() time.Time { return time.Unix(1714564800, 0) }
+	if err := p.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/api/live/push/app" || auth != "Bearer secret" {
+		t.Error(path, auth)
+	}
+	expected := `api\ latency,region=us\,east,route=/ count=3i,avg=2,min=1,max=3 1714564800000000000
+idle count=0i 1714564800000000000
+queue count=1i 1714564800000000000
+`
+	if body != expected {
+		t.Error(body)
+	}
+}
+
+func TestGrafanaLivePusherError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	reg := NewRegistry()
+	reg.Register("a", New(Options{Window: 3}), nil)
+	p := NewGrafanaLivePusher(reg, srv.URL, "app", "nope", time.Millisecond)
+	err := p.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "invalid token") {
+		t.Error(err)
+	}
+}
+
+func TestGrafanaLivePusherInvalidInterval(t *testing.T) {
+	p := NewGrafanaLivePusher(NewRegistry(), "http://localhost", "app", "", -time.Second)
+	if err := p.Run(context.Background()); !errors.Is(err, errNonPositiveInterval) {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,126 @@
+package movingaverage
+
+import (
+	"math"
+	"time"
+)
+
+// HealthOptions configures the detectors combined by MovingStats.HealthScore.
+// Each detector is disabled if its limit is zero.
+type HealthOptions struct {
+	// The z-score detector's limit: its score falls from 1, when the newest value is at the
+	// window's mean, to 0, when it is MaxZScore standard deviations from the mean (per ZScore).
+	MaxZScore float64
+
+	// The CUSUM detector's decision threshold, h, in standard deviations, e.g. 5: its score
+	// falls from 1 to 0 as the two-sided cumulative sum (CUSUM) of the values' deviations from
+	// their mean reaches CUSUMThreshold. The CUSUM detects small, persistent shifts in the
+	// mean, which the z-score of any single value doesn't. Each value is standardized against
+	// the exponentially weighted mean and standard deviation of the values before it (as by
+	// EWVariance), so the CUSUM recovers as the mean settles at a new level. Its score is 0
+	// while the newest value is NaN or Inf, or if the mean or variance overflowed.
+	CUSUMThreshold float64
+
+	// The CUSUM detector's slack, k, in standard deviations: the deviation from the mean
+	// tolerated per value without accumulating. If zero, 0.5 is used.
+	CUSUMSlack float64
+
+	// The staleness detector's limit: its score falls from 1, when a value has just been
+	// added, to 0, when none has been added for MaxStaleness. Setting it makes the instance
+	// record the time each value is added.
+	MaxStaleness time.Duration
+}
+
+// defaultCUSUMSlack is the CUSUM detector's slack if HealthOptions.CUSUMSlack is zero.
+const defaultCUSUMSlack = 0.5
+
+// cusum is a two-sided cumulative sum of standardized deviations from the mean, updated
+// incrementally as values are added.
+type cusum struct {
+	pos, neg  float64
+	nonFinite bool // whether the newest value was NaN or Inf
+}
+
+// add updates the sums with the given value, standardized against the given moments,
+// which must not yet include the value. Until the moments have a variance, it's ignored.
+// A non-finite value doesn't change the sums, but the detector reports it, per value,
+// until a finite value is added.
+func (c *cusum) add(v float64, m *ewMoments, slack float64) {
+	c.nonFinite = math.IsNaN(v) || math.IsInf(v, 0)
+	if c.nonFinite || !m.started || !(m.variance > 0) {
+		return
+	}
+	z := (v - m.mean) / math.Sqrt(m.variance)
+	if math.IsNaN(z) || math.IsInf(z, 0) {
+		return
+	}
+	c.pos = max(0, c.pos+z-slack)
+	c.neg = max(0, c.neg-z-slack)
+}
+
+// value returns the larger of the two sums, or +Inf if the newest value was non-finite.
+func (c *cusum) value() float64 {
+	if c.nonFinite {
+		return math.Inf(1)
+	}
+	return max(c.pos, c.neg)
+}
+
+func (ma *movingStats) cusumSlack() float64 {
+	if ma.health.CUSUMSlack > 0 {
+		return ma.health.CUSUMSlack
+	}
+	return defaultCUSUMSlack
+}
+
+func (ma *movingStats) HealthScore() float64 {
+	retv := 1.0
+	if limit := ma.health.MaxZScore; limit > 0 {
+		retv = min(retv, healthScore(math.Abs(ma.ZScore()), limit))
+	}
+	if limit := ma.health.CUSUMThreshold; limit > 0 {
+		retv = min(retv, healthScore(ma.cusumValue(), limit))
+	}
+	if limit := ma.health.MaxStaleness; limit > 0 && ma.times != nil {
+		// The time the newest value was added, even if it has since expired
+		if times := ma.times.Slice(); len(times) > 0 {
+			age := ma.now().Sub(times[len(times)-1])
+			retv = min(retv, healthScore(float64(age), float64(limit)))
+		}
+	}
+	return retv
+}
+
+// cusumValue returns the larger of the instance's two CUSUM sums, or +Inf if the newest
+// value or the moments the values are standardized against aren't finite (e.g. after the
+// variance overflows), so the detector reports garbage rather than silently stopping.
+func (ma *movingStats) cusumValue() float64 {
+	if ma.cusum != nil {
+		return cusumValue(ma.cusum, ma.ew)
+	}
+
+	// Views created by LastN have no incremental state; calculate from their values
+	var m ewMoments
+	var c cusum
+	for _, v := range ma.statValues() {
+		c.add(v, &m, ma.cusumSlack())
+		m.add(v, ma.ewAlpha())
+	}
+	return cusumValue(&c, &m)
+}
+
+func cusumValue(c *cusum, m *ewMoments) float64 {
+	if math.IsNaN(m.mean) || math.IsInf(m.mean, 0) || math.IsNaN(m.variance) || math.IsInf(m.variance, 0) {
+		return math.Inf(1)
+	}
+	return c.value()
+}
+
+// healthScore returns a detector's score for the given statistic: 1 at zero, falling
+// linearly to 0 at the given limit.
+func healthScore(x, limit float64) float64 {
+	if math.IsNaN(x) {
+		return 0.0
+	}
+	return max(0, 1-x/limit)
+}
@@ -0,0 +1,92 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHealthScore(t *testing.T) {
+	a := NewConcurrent(Options{Window: 8})
+	a.Add(1, 100)
+	if a.HealthScore() != 1 {
+		t.Error(a.HealthScore())
+	}
+
+	// mean 5, population stddev 2, so the newest value's z-score is 2
+	b := NewConcurrent(Options{Window: 8, Health: HealthOptions{MaxZScore: 4}})
+	if b.HealthScore() != 1 {
+		t.Error(b.HealthScore())
+	}
+	b.Add(2, 4, 4, 4, 5, 5, 7, 9)
+	if b.HealthScore() != 0.5 {
+		t.Error(b.HealthScore())
+	}
+}
+
+func TestHealthScoreCUSUM(t *testing.T) {
+	a := New(Options{Window: 20, Health: HealthOptions{CUSUMThreshold: 5}})
+	for i := 0; i < 50; i++ {
+		a.Add(9 + 2*float64(i%2))
+	}
+	if score := a.HealthScore(); score < 0.8 {
+		t.Error(score)
+	}
+	if score := a.LastN(10).HealthScore(); score < 0.5 {
+		t.Error(score)
+	}
+
+	// a persistent shift of the mean
+	for i := 0; i < 5; i++ {
+		a.Add(12)
+	}
+	if a.HealthScore() != 0 {
+		t.Error(a.HealthScore())
+	}
+
+	a.Restore(Snapshot{})
+	if a.HealthScore() != 1 {
+		t.Error(a.HealthScore())
+	}
+}
+
+func TestHealthScoreNonFinite(t *testing.T) {
+	a := New(Options{Window: 20, Health: HealthOptions{CUSUMThreshold: 5}})
+	for i := 0; i < 50; i++ {
+		a.Add(9 + 2*float64(i%2))
+	}
+
+	// a non-finite value is reported, by the instance and its views, until a finite one is added
+	a.Add(math.NaN())
+	if a.HealthScore() != 0 || a.LastN(10).HealthScore() != 0 {
+		t.Error(a.HealthScore(), a.LastN(10).HealthScore())
+	}
+	a.Add(9, 11)
+	if score := a.HealthScore(); score < 0.8 {
+		t.Error(score)
+	}
+
+	// as are moments which overflowed
+	a.Add(math.MaxFloat64, -math.MaxFloat64, 10)
+	if a.HealthScore() != 0 {
+		t.Error(a.HealthScore())
+	}
+}
+
+func TestHealthScoreStaleness(t *testing.T) {
+	now := time.Now()
+	a := newMovingStats(Options{Window: 8, MaxAge: time.Second, Health: HealthOptions{MaxStaleness: 10 * time.Second}})
+	a.now = func() time.Time { return now }
+	if a.HealthScore() != 1 {
+		t.Error(a.HealthScore())
+	}
+	a.Add(1)
+	now = now.Add(5 * time.Second)
+	if a.HealthScore() != 0.5 {
+		t.Error(a.HealthScore())
+	}
+	now = now.Add(time.Minute)
+	if a.HealthScore() != 0 {
+		t.Error(a.HealthScore())
+	}
+}
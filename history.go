@@ -0,0 +1,74 @@
+package movingaverage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cdzombak/golang-moving-average/ringbuf"
+)
+
+// TimedSummary is a Summary along with the time it was taken.
+type TimedSummary struct {
+	Time time.Time
+	Summary
+}
+
+// SummaryHistory retains the most recent Summaries of a MovingStats instance, e.g. one per
+// minute over the last hour, so a process can tell how the window's stats have themselves
+// evolved without external storage.
+//
+// SummaryHistory is safe for concurrent use by multiple goroutines, provided the
+// MovingStats instance it summarizes is (i.e. it was created by NewConcurrent).
+type SummaryHistory struct {
+	ms      MovingStats
+	entries *ringbuf.Ring[TimedSummary]
+	now     func() time.Time
+	mux     sync.RWMutex
+}
+
+// NewSummaryHistory returns a new SummaryHistory retaining the last size Summaries of ms.
+func NewSummaryHistory(ms MovingStats, size int) *SummaryHistory {
+	return &SummaryHistory{
+		ms:      ms,
+		entries: ringbuf.New[TimedSummary](size),
+		now:     time.Now,
+	}
+}
+
+// Record takes a Summary of the MovingStats instance now, retains it (evicting the oldest
+// retained Summary if the history is full), and returns it.
+func (h *SummaryHistory) Record() TimedSummary {
+	entry := TimedSummary{
+		Time:    h.now(),
+		Summary: h.ms.Summary(),
+	}
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.entries.Push(entry)
+	return entry
+}
+
+// History returns a copy of the retained Summaries, oldest first.
+func (h *SummaryHistory) History() []TimedSummary {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	retv := make([]TimedSummary, h.entries.Len())
+	_ = copy(retv, h.entries.Slice())
+	return retv
+}
+
+// Run calls Record every interval until the given context is canceled.
+// It returns the context's error.
+func (h *SummaryHistory) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			h.Record()
+		}
+	}
+}
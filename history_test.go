@@ -0,0 +1,50 @@
+package movingaverage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSummaryHistory(t *testing.T) {
+	ms := New(Options{Window: 2})
+	h := NewSummaryHistory(ms, 2)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	if len(h.History()) != 0 {
+		t.Error(h.History())
+	}
+
+	for _, v := range []float64{2, 4, 8} {
+		ms.Add(v)
+		h.Record()
+		now = now.Add(time.Minute)
+	}
+
+	got := h.History()
+	if len(got) != 2 {
+		t.Fatal(got)
+	}
+	if got[0].Avg != 3 || got[1].Avg != 6 || got[1].Count != 2 {
+		t.Error(got)
+	}
+	if !got[1].Time.Equal(got[0].Time.Add(time.Minute)) {
+		t.Error(got[0].Time, got[1].Time)
+	}
+}
+
+func TestSummaryHistoryRun(t *testing.T) {
+	h := NewSummaryHistory(NewConcurrent(Options{Window: 2}), 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- h.Run(ctx, time.Millisecond) }()
+
+	for len(h.History()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,59 @@
+package movingaverage
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// LatencyTracker tracks a moving window of latencies and provides the percentiles
+// services commonly report (P50, P95, P99), plus optional Prometheus export.
+//
+// Percentiles are calculated using the nearest-rank method unless Options.QuantileInterpolation is set.
+// LatencyTracker is safe for concurrent use by multiple goroutines.
+type LatencyTracker struct {
+	*DurationStats
+}
+
+// NewLatencyTracker returns a new LatencyTracker with the given options.
+func NewLatencyTracker(opts Options) *LatencyTracker {
+	return &LatencyTracker{
+		DurationStats: NewConcurrentDurationStats(opts),
+	}
+}
+
+// Percentile returns the given percentile (0-100] of the latencies in the window, per
+// MovingStats.Percentile. If no values have been added or any other error occurs, 0 is returned.
+func (l *LatencyTracker) Percentile(p float64) time.Duration {
+	return time.Duration(l.ms.Percentile(p))
+}
+
+// P50 returns the 50th percentile (median) of the latencies in the window.
+// If no values have been added or any other error occurs, 0 is returned.
+func (l *LatencyTracker) P50() time.Duration {
+	return l.Percentile(50)
+}
+
+// P95 returns the 95th percentile of the latencies in the window.
+// If no values have been added or any other error occurs, 0 is returned.
+func (l *LatencyTracker) P95() time.Duration {
+	return l.Percentile(95)
+}
+
+// P99 returns the 99th percentile of the latencies in the window.
+// If no values have been added or any other error occurs, 0 is returned.
+func (l *LatencyTracker) P99() time.Duration {
+	return l.Percentile(99)
+}
+
+// WritePrometheus writes the tracker's P50, P95, and P99 latencies, sum, and count to w
+// as a Prometheus summary with the given metric name, per WritePrometheusSummary, followed
+// by its max latency as a gauge named <name>_max, in the Prometheus text exposition format.
+// Latencies are written in seconds, per Prometheus conventions.
+func (l *LatencyTracker) WritePrometheus(w io.Writer, name string) error {
+	if err := WritePrometheusSummary(w, l.Stats(), name, 0.5, 0.95, 0.99); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "# TYPE %[1]s_max gauge\n%[1]s_max %[2]g\n", name, l.Max().Seconds())
+	return err
+}
@@ -0,0 +1,66 @@
+package movingaverage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	l := NewLatencyTracker(Options{Window: 100})
+	if l.P99() != 0 {
+		t.Error(l.P99())
+	}
+
+	for i := 1; i <= 100; i++ {
+		l.Observe(time.Duration(i) * time.Millisecond)
+	}
+	if l.P50() != 50*time.Millisecond {
+		t.Error(l.P50())
+	}
+	if l.P95() != 95*time.Millisecond {
+		t.Error(l.P95())
+	}
+	if l.P99() != 99*time.Millisecond {
+		t.Error(l.P99())
+	}
+	if l.Max() != 100*time.Millisecond {
+		t.Error(l.Max())
+	}
+}
+
+func TestLatencyTrackerOptions(t *testing.T) {
+	l := NewLatencyTracker(Options{Window: 10, MinSamples: 3, QuantileInterpolation: QuantileLinear})
+	l.Observe(time.Millisecond, 2*time.Millisecond)
+	// too few samples, as for the embedded DurationStats
+	if l.P50() != 0 || l.P50() != l.Median() {
+		t.Error(l.P50(), l.Median())
+	}
+
+	l.Observe(3*time.Millisecond, 4*time.Millisecond)
+	if l.P50() != 2500*time.Microsecond || l.P50() != l.Median() {
+		t.Error(l.P50(), l.Median())
+	}
+}
+
+func TestLatencyTrackerWritePrometheus(t *testing.T) {
+	l := NewLatencyTracker(Options{Window: 4})
+	l.Observe(time.Second, 2*time.Second, 3*time.Second, 4*time.Second)
+
+	var sb strings.Builder
+	if err := l.WritePrometheus(&sb, "request_latency_seconds"); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# TYPE request_latency_seconds summary
+request_latency_seconds{quantile="0.5"} 2
+request_latency_seconds{quantile="0.95"} 4
+request_latency_seconds{quantile="0.99"} 4
+request_latency_seconds_sum 10
+request_latency_seconds_count 4
+# TYPE request_latency_seconds_max gauge
+request_latency_seconds_max 4
+`
+	if sb.String() != expected {
+		t.Error(sb.String())
+	}
+}
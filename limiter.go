@@ -0,0 +1,57 @@
+package movingaverage
+
+import (
+	"sync"
+	"time"
+)
+
+// SlidingWindowLimiter is a rate limiter which allows at most a given number of events
+// within any sliding window of a given duration, e.g. 100 requests per minute.
+//
+// Each allowed event is held until it is older than the window, so memory use is
+// proportional to the limit. SlidingWindowLimiter is safe for concurrent use by multiple goroutines.
+type SlidingWindowLimiter struct {
+	limit  int
+	events *movingStats
+	mux    sync.Mutex
+}
+
+// NewSlidingWindowLimiter returns a new SlidingWindowLimiter allowing at most limit events
+// within any sliding window of the given duration.
+func NewSlidingWindowLimiter(limit int, window time.Duration) *SlidingWindowLimiter {
+	return &SlidingWindowLimiter{
+		limit:  limit,
+		events: newMovingStats(Options{Window: limit, MaxAge: window}),
+	}
+}
+
+// Allow reports whether an event may happen now, and if so, records it.
+func (l *SlidingWindowLimiter) Allow() bool {
+	return l.AllowN(1)
+}
+
+// AllowN reports whether n events may happen now, and if so, records them.
+// If it returns false, none of the events are recorded.
+func (l *SlidingWindowLimiter) AllowN(n int) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if n < 0 || l.events.Count()+n > l.limit {
+		return false
+	}
+	for ; n > 0; n-- {
+		l.events.Add(1)
+	}
+	return true
+}
+
+// Count returns the number of events recorded within the current window.
+func (l *SlidingWindowLimiter) Count() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.events.Count()
+}
+
+// Limit returns the maximum number of events allowed within the window.
+func (l *SlidingWindowLimiter) Limit() int {
+	return l.limit
+}
@@ -0,0 +1,38 @@
+package movingaverage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlidingWindowLimiter(t *testing.T) {
+	l := NewSlidingWindowLimiter(3, time.Minute)
+	now := time.Now()
+	l.events.now = func() time.Time { return now }
+
+	if !l.Allow() {
+		t.Fatal("event within the limit was not allowed")
+	}
+	now = now.Add(30 * time.Second)
+	if !l.AllowN(2) {
+		t.Fatal("events within the limit were not allowed")
+	}
+	if l.Allow() {
+		t.Error("event over the limit was allowed")
+	}
+	if l.Count() != 3 || l.Limit() != 3 {
+		t.Error(l.Count(), l.Limit())
+	}
+
+	// the first event slides out of the window
+	now = now.Add(30*time.Second + time.Nanosecond)
+	if l.AllowN(2) {
+		t.Error("events over the limit were allowed")
+	}
+	if !l.Allow() || l.Count() != 3 {
+		t.Error(l.Count())
+	}
+	if l.AllowN(-1) {
+		t.Error("negative n was allowed")
+	}
+}
@@ -0,0 +1,65 @@
+package movingaverage
+
+import (
+	"math"
+
+	"github.com/montanaflynn/stats"
+)
+
+func (ma *movingStats) ValuesDownsampled(maxPoints int) stats.Float64Data {
+	return lttb(ma.filledValues(), maxPoints)
+}
+
+// lttb downsamples the given values, treated as evenly spaced, to at most maxPoints of them
+// using the Largest-Triangle-Three-Buckets algorithm (Steinarsson, 2013), which preserves
+// the visual shape of the series: the first and last values are kept, and the values between
+// them are divided into maxPoints-2 buckets, from each of which the value forming the largest
+// triangle with the previously selected value and the average of the next bucket is selected.
+// If there are no more than maxPoints values, a copy of all of them is returned. Since the
+// first and last values are always kept, a maxPoints of 1 is treated as 2.
+func lttb(values stats.Float64Data, maxPoints int) stats.Float64Data {
+	n := len(values)
+	if maxPoints == 1 {
+		maxPoints = 2
+	}
+	switch {
+	case maxPoints >= n:
+		retv := make(stats.Float64Data, n)
+		_ = copy(retv, values)
+		return retv
+	case maxPoints <= 0:
+		return stats.Float64Data{}
+	case maxPoints == 2:
+		return stats.Float64Data{values[0], values[n-1]}
+	}
+
+	retv := make(stats.Float64Data, 0, maxPoints)
+	retv = append(retv, values[0])
+	bucketSize := float64(n-2) / float64(maxPoints-2)
+	a := 0 // the previously selected value
+	for i := 0; i < maxPoints-2; i++ {
+		// The average of the next bucket (the last value, for the last bucket)
+		nextStart := int(float64(i+1)*bucketSize) + 1
+		nextEnd := min(int(float64(i+2)*bucketSize)+1, n)
+		var avgX, avgY float64
+		for j := nextStart; j < nextEnd; j++ {
+			avgX += float64(j)
+			avgY += values[j]
+		}
+		avgX /= float64(nextEnd - nextStart)
+		avgY /= float64(nextEnd - nextStart)
+
+		// The value in this bucket forming the largest triangle with a and that average
+		ax, ay := float64(a), values[a]
+		selected, maxArea := nextStart-1, -1.0
+		for j := int(float64(i)*bucketSize) + 1; j < nextStart; j++ {
+			area := math.Abs((ax-avgX)*(values[j]-ay) - (ax-float64(j))*(avgY-ay))
+			if area > maxArea {
+				selected, maxArea = j, area
+			}
+		}
+		retv = append(retv, values[selected])
+		a = selected
+	}
+	return append(retv, values[n-1])
+}
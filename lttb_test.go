@@ -0,0 +1,57 @@
+package movingaverage
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/montanaflynn/stats"
+)
+
+func TestValuesDownsampled(t *testing.T) {
+	ma := New(Options{Window: 10})
+	ma.Add(0, 1, -1, 5, 0.5, 1, -2, 2, 0, 1)
+
+	// Per the reference implementation
+	if got := ma.ValuesDownsampled(4); !slices.Equal(got, stats.Float64Data{0, 5, -2, 1}) {
+		t.Error(got)
+	}
+	if got := ma.ValuesDownsampled(5); !slices.Equal(got, stats.Float64Data{0, -1, 5, -2, 1}) {
+		t.Error(got)
+	}
+
+	// The oldest and newest values are always kept
+	for _, maxPoints := range []int{1, 2} {
+		if got := ma.ValuesDownsampled(maxPoints); !slices.Equal(got, stats.Float64Data{0, 1}) {
+			t.Error(maxPoints, got)
+		}
+	}
+	if got := ma.ValuesDownsampled(0); len(got) != 0 {
+		t.Error(got)
+	}
+
+	// A copy of every value, if there are no more than maxPoints
+	for _, maxPoints := range []int{10, 20} {
+		got := ma.ValuesDownsampled(maxPoints)
+		if !slices.Equal(got, ma.Values()) {
+			t.Error(maxPoints, got)
+		}
+		got[0] = 100
+		if ma.Values()[0] != 0 {
+			t.Error("expected a copy of the values")
+		}
+	}
+}
+
+func TestValuesDownsampledEmpty(t *testing.T) {
+	ma := New(Options{Window: 10})
+	for _, maxPoints := range []int{0, 1, 2, 5} {
+		if got := ma.ValuesDownsampled(maxPoints); len(got) != 0 {
+			t.Error(maxPoints, got)
+		}
+	}
+
+	ma.Add(3)
+	if got := ma.ValuesDownsampled(1); !slices.Equal(got, stats.Float64Data{3}) {
+		t.Error(got)
+	}
+}
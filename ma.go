@@ -1,9 +1,16 @@
 package movingaverage
 
 import (
+	"context"
+	"io"
 	"math"
+	"slices"
+	"sync/atomic"
+	"time"
 
 	"github.com/montanaflynn/stats"
+
+	"github.com/cdzombak/golang-moving-average/ringbuf"
 )
 
 // MovingStats holds the most recently added N values (N = Options.Window)
@@ -15,38 +22,339 @@ import (
 // 0.0 if an error occurs.
 //
 // (Avg() is the (non-geometric) mean of the values, and Median() is the median.)
+//
+// Count(), CountAbove(), CountBelow(), SlotsFilled(), Avg(), Sum(), SumOfSquares(), Product(),
+// Min(), Max(), MinMax(), and Summary() do not allocate, so they are suitable for hot paths.
 type MovingStats interface {
 	// Add adds the given values to the moving stats instance.
 	Add(values ...float64)
 
+	// AddAccepted adds the given values to the moving stats instance, like Add, and returns
+	// the number of them which were accepted, i.e. not dropped by the instance's filters.
+	AddAccepted(values ...float64) int
+
 	// Window returns the number of values kept in the moving stats instance.
 	Window() int
 
 	// SlotsFilled returns whether all slots in the moving stats instance have been filled.
 	SlotsFilled() bool
 
-	// Values returns the values in the moving stats instance, as stats.Float64Data.
+	// FillRatio returns how full the moving stats instance is, from 0.0 to 1.0: Count()/Window().
+	// For instances with a MaxAge, it is the greater of that and the fraction of MaxAge covered
+	// by the values, i.e. the age of the oldest value divided by MaxAge.
+	FillRatio() float64
+
+	// IngestRate returns the rate at which values have been added to the moving stats instance,
+	// in values per second: the number of values in the window, less one, divided by the time
+	// since the oldest was added. It falls when values stop arriving, so pipelines can detect
+	// upstream slowdowns even when the values themselves look normal. Only values accepted by the
+	// filters are counted. It requires Options.TrackIngestRate or a time-based window; otherwise,
+	// or if fewer than two values have been added, 0.0 is returned.
+	IngestRate() float64
+
+	// OldestAge returns how long ago the oldest value in the moving stats instance was added,
+	// qualifying how representative its stats are. It requires a time-based window or
+	// Options.TrackIngestRate; otherwise, or if no values have been added, 0 is returned.
+	OldestAge() time.Duration
+
+	// NewestAge returns how long ago the newest value in the moving stats instance was added,
+	// qualifying how fresh its stats are. It requires a time-based window or
+	// Options.TrackIngestRate; otherwise, or if no values have been added, 0 is returned.
+	NewestAge() time.Duration
+
+	// Span returns the time covered by the values in the moving stats instance: the time between
+	// when the oldest and newest were added. It requires a time-based window or
+	// Options.TrackIngestRate; otherwise, or if no values have been added, 0 is returned.
+	Span() time.Duration
+
+	// Values returns a copy of the values in the moving stats instance, as stats.Float64Data.
+	// The values are returned in the order they were added, oldest first.
 	Values() stats.Float64Data
 
+	// ValuesWithTimes returns a copy of the values in the moving stats instance along with
+	// the times they were added, oldest first, e.g. for plotting or export. It returns nil
+	// if the instance doesn't track the times values were added (see Snapshot.Times).
+	ValuesWithTimes() []TimedValue
+
+	// ValuesDownsampled returns at most maxPoints of the values in the moving stats instance,
+	// oldest first, selected with the Largest-Triangle-Three-Buckets algorithm to preserve the
+	// visual shape of the series, so huge windows can be plotted without shipping every value.
+	// The oldest and newest values are always kept, so a maxPoints of 1 is treated as 2, and
+	// a maxPoints of 0 or less returns no values. If there are no more than maxPoints values,
+	// a copy of all of them is returned, as by Values.
+	ValuesDownsampled(maxPoints int) stats.Float64Data
+
+	// SortedValues returns the values in the moving stats instance, sorted in ascending order.
+	// The sorted values are cached until the next call to Add, and a copy is returned on each call.
+	SortedValues() stats.Float64Data
+
 	// Count returns the number of values in the moving stats instance.
 	Count() int
 
-	// Avg returns the average of the values in the moving stats instance.
+	// CountAbove returns the number of values in the moving stats instance which are greater than t,
+	// without copying them.
+	CountAbove(t float64) int
+
+	// CountBelow returns the number of values in the moving stats instance which are less than t,
+	// without copying them.
+	CountBelow(t float64) int
+
+	// Avg returns the average of the values in the moving stats instance, blended with
+	// Options.PriorMean until the window fills if Options.PriorWeight is set.
 	// If no values have been added or any other error occurs, 0.0 is returned.
 	Avg() float64
 
-	// Median returns the median of the values in the moving stats instance.
+	// Sum returns the sum of the values in the moving stats instance. It is maintained
+	// incrementally as values are added and evicted, so it takes O(1) time.
+	// If no values have been added or any other error occurs, 0.0 is returned.
+	Sum() float64
+
+	// SumOfSquares returns the sum of the squares of the values in the moving stats instance, e.g. for
+	// incremental regressions. Like Sum, it is maintained incrementally, so it takes O(1) time.
 	// If no values have been added or any other error occurs, 0.0 is returned.
+	SumOfSquares() float64
+
+	// Product returns the product of the values in the moving stats instance, e.g. for compounding
+	// growth rates. It is maintained incrementally as the sum of the values' logarithms, so it takes
+	// O(1) time, but is subject to rounding even when the values are integers.
+	// If no values have been added or any other error occurs, 0.0 is returned.
+	Product() float64
+
+	// SumLast returns the sum of the newest k values in the moving stats instance, so one long
+	// window can also answer short-horizon questions. If fewer than k values have been added, it's
+	// the sum of all of them. If no values have been added or k < 1, 0.0 is returned.
+	SumLast(k int) float64
+
+	// AvgLast returns the average of the newest k values in the moving stats instance.
+	// If fewer than k values have been added, it's the average of all of them.
+	// If no values have been added or k < 1, 0.0 is returned.
+	AvgLast(k int) float64
+
+	// TrendPct returns the percent change of the average of the values in the moving stats instance
+	// versus the average of the previous window: the Window values most recently evicted from it,
+	// e.g. 35 if the average is up 35% versus the prior period. It requires Options.TrackTrend.
+	// If the previous window is empty or its average is zero, or the instance has no values,
+	// or the trend isn't tracked (including for views created by LastN), 0.0 is returned.
+	TrendPct() float64
+
+	// Median returns the median of the values in the moving stats instance, biased toward recent
+	// values if Options.MedianDecay is set. If no values have been added or any other error occurs, 0.0 is returned.
 	Median() float64
 
+	// MedianAbsoluteDeviation returns the median absolute deviation (MAD) of the values in the moving
+	// stats instance: the median of their absolute deviations from their median, a measure of spread
+	// robust to outliers. It is not scaled; for normally distributed values, multiply it by 1.4826
+	// to estimate the standard deviation, e.g. for robust z-scores.
+	// If no values have been added or any other error occurs, 0.0 is returned.
+	MedianAbsoluteDeviation() float64
+
+	// Percentile returns the given percentile (0-100] of the values in the moving stats instance,
+	// calculated using the nearest-rank method unless Options.QuantileInterpolation is set.
+	// If no values have been added, p is out of range, or any other error occurs, 0.0 is returned.
+	Percentile(p float64) float64
+
+	// PercentileSummary returns the given percentiles (0-100] of the values in the moving stats
+	// instance, keyed by percentile, calculated together from one sort of the values, as by
+	// Percentile. If no percentiles are given, the 50th, 90th, 95th, and 99th are returned.
+	// If no values have been added, each is 0.0, as is any percentile out of range.
+	PercentileSummary(ps ...float64) map[float64]float64
+
+	// IQR returns the interquartile range of the values in the moving stats instance: the 75th
+	// percentile less the 25th, calculated together as by Percentile, e.g. for box plots or
+	// outlier fences. If no values have been added, 0.0 is returned.
+	IQR() float64
+
+	// PercentileRank returns the percentage (0-100) of the values in the moving stats instance which
+	// are less than or equal to x: the inverse of Percentile, e.g. to check that 99% of latencies
+	// are within 250ms. If no values have been added, 0.0 is returned.
+	PercentileRank(x float64) float64
+
+	// CDF returns the fraction (0-1) of the values in the moving stats instance which are less than
+	// or equal to x: their empirical cumulative distribution function, evaluated at x.
+	// If no values have been added, 0.0 is returned.
+	CDF(x float64) float64
+
+	// Quantiles returns the quantiles given by Options.TrackQuantiles, in the same order, which
+	// the instance maintains incrementally so they can be read without sorting the window.
+	// Quantiles are calculated per Options.QuantileInterpolation. If no values have been added,
+	// each is 0.0, as is any quantile outside [0, 1]. If no quantiles are tracked, nil is returned.
+	Quantiles() []float64
+
 	// Min returns the minimum of the values in the moving stats instance.
 	// If no values have been added or any other error occurs, 0.0 is returned.
 	Min() float64
 
+	// Value returns the instance's primary statistic, per Options.PrimaryStat: by default, its average.
+	// If no values have been added or any other error occurs, 0.0 is returned.
+	Value() float64
+
+	// EWVariance returns the exponentially weighted variance of the values added to the moving
+	// stats instance, as used by RiskMetrics and adaptive alert thresholds, with the smoothing
+	// factor Options.EMAAlpha (or 2/(Window+1) if it is zero). It is maintained incrementally as
+	// values are added, so it weights every value added, however old, by its decayed weight.
+	// If no values have been added, 0.0 is returned.
+	EWVariance() float64
+
+	// EWStdDev returns the exponentially weighted standard deviation of the values added to the
+	// moving stats instance: the square root of EWVariance.
+	EWStdDev() float64
+
 	// Max returns the maximum of the values in the moving stats instance.
 	// If no values have been added or any other error occurs, 0.0 is returned.
 	Max() float64
 
+	// MinMax returns the minimum and maximum of the values in the moving stats instance,
+	// computed in a single pass over the values.
+	// If no values have been added, 0.0 is returned for both.
+	MinMax() (min, max float64)
+
+	// Range returns the range of the values in the moving stats instance, i.e. their maximum
+	// less their minimum, e.g. the peak-to-peak level of an audio signal.
+	// If no values have been added, 0.0 is returned.
+	Range() float64
+
+	// RMS returns the root mean square of the values in the moving stats instance, e.g. the
+	// level of an audio signal. If no values have been added, 0.0 is returned.
+	RMS() float64
+
+	// Variance returns the variance of the values in the moving stats instance: the population
+	// variance, unless Options.VarianceEstimator is VarianceSample. If no values have been added
+	// (or, for the sample variance, fewer than two) or any other error occurs, 0.0 is returned.
+	Variance() float64
+
+	// StdDev returns the standard deviation of the values in the moving stats instance: the square
+	// root of Variance, per Options.VarianceEstimator. If no values have been added (or, for the
+	// sample standard deviation, fewer than two) or any other error occurs, 0.0 is returned.
+	StdDev() float64
+
+	// CV returns the coefficient of variation of the values in the moving stats instance: their
+	// standard deviation (per StdDev) divided by the absolute value of their mean, to compare the
+	// relative variability of streams of different magnitudes, e.g. latencies of different endpoints.
+	// If no values have been added or their mean is zero, 0.0 is returned.
+	CV() float64
+
+	// HealthScore returns a composite score, from 0 (misbehaving) to 1 (healthy), of whether the
+	// values in the moving stats instance are behaving as usual: the lowest score of the detectors
+	// configured by Options.Health, which detect outlying values (by z-score), shifts in the mean
+	// (by CUSUM), and values no longer being added (staleness). If no detectors are configured,
+	// 1.0 is returned.
+	HealthScore() float64
+
+	// ZScore returns the number of standard deviations (per StdDev) the most recently added
+	// value is from the mean of the values in the moving stats instance, e.g. for threshold alerting.
+	// If no values have been added or the standard deviation is zero, 0.0 is returned.
+	ZScore() float64
+
+	// ZScoreOf returns the number of standard deviations (per StdDev) x is from the mean of
+	// the values in the moving stats instance, e.g. to test a value before adding it.
+	// If no values have been added or the standard deviation is zero, 0.0 is returned.
+	ZScoreOf(x float64) float64
+
+	// Skewness returns the (population) skewness of the values in the moving stats instance, the
+	// third standardized moment: positive for a long right tail, e.g. occasional slow frames.
+	// If no values have been added or they are all equal, 0.0 is returned.
+	Skewness() float64
+
+	// Kurtosis returns the (population) excess kurtosis of the values in the moving stats instance,
+	// the fourth standardized moment less 3: zero for normally distributed values, and positive for
+	// heavier tails. If no values have been added or they are all equal, 0.0 is returned.
+	Kurtosis() float64
+
+	// GeometricMean returns the geometric mean of the values in the moving stats instance, e.g. for
+	// rolling growth rates or ratios. It is 0.0 if any value is zero; since it is undefined for
+	// negative values, 0.0 is also returned if any value is negative, if no values have been added,
+	// or if any other error occurs.
+	GeometricMean() float64
+
+	// HarmonicMean returns the harmonic mean of the values in the moving stats instance, e.g. for
+	// rolling rates such as throughput. Since it is undefined for zero and negative values, 0.0
+	// is returned if any value is zero or negative, if no values have been added, or if any
+	// other error occurs.
+	HarmonicMean() float64
+
+	// TrimmedMean returns the average of the values in the moving stats instance after discarding
+	// the given fraction of them, from 0 to 0.5, from each end of their sorted order, for a central
+	// estimate robust to occasional spikes; at least one value is always kept. If no values have
+	// been added or the fraction is out of range, 0.0 is returned.
+	TrimmedMean(fraction float64) float64
+
+	// Summary returns a point-in-time Summary of the values in the moving stats instance.
+	// If no values have been added, the Summary's fields are all zero.
+	Summary() Summary
+
+	// Unit returns the unit of the values in the moving stats instance, per Options.Unit.
+	Unit() Unit
+
+	// FormatSummary returns the instance's Summary as a human-friendly string in its Unit,
+	// e.g. "count 3, avg 12.3ms, min 1ms, max 20ms". Instances' String methods return the same.
+	FormatSummary() string
+
+	// TopK returns the k largest values in the moving stats instance, largest first, e.g. the
+	// worst recent latencies. The values are selected without sorting the whole window.
+	// If fewer than k values have been added, all of them are returned. NaN values are excluded.
+	TopK(k int) stats.Float64Data
+
+	// BottomK returns the k smallest values in the moving stats instance, smallest first.
+	// The values are selected without sorting the whole window.
+	// If fewer than k values have been added, all of them are returned. NaN values are excluded.
+	BottomK(k int) stats.Float64Data
+
+	// Frequencies returns the number of times each distinct value occurs in the moving stats
+	// instance, for windows of discrete values such as status codes. NaN values are not counted.
+	// To count values in buckets instead, round them as they are added with Options.RoundTo.
+	Frequencies() map[float64]int
+
+	// Mode returns the most frequent values in the moving stats instance, smallest first, for windows
+	// of discrete values such as sensor readings; per stats.Mode, there may be more than one.
+	// If the window is empty, or every distinct value occurs equally often, nil is returned.
+	Mode() []float64
+
+	// Periodicity returns the dominant cycle length in the moving stats instance's values, in
+	// samples, and its strength: the autocorrelation of the values at that lag, from 0 (no
+	// periodicity) to 1 (perfectly periodic). Cycles longer than half the window aren't detected.
+	// If no cycle is detected, 0 and 0.0 are returned.
+	Periodicity() (period int, strength float64)
+
+	// Compute returns the given statistics of the values in the moving stats instance,
+	// calculated together: the values are traversed once and sorted at most once, and
+	// concurrency-safe instances take their lock once. Percentiles are calculated per
+	// Options.QuantileInterpolation. If no values have been added, every statistic is 0.0.
+	// Unknown kinds are mapped to NaN, whether or not values have been added.
+	Compute(kinds ...StatKind) map[StatKind]float64
+
+	// LastN returns a read-only view of the newest k values in the moving stats instance, so
+	// short- and long-horizon logic can operate on one stream of values. The view shares the
+	// instance's storage, so it reflects values added later, and its Window is k (at most the
+	// instance's Window). Views of concurrency-safe instances are concurrency-safe.
+	LastN(k int) ReadOnlyView
+
+	// Snapshot returns the state of the moving stats instance, which can be serialized
+	// and later restored via Restore.
+	Snapshot() Snapshot
+
+	// Restore replaces the values in the moving stats instance with those in the given Snapshot.
+	// The instance keeps its own options; if the snapshot holds more values than the instance's
+	// Window, only the newest are kept, and values are subject to the instance's filters and
+	// eviction policies. If the snapshot has no times, the values are recorded as added now.
+	Restore(Snapshot)
+
+	// WriteTo writes the state of the moving stats instance to w in a compact binary format,
+	// without first encoding it in memory, implementing io.WriterTo. For concurrency-safe
+	// instances, the read lock is held while writing, so slow writers should be buffered.
+	WriteTo(w io.Writer) (n int64, err error)
+
+	// ReadFrom reads one snapshot written by WriteTo from r and restores it, per Restore,
+	// implementing io.ReaderFrom. If an error occurs, the instance is unchanged.
+	ReadFrom(r io.Reader) (n int64, err error)
+
+	// ApplyOptions replaces the options of the moving stats instance, e.g. to reload its config
+	// without rebuilding it. The instance's values are kept, subject to the new options: if the
+	// new Window is smaller, only the newest values are kept, and values are subject to the new
+	// filters and eviction policies (as for Restore). The new Aggregators are updated with the
+	// kept values, after the old ones are updated as if every value were evicted. DetectRaces
+	// has no effect. Views created by LastN keep the options they were created with.
+	ApplyOptions(opts Options)
+
 	// UnsafeDoStat runs the given function on the values in the moving stats instance.
 	// If the function returns an error, that error is returned.
 	// Functions passed to UnsafeDoStat must not modify the values slice or call Add(). This will result in undefined behavior.
@@ -56,8 +364,53 @@ type MovingStats interface {
 	// If the function returns an error, that error is returned.
 	// Functions passed to UnsafeDo must not modify the values slice or call Add(). This will result in undefined behavior.
 	UnsafeDo(func(stats.Float64Data) error) error
+
+	// DoStatCtx runs the given function on the values in the moving stats instance, like UnsafeDoStat,
+	// passing it ctx so that long-running statistics over huge windows can stop early once ctx is done,
+	// rather than holding a concurrency-safe instance's read lock indefinitely. If ctx is already done,
+	// the function isn't run, and ctx's error is returned. Otherwise, the function's error is returned.
+	// Functions passed to DoStatCtx must not modify the values slice or call Add(). This will result in undefined behavior.
+	DoStatCtx(ctx context.Context, f func(context.Context, stats.Float64Data) (float64, error)) (float64, error)
+
+	// DoCtx runs the given function on the values in the moving stats instance, like UnsafeDo,
+	// passing it ctx, as for DoStatCtx. If ctx is already done, the function isn't run, and ctx's
+	// error is returned. Otherwise, the function's error is returned.
+	// Functions passed to DoCtx must not modify the values slice or call Add(). This will result in undefined behavior.
+	DoCtx(ctx context.Context, f func(context.Context, stats.Float64Data) error) error
+
+	// DoLocked calls f with a read-only view of the moving stats instance, so several reads
+	// (e.g. Avg, Min, and Values) see the same values. For concurrency-safe instances, the
+	// instance's read lock is held while f runs, so f must use the view, not the instance itself,
+	// and must not call Add(). This will cause a deadlock.
+	DoLocked(f func(view ReadOnlyView))
+
+	// BorrowValues returns the values in the moving stats instance without copying them,
+	// along with a release function that must be called once the caller is done with the values.
+	// Until release is called, the values slice must not be modified and Add() must not be called.
+	// For concurrency-safe instances, the instance's read lock is held until release is called.
+	BorrowValues() (values stats.Float64Data, release func())
 }
 
+// TimedValue is a value in a moving stats instance along with the time it was added.
+type TimedValue struct {
+	Time  time.Time
+	Value float64
+}
+
+// VarianceEstimator selects how Variance, StdDev, and the statistics based on them are
+// calculated from the values in a moving stats instance.
+type VarianceEstimator int
+
+const (
+	// VariancePopulation divides the sum of squared deviations from the mean by n, the
+	// number of values, treating the window as the whole population.
+	VariancePopulation VarianceEstimator = iota
+	// VarianceSample divides the sum of squared deviations from the mean by n−1 (Bessel's
+	// correction), treating the window as a sample of a larger population. It requires at
+	// least two values.
+	VarianceSample
+)
+
 // Options configures a new movingStats instance.
 type Options struct {
 	// Whether to ignore NaN values when adding values to the moving stats instance.
@@ -66,68 +419,466 @@ type Options struct {
 	// Whether to ignore Inf values when adding values to the moving stats instance.
 	IgnoreInfValues bool
 
-	// The number of values to keep in the moving stats instance.
+	// Whether to ignore negative values when adding values to the moving stats instance.
+	IgnoreNegative bool
+
+	// Whether to ignore zero and negative values when adding values to the moving stats instance,
+	// e.g. for windows feeding geometric means or log transforms.
+	IgnoreNonPositive bool
+
+	// What to do with NaN values added to the moving stats instance, e.g. replace them with
+	// the previous value so the window keeps its cadence. IgnoreNanValues takes precedence.
+	NaNPolicy NaNPolicy
+
+	// The value NaN values are replaced with if NaNPolicy is NaNReplaceWithConstant.
+	NaNReplacement float64
+
+	// What to do with ±Inf values added to the moving stats instance, e.g. clamp them to
+	// finite bounds so they neither poison nor thin out the window. IgnoreInfValues takes precedence.
+	InfPolicy InfPolicy
+
+	// The values -Inf and +Inf values are clamped to if InfPolicy is InfClampToBounds.
+	InfClampMin, InfClampMax float64
+
+	// The statistic returned by Value. If empty, PrimaryMean is used.
+	PrimaryStat PrimaryStat
+
+	// The fraction of values, from 0 to 0.5, discarded from each end of their sorted order
+	// if PrimaryStat is PrimaryTrimmedMean. If zero, 0.1 is used.
+	TrimFraction float64
+
+	// The smoothing factor, from 0 to 1, used if PrimaryStat is PrimaryEMA, and by EWVariance and
+	// EWStdDev; higher values weight recent values more heavily. If zero, 2/(n+1) is used, where
+	// n is the number of values (or, for EWVariance and EWStdDev, the Window).
+	EMAAlpha float64
+
+	// The unit of the values, used to render them in human-friendly form.
+	Unit Unit
+
+	// If positive, values are rounded to the nearest multiple of RoundTo as they are added,
+	// e.g. 0.01 rounds to two decimal places and 5 rounds to the nearest multiple of 5.
+	RoundTo float64
+
+	// The number of values to keep in the moving stats instance. Unless Compressed is set,
+	// the instance allocates room for twice this many values (and times, if it records them),
+	// per ringbuf.Ring, so its values can be read without copying.
 	Window int
+
+	// If positive, values older than MaxAge are evicted from the moving stats instance,
+	// even if fewer than Window values have been added since.
+	// This is shorthand for an Eviction policy of MaxAgeEviction(MaxAge).
+	MaxAge time.Duration
+
+	// An additional policy for evicting the oldest values from the moving stats instance.
+	// The instance never holds more than Window values, regardless of this policy.
+	Eviction EvictionPolicy
+
+	// If positive, the statistics calculated by the instance's methods (Avg, Median, Min, Max,
+	// MinMax, Value, Summary, Compute, and Periodicity) are 0.0 until it holds at least
+	// MinSamples values, so consumers don't act on a barely-filled window. Count is unaffected.
+	MinSamples int
+
+	// A prior estimate of the average, and its weight as a number of pseudo-values, for warming
+	// up after a restart. If PriorWeight is positive, the average calculated by Avg (and by Value,
+	// Summary, and Compute) is blended with PriorMean until the instance holds Window values,
+	// so early values aren't wildly unrepresentative: it's (w·PriorMean + sum)/(w + n) for n
+	// values, where the prior's weight w = PriorWeight·(Window-n)/Window fades as the window
+	// fills. Before any values have been added (or MinSamples values, if set), it's PriorMean.
+	PriorMean, PriorWeight float64
+
+	// Whether to record the time each value is added, even if the window is not time-based,
+	// so IngestRate can report the rate at which values are added.
+	TrackIngestRate bool
+
+	// Quantiles, from 0 to 1, for the instance to maintain incrementally and return from
+	// Quantiles, e.g. []float64{0.5, 0.9, 0.99}. Tracking them keeps a sorted copy of the
+	// values, which is updated in O(Window) time as each value is added.
+	TrackQuantiles []float64
+
+	// Whether to keep the values evicted from the moving stats instance, up to Window of them,
+	// so TrendPct can compare the current window's average against the previous window's.
+	// This doubles the memory the instance uses for its values; they aren't compressed.
+	TrackTrend bool
+
+	// The detectors combined by HealthScore.
+	Health HealthOptions
+
+	// Aggregators to update as values are added to and evicted from the moving stats instance.
+	Aggregators []Aggregator
+
+	// How to calculate percentiles, including the median, which fall between two values.
+	QuantileInterpolation QuantileInterpolation
+
+	// Whether Variance, StdDev, CV, and ZScore use the population (n) or sample (n−1) variance,
+	// to match the convention of the rest of a pipeline. The default is VariancePopulation.
+	VarianceEstimator VarianceEstimator
+
+	// If in (0, 1), Median (and the median calculated by Value and Compute) is a weighted median
+	// biased toward recent values, as for DecayedStats: the newest value has weight 1, the value
+	// before it MedianDecay, the one before that MedianDecay², and so on. This suits control
+	// systems where old values should matter less, but an EMA is too jumpy. Lower values bias
+	// the median more heavily toward recent values. QuantileInterpolation does not apply.
+	MedianDecay float64
+
+	// Whether to panic if an instance created by New is used concurrently, i.e. if Add
+	// (or another method which modifies the instance) overlaps any other method call.
+	// This is a cheap check intended for debugging, not a substitute for the race detector.
+	// It has no effect on instances created by NewConcurrent.
+	DetectRaces bool
+
+	// Whether to store values (and the times they were added, for time-based windows)
+	// compressed, for very large windows of slowly changing values. Compression is lossless,
+	// but reading the values decompresses all of them into a newly allocated slice, so most
+	// methods are slower and allocate. Times are kept to the nanosecond, without a monotonic
+	// clock reading.
+	Compressed bool
 }
 
 // New returns a new MovingStats instance with the given options.
 func New(opts Options) MovingStats {
-	return &movingStats{
-		values:          make([]float64, opts.Window),
-		valPos:          0,
-		slotsFilled:     false,
+	if opts.DetectRaces {
+		return &raceDetectingStats{ma: newMovingStats(opts), state: new(atomic.Int32)}
+	}
+	return newMovingStats(opts)
+}
+
+func newMovingStats(opts Options) *movingStats {
+	ma := &movingStats{
 		window:          opts.Window,
+		compressed:      opts.Compressed,
 		ignoreInfValues: opts.IgnoreInfValues,
 		ignoreNanValues: opts.IgnoreNanValues,
+		ignoreNegative:  opts.IgnoreNegative || opts.IgnoreNonPositive,
+		ignoreZero:      opts.IgnoreNonPositive,
+		nanPolicy:       opts.NaNPolicy,
+		nanReplacement:  opts.NaNReplacement,
+		infPolicy:       opts.InfPolicy,
+		infClampMin:     opts.InfClampMin,
+		infClampMax:     opts.InfClampMax,
+		roundTo:         opts.RoundTo,
+		unit:            opts.Unit,
+		aggregators:     opts.Aggregators,
+		interpolation:   opts.QuantileInterpolation,
+		varEstimator:    opts.VarianceEstimator,
+		medianDecay:     opts.MedianDecay,
+		minSamples:      opts.MinSamples,
+		priorMean:       opts.PriorMean,
+		priorWeight:     opts.PriorWeight,
+		maxAge:          opts.MaxAge,
+		primaryStat:     opts.PrimaryStat,
+		trimFraction:    opts.TrimFraction,
+		emaAlpha:        opts.EMAAlpha,
+		health:          opts.Health,
+		ew:              &ewMoments{},
+		sum:             &runningSum{},
+		sumSquares:      &runningSum{},
+		product:         &runningProduct{},
+		trackQuantiles:  slices.Clone(opts.TrackQuantiles),
+		now:             time.Now,
+	}
+	if ma.compressed {
+		ma.values = newCompressedValues(ma.window)
+	} else {
+		ma.values = ringbuf.New[float64](ma.window)
+	}
+	if len(ma.trackQuantiles) > 0 {
+		ma.quantiles = &quantileTracker{}
+	}
+	if opts.TrackTrend {
+		ma.previous = newPreviousWindow(ma.window)
+	}
+	if opts.Health.CUSUMThreshold > 0 {
+		ma.cusum = &cusum{}
+	}
+	if ma.trimFraction == 0 {
+		ma.trimFraction = defaultTrimFraction
 	}
+	if opts.MaxAge > 0 {
+		ma.eviction = append(ma.eviction, MaxAgeEviction(opts.MaxAge))
+	}
+	if opts.Eviction != nil {
+		ma.eviction = append(ma.eviction, opts.Eviction)
+	}
+	if len(ma.eviction) > 0 || opts.TrackIngestRate || opts.Health.MaxStaleness > 0 {
+		ma.trackTimes()
+	}
+	return ma
 }
 
 type movingStats struct {
 	window          int
-	values          []float64
-	valPos          int
-	slotsFilled     bool
+	eviction        []EvictionPolicy
+	compressed      bool
+	values          buffer[float64]
+	times           buffer[time.Time]
 	ignoreNanValues bool
 	ignoreInfValues bool
+	ignoreNegative  bool
+	ignoreZero      bool
+	nanPolicy       NaNPolicy
+	nanReplacement  float64
+	infPolicy       InfPolicy
+	infClampMin     float64
+	infClampMax     float64
+	roundTo         float64
+	unit            Unit
+	minSamples      int
+	priorMean       float64
+	priorWeight     float64
+	maxAge          time.Duration
+	primaryStat     PrimaryStat
+	trimFraction    float64
+	emaAlpha        float64
+	ew              *ewMoments // nil for views created by LastN
+	trackQuantiles  []float64
+	quantiles       *quantileTracker // nil for views created by LastN
+	sum             *runningSum      // nil for views created by LastN
+	sumSquares      *runningSum      // nil for views created by LastN
+	product         *runningProduct  // nil for views created by LastN
+	previous        *previousWindow  // nil for views created by LastN
+	health          HealthOptions
+	cusum           *cusum // nil for views created by LastN
+	sorted          stats.Float64Data
+	sortedEvicted   int
+	aggregators     []Aggregator
+	interpolation   QuantileInterpolation
+	varEstimator    VarianceEstimator
+	medianDecay     float64
+	now             func() time.Time
+}
+
+// trackTimes makes the instance record the time each value was added,
+// even if it has no eviction policy which requires them.
+func (ma *movingStats) trackTimes() {
+	if ma.times == nil && ma.compressed {
+		ma.times = newCompressedTimes(ma.window)
+	} else if ma.times == nil {
+		ma.times = ringbuf.New[time.Time](ma.window)
+	}
+}
+
+// evictCount returns the number of the oldest values which the instance's
+// eviction policies would evict at the given time.
+func (ma *movingStats) evictCount(now time.Time) int {
+	retv := 0
+	for _, policy := range ma.eviction {
+		n := policy.Evict(ma.values.Slice(), ma.times.Slice(), now)
+		retv = max(retv, min(n, ma.values.Len()))
+	}
+	return retv
+}
+
+// live returns the values which have not been evicted by the instance's eviction
+// policies, and the times they were added (if tracked), oldest first, without copying them.
+// Values are excluded here even before they are evicted by Add.
+func (ma *movingStats) live() (stats.Float64Data, []time.Time) {
+	evicted := 0
+	if len(ma.eviction) > 0 {
+		evicted = ma.evictCount(ma.now())
+	}
+	var times []time.Time
+	if ma.times != nil {
+		times = ma.times.Slice()[evicted:]
+	}
+	return ma.values.Slice()[evicted:], times
 }
 
+// filledValues returns the live values, oldest first, without copying them.
 func (ma *movingStats) filledValues() stats.Float64Data {
-	var c = ma.window - 1
+	values, _ := ma.live()
+	if len(values) == 0 {
+		// Empty register
+		return nil
+	}
+	return values
+}
 
-	// Are all slots filled? If not, ignore unused
-	if !ma.slotsFilled {
-		c = ma.valPos - 1
-		if c < 0 {
-			// Empty register
-			return nil
-		}
+// statValues returns the values to calculate statistics from: the live values, like
+// filledValues, or nil if there are fewer than Options.MinSamples of them.
+func (ma *movingStats) statValues() stats.Float64Data {
+	values := ma.filledValues()
+	if len(values) < ma.minSamples {
+		return nil
+	}
+	return values
+}
+
+// newest returns the most recently added value, and false if no values have been added.
+func (ma *movingStats) newest() (float64, bool) {
+	values := ma.filledValues()
+	if len(values) == 0 {
+		return 0.0, false
+	}
+	return values[len(values)-1], true
+}
+
+// evictOldest removes the oldest value.
+func (ma *movingStats) evictOldest() {
+	val, _ := ma.values.PopOldest()
+	if ma.times != nil {
+		_, _ = ma.times.PopOldest()
+	}
+	for _, agg := range ma.aggregators {
+		agg.OnEvict(val)
+	}
+	if ma.quantiles != nil {
+		ma.quantiles.remove(val)
+	}
+	if ma.sum != nil {
+		ma.sum.remove(val)
+		ma.sumSquares.remove(val * val)
+		ma.product.remove(val)
+	}
+	if ma.previous != nil {
+		ma.previous.add(val)
 	}
-	return ma.values[0 : c+1]
 }
 
 func (ma *movingStats) Add(values ...float64) {
+	_ = ma.AddAccepted(values...)
+}
+
+func (ma *movingStats) AddAccepted(values ...float64) int {
+	var now time.Time
+	if ma.times != nil {
+		now = ma.now()
+	}
+
+	accepted := 0
 	for _, val := range values {
-		// ignore NaN?
-		if ma.ignoreNanValues && math.IsNaN(val) {
-			continue
+		if ma.push(val, now) {
+			accepted++
+		}
+	}
+
+	ma.applyEviction(now)
+	return accepted
+}
+
+// push adds a single value, recorded (if times are tracked) as added at the given time,
+// and returns false if the value was dropped by the instance's filters.
+// The caller is responsible for calling applyEviction afterward.
+func (ma *movingStats) push(val float64, t time.Time) bool {
+	// ignore or replace NaN?
+	if math.IsNaN(val) {
+		var ok bool
+		if val, ok = ma.replaceNaN(val); !ok {
+			return false
 		}
+	}
 
-		// ignore Inf?
-		if ma.ignoreInfValues && math.IsInf(val, 0) {
-			continue
+	// ignore or clamp Inf?
+	if math.IsInf(val, 0) {
+		var ok bool
+		if val, ok = ma.replaceInf(val); !ok {
+			return false
 		}
+	}
+
+	// round?
+	if ma.roundTo > 0 {
+		val = roundTo(val, ma.roundTo)
+	}
 
-		// Put into values array
-		ma.values[ma.valPos] = val
+	// ignore negative or zero values?
+	if (ma.ignoreNegative && val < 0) || (ma.ignoreZero && val == 0) {
+		return false
+	}
+
+	// Invalidate the sorted values cache
+	ma.sorted = nil
+
+	// Is the window full? If so, evict the oldest value
+	if ma.values.Len() == ma.window {
+		ma.evictOldest()
+	}
+
+	// Update aggregators
+	for _, agg := range ma.aggregators {
+		agg.OnAdd(val)
+	}
+	if ma.cusum != nil {
+		ma.cusum.add(val, ma.ew, ma.cusumSlack())
+	}
+	if ma.ew != nil {
+		ma.ew.add(val, ma.ewAlpha())
+	}
+
+	// Put into values buffer
+	ma.values.Push(val)
+	if ma.times != nil {
+		ma.times.Push(t)
+	}
+	if ma.quantiles != nil {
+		ma.quantiles.add(val)
+	}
+	if ma.sum != nil {
+		ma.sum.add(val)
+		ma.sumSquares.add(val * val)
+		ma.product.add(val)
+		if ma.sum.evictions >= ma.window {
+			values := ma.values.Slice()
+			ma.sum.reset(values)
+			*ma.sumSquares = runningSum{}
+			for _, v := range values {
+				ma.sumSquares.add(v * v)
+			}
+			ma.product.reset(values)
+		}
+	}
+	return true
+}
+
+// applyEviction evicts values per the instance's eviction policies.
+func (ma *movingStats) applyEviction(now time.Time) {
+	if len(ma.eviction) == 0 {
+		return
+	}
+	n := ma.evictCount(now)
+	if n > 0 {
+		ma.sorted = nil
+	}
+	for ; n > 0; n-- {
+		ma.evictOldest()
+	}
+}
 
-		// Increment value position
-		ma.valPos = (ma.valPos + 1) % ma.window
+func (ma *movingStats) ApplyOptions(opts Options) {
+	// Every stored value is evicted from the old aggregators, including those which have
+	// expired but haven't been evicted yet, since the aggregators have yet to see them go
+	stored := ma.values.Slice()
+	for _, agg := range ma.aggregators {
+		for _, v := range stored {
+			agg.OnEvict(v)
+		}
+	}
+	values, times := ma.live()
+	expired := stored[:len(stored)-len(values)]
 
-		// Did we just go back to 0, effectively meaning we filled all registers?
-		if !ma.slotsFilled && ma.valPos == 0 {
-			ma.slotsFilled = true
+	applied := newMovingStats(opts)
+	applied.now = ma.now
+	if applied.previous != nil && ma.previous != nil {
+		for _, v := range ma.previous.values.Slice() {
+			applied.previous.add(v)
+		}
+		for _, v := range expired {
+			applied.previous.add(v)
+		}
+	}
+	now := ma.now()
+	for i, val := range values {
+		t := now
+		if i < len(times) {
+			t = times[i]
 		}
+		applied.push(val, t)
+	}
+	// Keep the exponentially weighted moments of every value added, not just those kept
+	applied.ew = ma.ew
+	if applied.cusum != nil && ma.cusum != nil {
+		applied.cusum = ma.cusum
 	}
+	*ma = *applied
 }
 
 func (ma *movingStats) Window() int {
@@ -135,7 +886,56 @@ func (ma *movingStats) Window() int {
 }
 
 func (ma *movingStats) SlotsFilled() bool {
-	return ma.slotsFilled
+	return ma.Count() == ma.window
+}
+
+func (ma *movingStats) FillRatio() float64 {
+	if ma.window <= 0 {
+		return 0.0
+	}
+	values, times := ma.live()
+	retv := float64(len(values)) / float64(ma.window)
+	if ma.maxAge > 0 && len(times) > 0 {
+		covered := ma.now().Sub(times[0])
+		retv = max(retv, min(float64(covered)/float64(ma.maxAge), 1.0))
+	}
+	return retv
+}
+
+func (ma *movingStats) IngestRate() float64 {
+	_, times := ma.live()
+	if len(times) < 2 {
+		return 0.0
+	}
+	span := ma.now().Sub(times[0])
+	if span <= 0 {
+		return 0.0
+	}
+	return float64(len(times)-1) / span.Seconds()
+}
+
+func (ma *movingStats) OldestAge() time.Duration {
+	_, times := ma.live()
+	if len(times) == 0 {
+		return 0
+	}
+	return ma.now().Sub(times[0])
+}
+
+func (ma *movingStats) NewestAge() time.Duration {
+	_, times := ma.live()
+	if len(times) == 0 {
+		return 0
+	}
+	return ma.now().Sub(times[len(times)-1])
+}
+
+func (ma *movingStats) Span() time.Duration {
+	_, times := ma.live()
+	if len(times) == 0 {
+		return 0
+	}
+	return times[len(times)-1].Sub(times[0])
 }
 
 func (ma *movingStats) Values() stats.Float64Data {
@@ -143,31 +943,203 @@ func (ma *movingStats) Values() stats.Float64Data {
 	retv := make(stats.Float64Data, len(internal))
 	_ = copy(retv, internal)
 	return retv
+}
 
+func (ma *movingStats) ValuesWithTimes() []TimedValue {
+	values, times := ma.live()
+	if times == nil {
+		return nil
+	}
+	retv := make([]TimedValue, len(values))
+	for i, v := range values {
+		retv[i] = TimedValue{Time: times[i], Value: v}
+	}
+	return retv
+}
+
+func (ma *movingStats) SortedValues() stats.Float64Data {
+	// Evicting values at query time invalidates the cache, too
+	evicted := ma.values.Len() - len(ma.filledValues())
+	if ma.sorted == nil || ma.sortedEvicted != evicted {
+		ma.sorted = ma.Values()
+		ma.sortedEvicted = evicted
+		slices.Sort(ma.sorted)
+	}
+	retv := make(stats.Float64Data, len(ma.sorted))
+	_ = copy(retv, ma.sorted)
+	return retv
 }
 
 func (ma *movingStats) Count() int {
 	return len(ma.filledValues())
 }
 
+func (ma *movingStats) CountAbove(t float64) int {
+	n := 0
+	for _, v := range ma.filledValues() {
+		if v > t {
+			n++
+		}
+	}
+	return n
+}
+
+func (ma *movingStats) CountBelow(t float64) int {
+	n := 0
+	for _, v := range ma.filledValues() {
+		if v < t {
+			n++
+		}
+	}
+	return n
+}
+
 func (ma *movingStats) Avg() float64 {
-	retv, err := ma.filledValues().Mean()
+	values := ma.statValues()
+	if ma.priorWeight > 0 {
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return ma.blendPrior(sum, len(values))
+	}
+	retv, err := values.Mean()
+	if err != nil {
+		return 0.0
+	}
+	return retv
+}
+
+// blendPrior returns the average of n values with the given sum, blended with the prior
+// given by Options.PriorMean and PriorWeight until the window fills.
+// If there are no values and no prior, 0.0 is returned.
+func (ma *movingStats) blendPrior(sum float64, n int) float64 {
+	w := 0.0
+	if ma.priorWeight > 0 && n < ma.window {
+		w = ma.priorWeight * float64(ma.window-n) / float64(ma.window)
+	}
+	if w+float64(n) == 0 {
+		return 0.0
+	}
+	return (w*ma.priorMean + sum) / (w + float64(n))
+}
+
+func (ma *movingStats) SumLast(k int) float64 {
+	retv, err := ma.lastValues(k).Sum()
 	if err != nil {
 		return 0.0
 	}
 	return retv
 }
 
+func (ma *movingStats) AvgLast(k int) float64 {
+	retv, err := ma.lastValues(k).Mean()
+	if err != nil {
+		return 0.0
+	}
+	return retv
+}
+
+// lastValues returns the newest k values to calculate statistics from, per statValues.
+func (ma *movingStats) lastValues(k int) stats.Float64Data {
+	values := ma.statValues()
+	if k < 1 {
+		return nil
+	}
+	return values[max(len(values)-k, 0):]
+}
+
 func (ma *movingStats) Median() float64 {
-	retv, err := ma.filledValues().Median()
+	if ma.medianDecay > 0 && ma.medianDecay < 1 {
+		values := ma.statValues()
+		if len(values) == 0 {
+			return 0.0
+		}
+		return recencyWeightedMedian(values, ma.medianDecay)
+	}
+	if ma.interpolation != QuantileDefault {
+		retv, err := percentile(ma.statValues(), 50, ma.interpolation)
+		if err != nil {
+			return 0.0
+		}
+		return retv
+	}
+	retv, err := ma.statValues().Median()
+	if err != nil {
+		return 0.0
+	}
+	return retv
+}
+
+func (ma *movingStats) MedianAbsoluteDeviation() float64 {
+	retv, err := ma.statValues().MedianAbsoluteDeviationPopulation()
 	if err != nil {
 		return 0.0
 	}
 	return retv
 }
 
+func (ma *movingStats) Percentile(p float64) float64 {
+	retv, err := percentile(ma.statValues(), p, ma.interpolation)
+	if err != nil {
+		return 0.0
+	}
+	return retv
+}
+
+func (ma *movingStats) PercentileSummary(ps ...float64) map[float64]float64 {
+	if len(ps) == 0 {
+		ps = defaultSummaryPercentiles
+	}
+	retv := make(map[float64]float64, len(ps))
+	values := ma.statValues()
+	var sorted stats.Float64Data
+	if len(values) > 0 {
+		sorted = slices.Clone(values)
+		slices.Sort(sorted)
+	}
+	for _, p := range ps {
+		retv[p] = 0.0
+		if len(sorted) > 0 && p >= 0 && p <= 100 && (p > 0 || ma.interpolation != QuantileDefault) {
+			retv[p] = percentileSorted(sorted, p, ma.interpolation)
+		}
+	}
+	return retv
+}
+
+// defaultSummaryPercentiles are the percentiles returned by PercentileSummary if none are given.
+var defaultSummaryPercentiles = []float64{50, 90, 95, 99}
+
+func (ma *movingStats) IQR() float64 {
+	values := ma.statValues()
+	if len(values) == 0 {
+		return 0.0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return percentileSorted(sorted, 75, ma.interpolation) - percentileSorted(sorted, 25, ma.interpolation)
+}
+
+func (ma *movingStats) PercentileRank(x float64) float64 {
+	return ma.CDF(x) * 100
+}
+
+func (ma *movingStats) CDF(x float64) float64 {
+	values := ma.statValues()
+	if len(values) == 0 {
+		return 0.0
+	}
+	n := 0
+	for _, v := range values {
+		if v <= x {
+			n++
+		}
+	}
+	return float64(n) / float64(len(values))
+}
+
 func (ma *movingStats) Min() float64 {
-	retv, err := ma.filledValues().Min()
+	retv, err := ma.statValues().Min()
 	if err != nil {
 		return 0.0
 	}
@@ -175,13 +1147,239 @@ func (ma *movingStats) Min() float64 {
 }
 
 func (ma *movingStats) Max() float64 {
-	retv, err := ma.filledValues().Max()
+	retv, err := ma.statValues().Max()
 	if err != nil {
 		return 0.0
 	}
 	return retv
 }
 
+func (ma *movingStats) MinMax() (float64, float64) {
+	return minMax(ma.statValues())
+}
+
+// minMax returns the minimum and maximum of the given values in a single pass,
+// or 0.0 for both if there are no values.
+func minMax(values stats.Float64Data) (float64, float64) {
+	if len(values) == 0 {
+		return 0.0, 0.0
+	}
+	minV, maxV := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < minV {
+			minV = v
+		}
+		if v > maxV {
+			maxV = v
+		}
+	}
+	return minV, maxV
+}
+
+func (ma *movingStats) Range() float64 {
+	minV, maxV := minMax(ma.statValues())
+	return maxV - minV
+}
+
+func (ma *movingStats) RMS() float64 {
+	values := ma.statValues()
+	if len(values) == 0 {
+		return 0.0
+	}
+	var sumSq float64
+	for _, v := range values {
+		sumSq += v * v
+	}
+	return math.Sqrt(sumSq / float64(len(values)))
+}
+
+func (ma *movingStats) Variance() float64 {
+	retv, ok := ma.variance(ma.statValues())
+	if !ok {
+		return 0.0
+	}
+	return retv
+}
+
+func (ma *movingStats) StdDev() float64 {
+	retv, ok := ma.stdDev(ma.statValues())
+	if !ok {
+		return 0.0
+	}
+	return retv
+}
+
+// variance returns the variance of the given values per Options.VarianceEstimator,
+// and false if there are too few of them.
+func (ma *movingStats) variance(values stats.Float64Data) (float64, bool) {
+	if ma.varEstimator == VarianceSample {
+		if len(values) < 2 {
+			return 0.0, false
+		}
+		retv, err := values.SampleVariance()
+		return retv, err == nil
+	}
+	retv, err := values.PopulationVariance()
+	return retv, err == nil
+}
+
+// stdDev returns the standard deviation of the given values per Options.VarianceEstimator,
+// and false if there are too few of them.
+func (ma *movingStats) stdDev(values stats.Float64Data) (float64, bool) {
+	variance, ok := ma.variance(values)
+	return math.Sqrt(variance), ok
+}
+
+func (ma *movingStats) CV() float64 {
+	values := ma.statValues()
+	mean, err := values.Mean()
+	if err != nil || mean == 0 {
+		return 0.0
+	}
+	stdDev, ok := ma.stdDev(values)
+	if !ok {
+		return 0.0
+	}
+	return stdDev / math.Abs(mean)
+}
+
+func (ma *movingStats) ZScore() float64 {
+	current, ok := ma.newest()
+	if !ok {
+		return 0.0
+	}
+	return ma.ZScoreOf(current)
+}
+
+func (ma *movingStats) ZScoreOf(x float64) float64 {
+	values := ma.statValues()
+	mean, err := values.Mean()
+	if err != nil {
+		return 0.0
+	}
+	stdDev, ok := ma.stdDev(values)
+	if !ok || stdDev == 0 {
+		return 0.0
+	}
+	return (x - mean) / stdDev
+}
+
+func (ma *movingStats) Skewness() float64 {
+	m2, m3, _ := centralMoments(ma.statValues())
+	if m2 == 0 {
+		return 0.0
+	}
+	return m3 / math.Pow(m2, 1.5)
+}
+
+func (ma *movingStats) Kurtosis() float64 {
+	m2, _, m4 := centralMoments(ma.statValues())
+	if m2 == 0 {
+		return 0.0
+	}
+	return m4/(m2*m2) - 3
+}
+
+// centralMoments returns the second, third, and fourth central moments of the given values,
+// or zeros if there are none.
+func centralMoments(values stats.Float64Data) (m2, m3, m4 float64) {
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+	mean, _ := values.Mean()
+	for _, v := range values {
+		d := v - mean
+		d2 := d * d
+		m2 += d2
+		m3 += d2 * d
+		m4 += d2 * d2
+	}
+	n := float64(len(values))
+	return m2 / n, m3 / n, m4 / n
+}
+
+func (ma *movingStats) GeometricMean() float64 {
+	// stats.GeometricMean multiplies the values together, which overflows for long windows
+	// (and skips zeros), so average their logarithms instead
+	values := ma.statValues()
+	if len(values) == 0 {
+		return 0.0
+	}
+	var logSum float64
+	for _, v := range values {
+		if v <= 0 {
+			return 0.0
+		}
+		logSum += math.Log(v)
+	}
+	return math.Exp(logSum / float64(len(values)))
+}
+
+func (ma *movingStats) HarmonicMean() float64 {
+	retv, err := ma.statValues().HarmonicMean()
+	if err != nil {
+		return 0.0
+	}
+	return retv
+}
+
+func (ma *movingStats) TrimmedMean(fraction float64) float64 {
+	if fraction < 0 || fraction > 0.5 {
+		return 0.0
+	}
+	return trimmedMean(ma.statValues(), fraction)
+}
+
+func (ma *movingStats) Summary() Summary {
+	values := ma.filledValues()
+	if len(values) == 0 || len(values) < ma.minSamples {
+		return Summary{Count: len(values), Avg: ma.blendPrior(0, 0)}
+	}
+	avg, _ := values.Mean()
+	if ma.priorWeight > 0 {
+		sum, _ := values.Sum()
+		avg = ma.blendPrior(sum, len(values))
+	}
+	minV, maxV := minMax(values)
+	return Summary{
+		Count: len(values),
+		Avg:   avg,
+		Min:   minV,
+		Max:   maxV,
+	}
+}
+
+func (ma *movingStats) Frequencies() map[float64]int {
+	retv := make(map[float64]int)
+	for _, v := range ma.filledValues() {
+		if !math.IsNaN(v) {
+			retv[v]++
+		}
+	}
+	return retv
+}
+
+func (ma *movingStats) Mode() []float64 {
+	retv, err := stats.Mode(ma.statValues())
+	if err != nil || len(retv) == 0 {
+		return nil
+	}
+	// stats.Mode returns its input when there's a single value
+	return slices.Clone(retv)
+}
+
+func (ma *movingStats) Unit() Unit {
+	return ma.unit
+}
+
+func (ma *movingStats) FormatSummary() string {
+	return ma.Summary().Format(ma.unit)
+}
+
+func (ma *movingStats) String() string {
+	return ma.FormatSummary()
+}
+
 func (ma *movingStats) UnsafeDoStat(f func(stats.Float64Data) (float64, error)) (float64, error) {
 	return f(ma.filledValues())
 }
@@ -189,3 +1387,21 @@ func (ma *movingStats) UnsafeDoStat(f func(stats.Float64Data) (float64, error))
 func (ma *movingStats) UnsafeDo(f func(stats.Float64Data) error) error {
 	return f(ma.filledValues())
 }
+
+func (ma *movingStats) DoStatCtx(ctx context.Context, f func(context.Context, stats.Float64Data) (float64, error)) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0.0, err
+	}
+	return f(ctx, ma.filledValues())
+}
+
+func (ma *movingStats) DoCtx(ctx context.Context, f func(context.Context, stats.Float64Data) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f(ctx, ma.filledValues())
+}
+
+func (ma *movingStats) BorrowValues() (stats.Float64Data, func()) {
+	return ma.filledValues(), func() {}
+}
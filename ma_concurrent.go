@@ -1,21 +1,25 @@
 package movingaverage
 
 import (
+	"context"
+	"io"
 	"sync"
+	"time"
 
 	"github.com/montanaflynn/stats"
 )
 
 type concurrentMovingStats struct {
 	ma  MovingStats
-	mux sync.RWMutex
+	mux *sync.RWMutex // shared with views created by LastN
 }
 
 // NewConcurrent returns a new concurrency-safe MovingStats instance
 // with the given options.
 func NewConcurrent(opts Options) MovingStats {
 	return &concurrentMovingStats{
-		ma: New(opts),
+		ma:  newMovingStats(opts),
+		mux: new(sync.RWMutex),
 	}
 }
 
@@ -25,6 +29,33 @@ func (c *concurrentMovingStats) Add(values ...float64) {
 	c.ma.Add(values...)
 }
 
+func (c *concurrentMovingStats) AddAccepted(values ...float64) int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.ma.AddAccepted(values...)
+}
+
+// TryAdder is implemented by MovingStats instances which can add values without blocking.
+// Instances created by NewConcurrent implement TryAdder:
+//
+//	if !ms.(movingaverage.TryAdder).TryAdd(v) {
+//		dropped++
+//	}
+type TryAdder interface {
+	// TryAdd adds the given values if it can do so without waiting for a lock,
+	// and reports whether it did. If it returns false, none of the values were added.
+	TryAdd(values ...float64) bool
+}
+
+func (c *concurrentMovingStats) TryAdd(values ...float64) bool {
+	if !c.mux.TryLock() {
+		return false
+	}
+	defer c.mux.Unlock()
+	c.ma.Add(values...)
+	return true
+}
+
 func (c *concurrentMovingStats) Window() int {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
@@ -37,42 +68,382 @@ func (c *concurrentMovingStats) SlotsFilled() bool {
 	return c.ma.SlotsFilled()
 }
 
+func (c *concurrentMovingStats) FillRatio() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.FillRatio()
+}
+
+func (c *concurrentMovingStats) IngestRate() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.IngestRate()
+}
+
+func (c *concurrentMovingStats) OldestAge() time.Duration {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.OldestAge()
+}
+
+func (c *concurrentMovingStats) NewestAge() time.Duration {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.NewestAge()
+}
+
+func (c *concurrentMovingStats) Span() time.Duration {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Span()
+}
+
 func (c *concurrentMovingStats) Values() stats.Float64Data {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 	return c.ma.Values()
 }
 
+func (c *concurrentMovingStats) ValuesWithTimes() []TimedValue {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.ValuesWithTimes()
+}
+
+func (c *concurrentMovingStats) ValuesDownsampled(maxPoints int) stats.Float64Data {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.ValuesDownsampled(maxPoints)
+}
+
+// SortedValues takes the write lock, since it may populate the
+// underlying instance's sorted values cache.
+func (c *concurrentMovingStats) SortedValues() stats.Float64Data {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.ma.SortedValues()
+}
+
 func (c *concurrentMovingStats) Count() int {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 	return c.ma.Count()
 }
 
+func (c *concurrentMovingStats) CountAbove(t float64) int {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.CountAbove(t)
+}
+
+func (c *concurrentMovingStats) CountBelow(t float64) int {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.CountBelow(t)
+}
+
 func (c *concurrentMovingStats) Avg() float64 {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 	return c.ma.Avg()
 }
 
+func (c *concurrentMovingStats) Sum() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Sum()
+}
+
+func (c *concurrentMovingStats) SumOfSquares() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.SumOfSquares()
+}
+
+func (c *concurrentMovingStats) Product() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Product()
+}
+
+func (c *concurrentMovingStats) SumLast(k int) float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.SumLast(k)
+}
+
+func (c *concurrentMovingStats) AvgLast(k int) float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.AvgLast(k)
+}
+
+func (c *concurrentMovingStats) TrendPct() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.TrendPct()
+}
+
 func (c *concurrentMovingStats) Median() float64 {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 	return c.ma.Median()
 }
 
+func (c *concurrentMovingStats) MedianAbsoluteDeviation() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.MedianAbsoluteDeviation()
+}
+
+func (c *concurrentMovingStats) Percentile(p float64) float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Percentile(p)
+}
+
+func (c *concurrentMovingStats) PercentileSummary(ps ...float64) map[float64]float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.PercentileSummary(ps...)
+}
+
+func (c *concurrentMovingStats) IQR() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.IQR()
+}
+
+func (c *concurrentMovingStats) PercentileRank(x float64) float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.PercentileRank(x)
+}
+
+func (c *concurrentMovingStats) CDF(x float64) float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.CDF(x)
+}
+
+func (c *concurrentMovingStats) Quantiles() []float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Quantiles()
+}
+
 func (c *concurrentMovingStats) Min() float64 {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 	return c.ma.Min()
 }
 
+func (c *concurrentMovingStats) Value() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Value()
+}
+
+func (c *concurrentMovingStats) EWVariance() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.EWVariance()
+}
+
+func (c *concurrentMovingStats) EWStdDev() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.EWStdDev()
+}
+
 func (c *concurrentMovingStats) Max() float64 {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 	return c.ma.Max()
 }
 
+func (c *concurrentMovingStats) MinMax() (float64, float64) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.MinMax()
+}
+
+func (c *concurrentMovingStats) Range() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Range()
+}
+
+func (c *concurrentMovingStats) RMS() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.RMS()
+}
+
+func (c *concurrentMovingStats) Variance() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Variance()
+}
+
+func (c *concurrentMovingStats) StdDev() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.StdDev()
+}
+
+func (c *concurrentMovingStats) CV() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.CV()
+}
+
+func (c *concurrentMovingStats) HealthScore() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.HealthScore()
+}
+
+func (c *concurrentMovingStats) ZScore() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.ZScore()
+}
+
+func (c *concurrentMovingStats) ZScoreOf(x float64) float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.ZScoreOf(x)
+}
+
+func (c *concurrentMovingStats) Skewness() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Skewness()
+}
+
+func (c *concurrentMovingStats) Kurtosis() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Kurtosis()
+}
+
+func (c *concurrentMovingStats) GeometricMean() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.GeometricMean()
+}
+
+func (c *concurrentMovingStats) HarmonicMean() float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.HarmonicMean()
+}
+
+func (c *concurrentMovingStats) TrimmedMean(fraction float64) float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.TrimmedMean(fraction)
+}
+
+func (c *concurrentMovingStats) Summary() Summary {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Summary()
+}
+
+func (c *concurrentMovingStats) Unit() Unit {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Unit()
+}
+
+func (c *concurrentMovingStats) FormatSummary() string {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.FormatSummary()
+}
+
+func (c *concurrentMovingStats) String() string {
+	return c.FormatSummary()
+}
+
+func (c *concurrentMovingStats) TopK(k int) stats.Float64Data {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.TopK(k)
+}
+
+func (c *concurrentMovingStats) BottomK(k int) stats.Float64Data {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.BottomK(k)
+}
+
+func (c *concurrentMovingStats) Frequencies() map[float64]int {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Frequencies()
+}
+
+func (c *concurrentMovingStats) Mode() []float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Mode()
+}
+
+func (c *concurrentMovingStats) Periodicity() (int, float64) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Periodicity()
+}
+
+func (c *concurrentMovingStats) Compute(kinds ...StatKind) map[StatKind]float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Compute(kinds...)
+}
+
+func (c *concurrentMovingStats) LastN(k int) ReadOnlyView {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return readOnlyView{&concurrentMovingStats{ma: c.ma.(*movingStats).lastN(k), mux: c.mux}}
+}
+
+func (c *concurrentMovingStats) Snapshot() Snapshot {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.Snapshot()
+}
+
+func (c *concurrentMovingStats) Restore(s Snapshot) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.ma.Restore(s)
+}
+
+func (c *concurrentMovingStats) ApplyOptions(opts Options) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.ma.ApplyOptions(opts)
+}
+
+func (c *concurrentMovingStats) WriteTo(w io.Writer) (int64, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.WriteTo(w)
+}
+
+func (c *concurrentMovingStats) ReadFrom(r io.Reader) (int64, error) {
+	// Read without holding the lock, which is only needed to restore the snapshot
+	s, n, err := readBinarySnapshot(r)
+	if err != nil {
+		return n, err
+	}
+	c.Restore(s)
+	return n, nil
+}
+
 func (c *concurrentMovingStats) UnsafeDoStat(f func(stats.Float64Data) (float64, error)) (float64, error) {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
@@ -84,3 +455,33 @@ func (c *concurrentMovingStats) UnsafeDo(f func(stats.Float64Data) error) error
 	defer c.mux.RUnlock()
 	return c.ma.UnsafeDo(f)
 }
+
+func (c *concurrentMovingStats) DoStatCtx(ctx context.Context, f func(context.Context, stats.Float64Data) (float64, error)) (float64, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.DoStatCtx(ctx, f)
+}
+
+func (c *concurrentMovingStats) DoCtx(ctx context.Context, f func(context.Context, stats.Float64Data) error) error {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.ma.DoCtx(ctx, f)
+}
+
+func (c *concurrentMovingStats) DoLocked(f func(ReadOnlyView)) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	c.ma.DoLocked(f)
+}
+
+func (c *concurrentMovingStats) BorrowValues() (stats.Float64Data, func()) {
+	c.mux.RLock()
+	values, release := c.ma.BorrowValues()
+	var once sync.Once
+	return values, func() {
+		once.Do(func() {
+			release()
+			c.mux.RUnlock()
+		})
+	}
+}
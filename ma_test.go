@@ -1,10 +1,14 @@
 package movingaverage
 
 import (
+	"context"
+	"errors"
+	"maps"
 	"math"
 	"slices"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/montanaflynn/stats"
 )
@@ -76,6 +80,28 @@ func TestNaNIgnore(t *testing.T) {
 	}
 }
 
+func TestNaNPolicy(t *testing.T) {
+	nan := math.NaN()
+	for _, tc := range []struct {
+		opts Options
+		want stats.Float64Data
+	}{
+		{Options{NaNPolicy: NaNIgnore}, stats.Float64Data{2, 4}},
+		{Options{NaNPolicy: NaNReplaceWithLast}, stats.Float64Data{2, 2, 4, 4}},
+		{Options{NaNPolicy: NaNReplaceWithMean}, stats.Float64Data{2, 2, 4, 8.0 / 3}},
+		{Options{NaNPolicy: NaNReplaceWithConstant, NaNReplacement: -1}, stats.Float64Data{-1, 2, -1, 4, -1}},
+		{Options{NaNPolicy: NaNReplaceWithConstant, IgnoreNanValues: true}, stats.Float64Data{2, 4}},
+	} {
+		tc.opts.Window = 5
+		a := New(tc.opts)
+		// a leading NaN can't be replaced with a value from the empty window
+		a.Add(nan, 2, nan, 4, nan)
+		if !slices.Equal(a.Values(), tc.want) {
+			t.Error(tc.opts, a.Values())
+		}
+	}
+}
+
 func TestInf(t *testing.T) {
 	a := New(Options{Window: 5})
 	a.Add(1)
@@ -97,6 +123,87 @@ func TestInfIgnore(t *testing.T) {
 	}
 }
 
+func TestAddAccepted(t *testing.T) {
+	for _, a := range []MovingStats{
+		New(Options{Window: 2, IgnoreNanValues: true, IgnoreInfValues: true}),
+		NewConcurrent(Options{Window: 2, IgnoreNanValues: true, IgnoreInfValues: true}),
+	} {
+		if n := a.AddAccepted(1, math.NaN(), 2, math.Inf(-1), 3); n != 3 {
+			t.Error(n)
+		}
+		if n := a.AddAccepted(); n != 0 {
+			t.Error(n)
+		}
+		if !slices.Equal(a.Values(), stats.Float64Data{2, 3}) {
+			t.Error(a.Values())
+		}
+	}
+}
+
+func TestInfPolicy(t *testing.T) {
+	inf := math.Inf(1)
+	for _, tc := range []struct {
+		opts Options
+		want stats.Float64Data
+	}{
+		{Options{InfPolicy: InfIgnore}, stats.Float64Data{2, 4}},
+		{Options{InfPolicy: InfClampToBounds, InfClampMin: -10, InfClampMax: 10}, stats.Float64Data{10, 2, -10, 4, 10}},
+		{Options{InfPolicy: InfClampToWindow}, stats.Float64Data{2, 2, 4, 4}},
+		{Options{InfPolicy: InfClampToBounds, IgnoreInfValues: true}, stats.Float64Data{2, 4}},
+	} {
+		tc.opts.Window = 5
+		a := New(tc.opts)
+		// a leading Inf can't be clamped to the empty window
+		a.Add(inf, 2, -inf, 4, inf)
+		if !slices.Equal(a.Values(), tc.want) {
+			t.Error(tc.opts, a.Values())
+		}
+	}
+}
+
+func TestIgnoreNegative(t *testing.T) {
+	a := New(Options{Window: 5, IgnoreNegative: true})
+	a.Add(-1, 0, 1, math.Inf(-1))
+	if !slices.Equal(a.Values(), stats.Float64Data{0, 1}) {
+		t.Error(a.Values())
+	}
+
+	b := New(Options{Window: 5, IgnoreNonPositive: true, InfPolicy: InfClampToBounds, InfClampMin: -1})
+	if n := b.AddAccepted(-1, 0, 1, math.Inf(-1), 2); n != 2 {
+		t.Error(n)
+	}
+	if !slices.Equal(b.Values(), stats.Float64Data{1, 2}) {
+		t.Error(b.Values())
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	for _, tc := range []struct {
+		step float64
+		in   float64
+		want float64
+	}{
+		{0.01, 0.299999, 0.3},
+		{0.01, 1.005001, 1.01},
+		{0.1, 0.1 + 0.2, 0.3},
+		{0.25, 1.1, 1},
+		{5, 12.6, 15},
+		{5, -12.4, -10},
+	} {
+		a := New(Options{Window: 1, RoundTo: tc.step})
+		a.Add(tc.in)
+		if a.Avg() != tc.want {
+			t.Error(tc.step, tc.in, a.Avg())
+		}
+	}
+
+	// values rounded to zero are subject to IgnoreNonPositive
+	a := New(Options{Window: 2, RoundTo: 1, IgnoreNonPositive: true})
+	if n := a.AddAccepted(0.4, 0.6); n != 1 || a.Avg() != 1 {
+		t.Error(n, a.Avg())
+	}
+}
+
 func TestCount(t *testing.T) {
 	a := New(Options{Window: 5})
 	if a.Count() != 0 {
@@ -116,6 +223,23 @@ func TestCount(t *testing.T) {
 	}
 }
 
+func TestCountAboveBelow(t *testing.T) {
+	a := NewConcurrent(Options{Window: 5})
+	if a.CountAbove(0) != 0 || a.CountBelow(0) != 0 {
+		t.Error(a.CountAbove(0), a.CountBelow(0))
+	}
+	a.Add(9, 1, 2, 3, 4, 5) // 9 is evicted
+	if a.CountAbove(3) != 2 || a.CountBelow(3) != 2 {
+		t.Error(a.CountAbove(3), a.CountBelow(3))
+	}
+	if a.CountAbove(5) != 0 || a.CountBelow(6) != 5 {
+		t.Error(a.CountAbove(5), a.CountBelow(6))
+	}
+	if a.LastN(2).CountAbove(3) != 2 {
+		t.Error(a.LastN(2).CountAbove(3))
+	}
+}
+
 func TestConcurrent(t *testing.T) {
 	// this test needs to be run with -race flag
 	a := NewConcurrent(Options{Window: 5})
@@ -171,6 +295,73 @@ func TestMedian(t *testing.T) {
 	}
 }
 
+func TestMedianAbsoluteDeviation(t *testing.T) {
+	a := NewConcurrent(Options{Window: 7})
+	if a.MedianAbsoluteDeviation() != 0 {
+		t.Error(a.MedianAbsoluteDeviation())
+	}
+	// median 2, absolute deviations 1, 1, 0, 0, 2, 4, 7
+	a.Add(1, 1, 2, 2, 4, 6, 9)
+	if a.MedianAbsoluteDeviation() != 1 {
+		t.Error(a.MedianAbsoluteDeviation())
+	}
+	a.Add(1e9) // evicts a 1; an outlier barely moves the MAD
+	if a.MedianAbsoluteDeviation() != 2 {
+		t.Error(a.MedianAbsoluteDeviation())
+	}
+}
+
+func TestIQR(t *testing.T) {
+	a := NewConcurrent(Options{Window: 8})
+	if a.IQR() != 0 {
+		t.Error(a.IQR())
+	}
+	a.Add(8, 1, 7, 2, 6, 3, 5, 4)
+	if a.IQR() != 4 {
+		t.Error(a.IQR())
+	}
+
+	b := New(Options{Window: 8, QuantileInterpolation: QuantileLinear})
+	b.Add(1, 2, 3, 4, 5, 6, 7, 8)
+	if b.IQR() != 3.5 {
+		t.Error(b.IQR())
+	}
+}
+
+func TestPercentileRank(t *testing.T) {
+	a := NewConcurrent(Options{Window: 4})
+	if a.PercentileRank(1) != 0 || a.CDF(1) != 0 {
+		t.Error(a.PercentileRank(1), a.CDF(1))
+	}
+	a.Add(100, 200, 300, 400, 500) // 100 is evicted
+	for x, want := range map[float64]float64{0: 0, 100: 0, 200: 25, 250: 25, 400: 75, 500: 100, 1000: 100} {
+		if a.PercentileRank(x) != want || a.CDF(x) != want/100 {
+			t.Error(x, a.PercentileRank(x), a.CDF(x))
+		}
+	}
+	if a.LastN(2).PercentileRank(400) != 50 {
+		t.Error(a.LastN(2).PercentileRank(400))
+	}
+}
+
+func TestMedianDecay(t *testing.T) {
+	a := NewConcurrent(Options{Window: 5, MedianDecay: 0.5})
+	if a.Median() != 0 {
+		t.Error(a.Median())
+	}
+	// weights 1/16, 1/8, 1/4, 1/2, 1: the newest two values outweigh the rest
+	a.Add(1, 2, 3, 100, 100)
+	if a.Median() != 100 || a.Compute(StatMedian)[StatMedian] != 100 {
+		t.Error(a.Median(), a.Compute(StatMedian))
+	}
+
+	b := New(Options{Window: 5, MedianDecay: 0.9})
+	b.Add(1, 2, 3, 100, 100)
+	if b.Median() != 3 {
+		t.Error(b.Median())
+	}
+}
+
 func TestWindow(t *testing.T) {
 	a := New(Options{Window: 5})
 	if a.Window() != 5 {
@@ -211,3 +402,978 @@ func TestUnsafeDo(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestDoCtx(t *testing.T) {
+	a := NewConcurrent(Options{Window: 3})
+	a.Add(1, 2, 3, 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v, err := a.DoStatCtx(ctx, func(fctx context.Context, data stats.Float64Data) (float64, error) {
+		if fctx != ctx {
+			t.Error("expected the context to be passed to f")
+		}
+		return data.Mean()
+	})
+	if v != 3 || err != nil {
+		t.Error(v, err)
+	}
+	err = a.DoCtx(ctx, func(_ context.Context, data stats.Float64Data) error {
+		if !slices.Equal(data, stats.Float64Data{2, 3, 4}) {
+			t.Error(data)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	cancel()
+	ran := false
+	v, err = a.DoStatCtx(ctx, func(context.Context, stats.Float64Data) (float64, error) {
+		ran = true
+		return 1, nil
+	})
+	if ran || v != 0 || !errors.Is(err, context.Canceled) {
+		t.Error(ran, v, err)
+	}
+	err = a.DoCtx(ctx, func(context.Context, stats.Float64Data) error {
+		ran = true
+		return nil
+	})
+	if ran || !errors.Is(err, context.Canceled) {
+		t.Error(ran, err)
+	}
+}
+
+func TestSortedValues(t *testing.T) {
+	a := New(Options{Window: 3})
+	if len(a.SortedValues()) != 0 {
+		t.Error(a.SortedValues())
+	}
+
+	a.Add(3, 1, 2)
+	if !slices.Equal(a.SortedValues(), stats.Float64Data{1, 2, 3}) {
+		t.Error(a.SortedValues())
+	}
+
+	// modifying the returned slice must not affect the cache
+	sorted := a.SortedValues()
+	sorted[0] = 100
+	if !slices.Equal(a.SortedValues(), stats.Float64Data{1, 2, 3}) {
+		t.Error(a.SortedValues())
+	}
+
+	// the cache is invalidated on Add
+	a.Add(0)
+	if !slices.Equal(a.SortedValues(), stats.Float64Data{0, 1, 2}) {
+		t.Error(a.SortedValues())
+	}
+}
+
+func TestBorrowValues(t *testing.T) {
+	a := New(Options{Window: 3})
+	a.Add(1, 2, 3)
+
+	values, release := a.BorrowValues()
+	if !slices.Equal(values, stats.Float64Data{1, 2, 3}) {
+		t.Error(values)
+	}
+	release()
+}
+
+func TestBorrowValuesConcurrent(t *testing.T) {
+	a := NewConcurrent(Options{Window: 3})
+	a.Add(1, 2, 3)
+
+	values, release := a.BorrowValues()
+	if !slices.Equal(values, stats.Float64Data{1, 2, 3}) {
+		t.Error(values)
+	}
+	// calling release more than once must be safe
+	release()
+	release()
+
+	// the lock must have been released
+	a.Add(4)
+	if a.Count() != 3 {
+		t.Error(a.Count())
+	}
+}
+
+func TestTryAdd(t *testing.T) {
+	a := NewConcurrent(Options{Window: 3})
+	ta, ok := a.(TryAdder)
+	if !ok {
+		t.Fatal("concurrent instance does not implement TryAdder")
+	}
+	if !ta.TryAdd(1, 2) {
+		t.Error("TryAdd failed on an uncontended instance")
+	}
+
+	// a borrowed instance holds the read lock
+	_, release := a.BorrowValues()
+	if ta.TryAdd(3) {
+		t.Error("TryAdd succeeded while the lock was held")
+	}
+	release()
+
+	if !slices.Equal(a.Values(), stats.Float64Data{1, 2}) {
+		t.Error(a.Values())
+	}
+}
+
+func TestDoLocked(t *testing.T) {
+	for _, a := range []MovingStats{New(Options{Window: 3}), NewConcurrent(Options{Window: 3})} {
+		a.Add(1, 2, 3, 4)
+		called := false
+		a.DoLocked(func(view ReadOnlyView) {
+			called = true
+			if view.Avg() != 3 || view.Min() != 2 || !slices.Equal(view.Values(), stats.Float64Data{2, 3, 4}) {
+				t.Error(view.Avg(), view.Min(), view.Values())
+			}
+		})
+		if !called {
+			t.Error("DoLocked did not call f")
+		}
+	}
+
+	// the read lock must be held while f runs
+	a := NewConcurrent(Options{Window: 3})
+	a.DoLocked(func(ReadOnlyView) {
+		if a.(TryAdder).TryAdd(1) {
+			t.Error("TryAdd succeeded while the lock was held")
+		}
+	})
+	if !a.(TryAdder).TryAdd(1) {
+		t.Error("the lock was not released")
+	}
+}
+
+func TestLastN(t *testing.T) {
+	for _, a := range []MovingStats{New(Options{Window: 10}), NewConcurrent(Options{Window: 10}), New(Options{Window: 10, DetectRaces: true})} {
+		a.Add(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+		v := a.LastN(3)
+		if v.Window() != 3 || !v.SlotsFilled() || v.Avg() != 9 || !slices.Equal(v.Values(), stats.Float64Data{8, 9, 10}) {
+			t.Error(v.Window(), v.SlotsFilled(), v.Avg(), v.Values())
+		}
+
+		// the view shares the instance's storage
+		a.Add(11)
+		if v.Min() != 9 || v.Max() != 11 || a.Count() != 10 {
+			t.Error(v.Min(), v.Max(), a.Count())
+		}
+		if _, ok := v.(MovingStats); ok {
+			t.Error("view should not be a MovingStats")
+		}
+
+		if a.LastN(100).Window() != 10 || a.LastN(-1).Count() != 0 {
+			t.Error(a.LastN(100).Window(), a.LastN(-1).Count())
+		}
+	}
+
+	now := time.Now()
+	a := newMovingStats(Options{Window: 10, MaxAge: time.Minute})
+	a.now = func() time.Time { return now }
+	a.Add(1, 2, 3)
+	now = now.Add(time.Hour)
+	a.Add(4)
+	if v := a.LastN(2); !slices.Equal(v.Values(), stats.Float64Data{4}) {
+		t.Error(v.Values())
+	}
+}
+
+func TestLastNConcurrent(t *testing.T) {
+	a := NewConcurrent(Options{Window: 100})
+	v := a.LastN(10)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			a.Add(float64(i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			if v.Count() > 10 {
+				t.Error(v.Count())
+			}
+		}
+	}()
+	wg.Wait()
+	if v.Avg() != 994.5 {
+		t.Error(v.Avg())
+	}
+}
+
+func TestApplyOptions(t *testing.T) {
+	agg := &sumAggregator{}
+	a := NewConcurrent(Options{Window: 5, Aggregators: []Aggregator{agg}})
+	a.Add(-1, 2, 3, 4, 5)
+	v := a.LastN(2)
+
+	// shrink the window and start ignoring negative values
+	a.ApplyOptions(Options{Window: 4, IgnoreNegative: true, Aggregators: []Aggregator{agg}})
+	if a.Window() != 4 || !slices.Equal(a.Values(), stats.Float64Data{2, 3, 4, 5}) {
+		t.Error(a.Window(), a.Values())
+	}
+	if agg.Value() != 14 {
+		t.Error(agg.Value())
+	}
+	a.Add(-6, 6)
+	if !slices.Equal(a.Values(), stats.Float64Data{3, 4, 5, 6}) || agg.Value() != 18 {
+		t.Error(a.Values(), agg.Value())
+	}
+	// views still reflect the instance's values
+	if !slices.Equal(v.Values(), stats.Float64Data{5, 6}) {
+		t.Error(v.Values())
+	}
+
+	// grow the window and add a max age
+	now := time.Now()
+	b := newMovingStats(Options{Window: 2})
+	b.now = func() time.Time { return now }
+	b.Add(1, 2)
+	b.ApplyOptions(Options{Window: 3, MaxAge: time.Minute})
+	b.Add(3)
+	if !slices.Equal(b.Values(), stats.Float64Data{1, 2, 3}) {
+		t.Error(b.Values())
+	}
+	now = now.Add(2 * time.Minute)
+	if b.Count() != 0 {
+		t.Error(b.Count())
+	}
+}
+
+// TestApplyOptionsExpired checks that values which have expired, but haven't been evicted
+// yet, are evicted from the old aggregators when new options are applied.
+func TestApplyOptionsExpired(t *testing.T) {
+	agg := &sumAggregator{}
+	now := time.Now()
+	a := newMovingStats(Options{Window: 5, MaxAge: time.Minute, Aggregators: []Aggregator{agg}})
+	a.now = func() time.Time { return now }
+	a.Add(1, 2)
+	now = now.Add(30 * time.Second)
+	a.Add(3)
+	now = now.Add(45 * time.Second)
+	if agg.Value() != 6 || a.Count() != 1 {
+		t.Error(agg.Value(), a.Count())
+	}
+
+	newAgg := &sumAggregator{}
+	a.ApplyOptions(Options{Window: 5, MaxAge: time.Minute, Aggregators: []Aggregator{newAgg}})
+	if agg.Value() != 0 || newAgg.Value() != 3 || a.Count() != 1 {
+		t.Error(agg.Value(), newAgg.Value(), a.Count())
+	}
+}
+
+func TestFrequencies(t *testing.T) {
+	a := NewConcurrent(Options{Window: 5})
+	if len(a.Frequencies()) != 0 {
+		t.Error(a.Frequencies())
+	}
+	a.Add(500, 200, 200, math.NaN(), 404, 200)
+	if !maps.Equal(a.Frequencies(), map[float64]int{200: 3, 404: 1}) {
+		t.Error(a.Frequencies())
+	}
+
+	b := New(Options{Window: 5, RoundTo: 100})
+	b.Add(120, 180, 240, 260)
+	if !maps.Equal(b.Frequencies(), map[float64]int{100: 1, 200: 2, 300: 1}) {
+		t.Error(b.Frequencies())
+	}
+}
+
+func TestMode(t *testing.T) {
+	a := NewConcurrent(Options{Window: 5})
+	if a.Mode() != nil {
+		t.Error(a.Mode())
+	}
+	a.Add(7)
+	if !slices.Equal(a.Mode(), []float64{7}) {
+		t.Error(a.Mode())
+	}
+	a.Add(3, 7, 3, 1)
+	if !slices.Equal(a.Mode(), []float64{3, 7}) {
+		t.Error(a.Mode())
+	}
+	a.Add(3) // evicts the first 7
+	if !slices.Equal(a.Mode(), []float64{3}) {
+		t.Error(a.Mode())
+	}
+
+	b := New(Options{Window: 4})
+	b.Add(1, 2, 3, 4)
+	if b.Mode() != nil {
+		t.Error(b.Mode())
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	a := New(Options{Window: 3})
+	if minV, maxV := a.MinMax(); minV != 0 || maxV != 0 {
+		t.Error(minV, maxV)
+	}
+
+	a.Add(2, 1, 3, 5)
+	if minV, maxV := a.MinMax(); minV != 1 || maxV != 5 {
+		t.Error(minV, maxV)
+	}
+	if minV, maxV := a.MinMax(); minV != a.Min() || maxV != a.Max() {
+		t.Error(minV, maxV)
+	}
+}
+
+type sumAggregator struct {
+	sum float64
+}
+
+func (s *sumAggregator) OnAdd(value float64)   { s.sum += value }
+func (s *sumAggregator) OnEvict(value float64) { s.sum -= value }
+func (s *sumAggregator) Value() float64        { return s.sum }
+
+func TestAggregators(t *testing.T) {
+	agg := &sumAggregator{}
+	a := New(Options{Window: 3, IgnoreNanValues: true, Aggregators: []Aggregator{agg}})
+
+	a.Add(1, 2)
+	if agg.Value() != 3 {
+		t.Error(agg.Value())
+	}
+
+	// ignored values must not reach the aggregator
+	a.Add(math.NaN())
+	if agg.Value() != 3 {
+		t.Error(agg.Value())
+	}
+
+	a.Add(3, 4, 5)
+	if agg.Value() != 12 {
+		t.Error(agg.Value())
+	}
+}
+
+func TestValuesOrder(t *testing.T) {
+	a := New(Options{Window: 3})
+	a.Add(1, 2)
+	if !slices.Equal(a.Values(), stats.Float64Data{1, 2}) {
+		t.Error(a.Values())
+	}
+	a.Add(3, 4, 5)
+	if !slices.Equal(a.Values(), stats.Float64Data{3, 4, 5}) {
+		t.Error(a.Values())
+	}
+}
+
+func TestCombine(t *testing.T) {
+	a := New(Options{Window: 3})
+	b := NewConcurrent(Options{Window: 5})
+	a.Add(1, 2, 3, 4)
+	b.Add(10, 20)
+
+	sum := Combine(a, b, func(x, y float64) float64 { return x + y })
+	if sum.Window() != 3 {
+		t.Error(sum.Window())
+	}
+	// newest values are aligned: 3+10, 4+20
+	if !slices.Equal(sum.Values(), stats.Float64Data{13, 24}) {
+		t.Error(sum.Values())
+	}
+	if sum.Avg() != 18.5 {
+		t.Error(sum.Avg())
+	}
+}
+
+func TestCrossCorrelation(t *testing.T) {
+	a := New(Options{Window: 20})
+	b := New(Options{Window: 20})
+	signal := []float64{1, 5, 2, 8, 3, 9, 4, 7, 1, 6, 2, 8, 5, 3, 9, 1}
+	// b follows a by 3 samples
+	for i := range signal {
+		a.Add(signal[i])
+		if i >= 3 {
+			b.Add(2*signal[i-3] + 10)
+		} else {
+			b.Add(0)
+		}
+	}
+
+	lag, r := CrossCorrelation(a, b, 5)
+	if lag != 3 || r < 0.4 || r > 1 {
+		t.Error(lag, r)
+	}
+	lag2, r2 := CrossCorrelation(b, a, 5)
+	if lag2 != -3 || r2 != r {
+		t.Error(lag2, r2)
+	}
+
+	// a constant series has no correlation
+	c := New(Options{Window: 20})
+	c.Add(1, 1, 1, 1)
+	if lag, r := CrossCorrelation(a, c, 2); lag != 0 || r != 0 {
+		t.Error(lag, r)
+	}
+	// maxLag larger than the windows
+	if lag, _ := CrossCorrelation(a, b, 100); lag != 3 {
+		t.Error(lag)
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	now := time.Now()
+	a := newMovingStats(Options{Window: 3, MaxAge: time.Minute})
+	a.now = func() time.Time { return now }
+
+	a.Add(1, 2)
+	now = now.Add(30 * time.Second)
+	a.Add(3)
+	if !slices.Equal(a.Values(), stats.Float64Data{1, 2, 3}) {
+		t.Error(a.Values())
+	}
+	if !a.SlotsFilled() {
+		t.Error("should be full")
+	}
+
+	// the first two values expire, even though nothing has been added since
+	now = now.Add(45 * time.Second)
+	if !slices.Equal(a.Values(), stats.Float64Data{3}) {
+		t.Error(a.Values())
+	}
+	if a.SlotsFilled() {
+		t.Error("should not be full")
+	}
+	if a.Avg() != 3 {
+		t.Error(a.Avg())
+	}
+	if !slices.Equal(a.SortedValues(), stats.Float64Data{3}) {
+		t.Error(a.SortedValues())
+	}
+
+	// the window still caps the number of values
+	a.Add(4, 5, 6)
+	if !slices.Equal(a.Values(), stats.Float64Data{4, 5, 6}) {
+		t.Error(a.Values())
+	}
+
+	now = now.Add(time.Hour)
+	if a.Count() != 0 || a.Avg() != 0 {
+		t.Error(a.Count(), a.Avg())
+	}
+}
+
+func TestFillRatio(t *testing.T) {
+	a := New(Options{Window: 4})
+	if a.FillRatio() != 0 {
+		t.Error(a.FillRatio())
+	}
+	a.Add(1)
+	if a.FillRatio() != 0.25 {
+		t.Error(a.FillRatio())
+	}
+	a.Add(2, 3, 4, 5)
+	if a.FillRatio() != 1 {
+		t.Error(a.FillRatio())
+	}
+
+	now := time.Now()
+	b := newMovingStats(Options{Window: 10, MaxAge: time.Minute})
+	b.now = func() time.Time { return now }
+	b.Add(1)
+	if b.FillRatio() != 0.1 {
+		t.Error(b.FillRatio())
+	}
+	now = now.Add(30 * time.Second)
+	if b.FillRatio() != 0.5 {
+		t.Error(b.FillRatio())
+	}
+	now = now.Add(29 * time.Second)
+	b.Add(2)
+	if r := b.FillRatio(); r <= 0.98 || r >= 1 {
+		t.Error(r)
+	}
+}
+
+func TestIngestRate(t *testing.T) {
+	now := time.Now()
+	a := newMovingStats(Options{Window: 5, TrackIngestRate: true})
+	a.now = func() time.Time { return now }
+	a.Add(1)
+	if a.IngestRate() != 0 {
+		t.Error(a.IngestRate())
+	}
+	for i := 0; i < 10; i++ {
+		now = now.Add(500 * time.Millisecond)
+		a.Add(1)
+	}
+	if a.IngestRate() != 2 {
+		t.Error(a.IngestRate())
+	}
+
+	// the rate falls when values stop arriving
+	now = now.Add(2 * time.Second)
+	if a.IngestRate() != 1 {
+		t.Error(a.IngestRate())
+	}
+
+	if b := New(Options{Window: 5}); b.IngestRate() != 0 {
+		t.Error(b.IngestRate())
+	}
+}
+
+func TestAges(t *testing.T) {
+	now := time.Now()
+	a := newMovingStats(Options{Window: 3, TrackIngestRate: true})
+	a.now = func() time.Time { return now }
+	if a.OldestAge() != 0 || a.NewestAge() != 0 || a.Span() != 0 {
+		t.Error(a.OldestAge(), a.NewestAge(), a.Span())
+	}
+
+	for i := 0; i < 4; i++ {
+		a.Add(1)
+		now = now.Add(time.Second)
+	}
+	if a.OldestAge() != 3*time.Second || a.NewestAge() != time.Second || a.Span() != 2*time.Second {
+		t.Error(a.OldestAge(), a.NewestAge(), a.Span())
+	}
+
+	if b := NewConcurrent(Options{Window: 3}); b.OldestAge() != 0 {
+		t.Error(b.OldestAge())
+	}
+}
+
+func TestValuesWithTimes(t *testing.T) {
+	start := time.Now()
+	now := start
+	a := newMovingStats(Options{Window: 2, MaxAge: time.Minute})
+	a.now = func() time.Time { return now }
+	for i := 1; i <= 3; i++ {
+		a.Add(float64(i))
+		now = now.Add(time.Second)
+	}
+	want := []TimedValue{{start.Add(time.Second), 2}, {start.Add(2 * time.Second), 3}}
+	if !slices.Equal(a.ValuesWithTimes(), want) {
+		t.Error(a.ValuesWithTimes())
+	}
+
+	// expired values are excluded
+	now = start.Add(61500 * time.Millisecond)
+	if !slices.Equal(a.ValuesWithTimes(), want[1:]) {
+		t.Error(a.ValuesWithTimes())
+	}
+
+	b := NewConcurrent(Options{Window: 2})
+	b.Add(1)
+	if b.ValuesWithTimes() != nil {
+		t.Error(b.ValuesWithTimes())
+	}
+}
+
+func TestPrior(t *testing.T) {
+	a := NewConcurrent(Options{Window: 4, PriorMean: 100, PriorWeight: 4})
+	if a.Avg() != 100 || a.Summary().Avg != 100 || a.Compute(StatAvg)[StatAvg] != 100 {
+		t.Error(a.Avg(), a.Summary(), a.Compute(StatAvg))
+	}
+
+	// the prior's weight is 4·(4-2)/4 = 2, so the average is (2·100 + 10 + 30)/(2 + 2)
+	a.Add(10, 30)
+	if a.Avg() != 60 || a.Summary().Avg != 60 || a.Compute(StatAvg)[StatAvg] != 60 || a.Value() != 60 {
+		t.Error(a.Avg(), a.Summary(), a.Compute(StatAvg), a.Value())
+	}
+
+	// once the window is full, the prior no longer applies
+	a.Add(20, 40)
+	if a.Avg() != 25 || a.Summary().Avg != 25 {
+		t.Error(a.Avg(), a.Summary())
+	}
+
+	b := New(Options{Window: 4, MinSamples: 2, PriorMean: 100, PriorWeight: 1})
+	b.Add(10)
+	if b.Avg() != 100 {
+		t.Error(b.Avg())
+	}
+}
+
+func TestMinSamples(t *testing.T) {
+	a := New(Options{Window: 5, MinSamples: 3})
+	a.Add(10, 20)
+	if a.Avg() != 0 || a.Median() != 0 || a.Min() != 0 || a.Max() != 0 || a.Value() != 0 {
+		t.Error(a.Avg(), a.Median(), a.Min(), a.Max(), a.Value())
+	}
+	if s := a.Summary(); s != (Summary{Count: 2}) {
+		t.Error(s)
+	}
+	if c := a.Compute(StatCount, StatAvg); c[StatCount] != 2 || c[StatAvg] != 0 {
+		t.Error(c)
+	}
+	if a.Count() != 2 || len(a.Values()) != 2 {
+		t.Error(a.Count(), a.Values())
+	}
+
+	a.Add(30)
+	if a.Avg() != 20 || a.Min() != 10 || a.Max() != 30 {
+		t.Error(a.Avg(), a.Min(), a.Max())
+	}
+	if s := a.Summary(); s.Count != 3 || s.Avg != 20 {
+		t.Error(s)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	for _, a := range []MovingStats{New(Options{Window: 100}), NewConcurrent(Options{Window: 100})} {
+		if a.Percentile(95) != 0 {
+			t.Error(a.Percentile(95))
+		}
+		for i := 1; i <= 100; i++ {
+			a.Add(float64(i))
+		}
+		if a.Percentile(95) != 95 || a.Percentile(99.5) != 100 || a.Percentile(100) != 100 {
+			t.Error(a.Percentile(95), a.Percentile(99.5), a.Percentile(100))
+		}
+		if a.Percentile(0) != 0 || a.Percentile(101) != 0 || a.Percentile(math.NaN()) != 0 {
+			t.Error(a.Percentile(0), a.Percentile(101))
+		}
+	}
+
+	a := New(Options{Window: 4, QuantileInterpolation: QuantileLinear})
+	a.Add(1, 2, 3, 4)
+	if a.Percentile(50) != 2.5 || a.Percentile(0) != 1 {
+		t.Error(a.Percentile(50), a.Percentile(0))
+	}
+}
+
+func TestPercentileSummary(t *testing.T) {
+	a := NewConcurrent(Options{Window: 100})
+	if s := a.PercentileSummary(); len(s) != 4 || s[99] != 0 {
+		t.Error(s)
+	}
+	for i := 1; i <= 100; i++ {
+		a.Add(float64(i))
+	}
+	if s := a.PercentileSummary(); !maps.Equal(s, map[float64]float64{50: 50, 90: 90, 95: 95, 99: 99}) {
+		t.Error(s)
+	}
+	if s := a.PercentileSummary(99.9, 0, 150); !maps.Equal(s, map[float64]float64{99.9: 100, 0: 0, 150: 0}) {
+		t.Error(s)
+	}
+}
+
+func TestCV(t *testing.T) {
+	a := NewConcurrent(Options{Window: 8})
+	if a.CV() != 0 {
+		t.Error(a.CV())
+	}
+	a.Add(-1, 1)
+	if a.CV() != 0 {
+		t.Error(a.CV())
+	}
+	a.Add(2, 4, 4, 4, 5, 5, 7, 9) // mean 5, population stddev 2
+	if a.CV() != 0.4 {
+		t.Error(a.CV())
+	}
+	a.Add(-2, -4, -4, -4, -5, -5, -7, -9)
+	if a.CV() != 0.4 {
+		t.Error(a.CV())
+	}
+}
+
+func TestRangeRMS(t *testing.T) {
+	a := NewConcurrent(Options{Window: 4})
+	if a.Range() != 0 || a.RMS() != 0 {
+		t.Error(a.Range(), a.RMS())
+	}
+	a.Add(100, 1, -1, 1, -1)
+	if a.Range() != 2 || a.RMS() != 1 {
+		t.Error(a.Range(), a.RMS())
+	}
+	a.Add(3, 3)
+	if a.Range() != 4 || a.RMS() != math.Sqrt(5) {
+		t.Error(a.Range(), a.RMS())
+	}
+}
+
+func TestVarianceStdDev(t *testing.T) {
+	for _, a := range []MovingStats{New(Options{Window: 4}), NewConcurrent(Options{Window: 4})} {
+		if a.Variance() != 0 || a.StdDev() != 0 {
+			t.Error(a.Variance(), a.StdDev())
+		}
+		a.Add(1, 2, 4, 4, 6)
+		if a.Variance() != 2 || a.StdDev() != math.Sqrt(2) {
+			t.Error(a.Variance(), a.StdDev())
+		}
+	}
+}
+
+func TestVarianceSample(t *testing.T) {
+	a := NewConcurrent(Options{Window: 4, VarianceEstimator: VarianceSample})
+	a.Add(5)
+	if a.Variance() != 0 || a.StdDev() != 0 || a.ZScoreOf(10) != 0 {
+		t.Error(a.Variance(), a.StdDev(), a.ZScoreOf(10))
+	}
+	a.Add(1, 2, 4, 4, 6) // squared deviations from the mean (4) sum to 8
+	if a.Variance() != 8.0/3 || a.StdDev() != math.Sqrt(8.0/3) {
+		t.Error(a.Variance(), a.StdDev())
+	}
+
+	// mean 3, sample stddev 2
+	b := New(Options{Window: 3, VarianceEstimator: VarianceSample})
+	b.Add(1, 3, 5)
+	if b.ZScoreOf(7) != 2 || b.ZScore() != 1 || !approxEqual(b.CV(), 2.0/3) {
+		t.Error(b.ZScoreOf(7), b.ZScore(), b.CV())
+	}
+	if b.LastN(2).Variance() != 2 {
+		t.Error(b.LastN(2).Variance())
+	}
+}
+
+func TestTrendPct(t *testing.T) {
+	a := NewConcurrent(Options{Window: 3, TrackTrend: true})
+	a.Add(10, 10, 10)
+	if a.TrendPct() != 0 {
+		t.Error(a.TrendPct())
+	}
+	a.Add(13, 13)
+	if a.TrendPct() != 20 { // 12 vs. 10
+		t.Error(a.TrendPct())
+	}
+	a.Add(13)
+	if a.TrendPct() != 30 {
+		t.Error(a.TrendPct())
+	}
+	a.Add(2, 2, 2)
+	if !approxEqual(a.TrendPct(), -100*11.0/13) {
+		t.Error(a.TrendPct())
+	}
+	if a.LastN(2).TrendPct() != 0 {
+		t.Error(a.LastN(2).TrendPct())
+	}
+
+	a.ApplyOptions(Options{Window: 2, TrackTrend: true})
+	// the previous window keeps its newest values, and the value evicted by the smaller window
+	if !approxEqual(a.TrendPct(), -100*5.5/7.5) { // 2 vs. 13, 2
+		t.Error(a.TrendPct())
+	}
+
+	if b := New(Options{Window: 1}); b.TrendPct() != 0 {
+		t.Error(b.TrendPct())
+	}
+}
+
+func TestZScore(t *testing.T) {
+	a := NewConcurrent(Options{Window: 8})
+	if a.ZScore() != 0 || a.ZScoreOf(1) != 0 {
+		t.Error(a.ZScore(), a.ZScoreOf(1))
+	}
+	a.Add(4, 4)
+	if a.ZScore() != 0 || a.ZScoreOf(10) != 0 {
+		t.Error(a.ZScore(), a.ZScoreOf(10))
+	}
+
+	// mean 5, population stddev 2
+	a.Add(2, 4, 4, 4, 5, 5, 7, 9)
+	if a.ZScore() != 2 || a.ZScoreOf(4) != -0.5 {
+		t.Error(a.ZScore(), a.ZScoreOf(4))
+	}
+}
+
+func TestSkewnessKurtosis(t *testing.T) {
+	a := NewConcurrent(Options{Window: 5})
+	if a.Skewness() != 0 || a.Kurtosis() != 0 {
+		t.Error(a.Skewness(), a.Kurtosis())
+	}
+	a.Add(3, 3, 3)
+	if a.Skewness() != 0 || a.Kurtosis() != 0 {
+		t.Error(a.Skewness(), a.Kurtosis())
+	}
+
+	// symmetric
+	a.Add(1, 2, 3, 4, 5)
+	if !approxEqual(a.Skewness(), 0) || !approxEqual(a.Kurtosis(), 1.7-3) {
+		t.Error(a.Skewness(), a.Kurtosis())
+	}
+
+	// a long right tail: mean 2, central moments 16, 96, and 832
+	a.Add(0, 0, 0, 0, 10)
+	if !approxEqual(a.Skewness(), 96.0/64) || !approxEqual(a.Kurtosis(), 832.0/256-3) {
+		t.Error(a.Skewness(), a.Kurtosis())
+	}
+}
+
+func TestGeometricHarmonicMean(t *testing.T) {
+	a := NewConcurrent(Options{Window: 3})
+	if a.GeometricMean() != 0 || a.HarmonicMean() != 0 {
+		t.Error(a.GeometricMean(), a.HarmonicMean())
+	}
+	a.Add(1, 2, 4)
+	if !approxEqual(a.GeometricMean(), 2) || !approxEqual(a.HarmonicMean(), 12.0/7) {
+		t.Error(a.GeometricMean(), a.HarmonicMean())
+	}
+	a.Add(0)
+	if a.GeometricMean() != 0 || a.HarmonicMean() != 0 {
+		t.Error(a.GeometricMean(), a.HarmonicMean())
+	}
+	a.Add(-1, 2, 4)
+	if a.GeometricMean() != 0 || a.HarmonicMean() != 0 {
+		t.Error(a.GeometricMean(), a.HarmonicMean())
+	}
+
+	// the product of these values overflows
+	b := New(Options{Window: 100})
+	for i := 0; i < 100; i++ {
+		b.Add(1e300)
+	}
+	if !approxEqual(b.GeometricMean()/1e300, 1) {
+		t.Error(b.GeometricMean())
+	}
+}
+
+func TestTrimmedMean(t *testing.T) {
+	a := NewConcurrent(Options{Window: 10})
+	if a.TrimmedMean(0.1) != 0 {
+		t.Error(a.TrimmedMean(0.1))
+	}
+	a.Add(1, 2, 3, 4, 5, 6, 7, 8, 9, 1000)
+	if a.TrimmedMean(0.1) != 5.5 || a.TrimmedMean(0) != a.Avg() || a.TrimmedMean(0.5) != 5.5 {
+		t.Error(a.TrimmedMean(0.1), a.TrimmedMean(0), a.TrimmedMean(0.5))
+	}
+	if a.TrimmedMean(-0.1) != 0 || a.TrimmedMean(0.6) != 0 {
+		t.Error(a.TrimmedMean(-0.1), a.TrimmedMean(0.6))
+	}
+}
+
+func TestSum(t *testing.T) {
+	a := New(Options{Window: 3})
+	if a.Sum() != 0 {
+		t.Error(a.Sum())
+	}
+	a.Add(1, 2, 3, 4)
+	if a.Sum() != 9 {
+		t.Error(a.Sum())
+	}
+
+	// NaN and Inf values don't poison the sum once they're evicted
+	a.Add(math.Inf(1))
+	if !math.IsInf(a.Sum(), 1) {
+		t.Error(a.Sum())
+	}
+	a.Add(math.NaN(), 5)
+	if !math.IsNaN(a.Sum()) {
+		t.Error(a.Sum())
+	}
+	a.Add(6, 7, 8)
+	if a.Sum() != 21 {
+		t.Error(a.Sum())
+	}
+
+	// the sum doesn't drift as large and small values pass through the window
+	b := New(Options{Window: 10})
+	for i := 0; i < 10000; i++ {
+		b.Add(1e16, 1, -1e16, 0.1)
+	}
+	// the window holds -1e16, 0.1, 1e16, 1, -1e16, 0.1, 1e16, 1, -1e16, 0.1; allow for one ulp
+	if want := -1e16 + 2.3; math.Abs(b.Sum()-want) > 2 {
+		t.Error(b.Sum(), want)
+	}
+
+	// expired values are excluded
+	now := time.Now()
+	c := newMovingStats(Options{Window: 10, MaxAge: time.Minute})
+	c.now = func() time.Time { return now }
+	c.Add(1, 2)
+	now = now.Add(45 * time.Second)
+	c.Add(3)
+	now = now.Add(30 * time.Second)
+	if c.Sum() != 3 || c.LastN(5).Sum() != 3 {
+		t.Error(c.Sum(), c.LastN(5).Sum())
+	}
+}
+
+func TestSumOfSquaresProduct(t *testing.T) {
+	a := New(Options{Window: 3})
+	if a.SumOfSquares() != 0 || a.Product() != 0 {
+		t.Error(a.SumOfSquares(), a.Product())
+	}
+	a.Add(5, 2, -3, 4)
+	if a.SumOfSquares() != 29 || !approxEqual(a.Product(), -24) {
+		t.Error(a.SumOfSquares(), a.Product())
+	}
+	if a.LastN(2).SumOfSquares() != 25 || !approxEqual(a.LastN(2).Product(), -12) {
+		t.Error(a.LastN(2).SumOfSquares(), a.LastN(2).Product())
+	}
+
+	// zeros and NaN and Inf values don't poison the product once they're evicted
+	a.Add(0)
+	if a.Product() != 0 {
+		t.Error(a.Product())
+	}
+	a.Add(math.Inf(1))
+	if !math.IsNaN(a.Product()) {
+		t.Error(a.Product())
+	}
+	a.Add(-1, -2, 0.5)
+	if !approxEqual(a.Product(), 1) || a.SumOfSquares() != 5.25 {
+		t.Error(a.Product(), a.SumOfSquares())
+	}
+
+	// the running sums are recalculated as values pass through the window
+	for i := 0; i < 100; i++ {
+		a.Add(1.5, -2, 0.25)
+	}
+	if !approxEqual(a.Product(), -0.75) || a.SumOfSquares() != 6.3125 {
+		t.Error(a.Product(), a.SumOfSquares())
+	}
+}
+
+func TestSumLastAvgLast(t *testing.T) {
+	a := New(Options{Window: 5})
+	if a.SumLast(3) != 0 || a.AvgLast(3) != 0 {
+		t.Error(a.SumLast(3), a.AvgLast(3))
+	}
+
+	a.Add(1, 2, 3, 4, 5, 6)
+	if a.SumLast(2) != 11 || a.AvgLast(2) != 5.5 {
+		t.Error(a.SumLast(2), a.AvgLast(2))
+	}
+	if a.SumLast(10) != 20 || a.AvgLast(10) != 4 {
+		t.Error(a.SumLast(10), a.AvgLast(10))
+	}
+	if a.SumLast(0) != 0 || a.AvgLast(-1) != 0 {
+		t.Error(a.SumLast(0), a.AvgLast(-1))
+	}
+}
+
+func TestBufferCompaction(t *testing.T) {
+	agg := &sumAggregator{}
+	a := New(Options{Window: 3, Aggregators: []Aggregator{agg}})
+	for i := 1; i <= 20; i++ {
+		a.Add(float64(i))
+		if a.Count() != min(i, 3) {
+			t.Error(i, a.Count())
+		}
+	}
+	if !slices.Equal(a.Values(), stats.Float64Data{18, 19, 20}) {
+		t.Error(a.Values())
+	}
+	if agg.Value() != 57 {
+		t.Error(agg.Value())
+	}
+}
+
+func TestSummary(t *testing.T) {
+	a := NewConcurrent(Options{Window: 3})
+	if a.Summary() != (Summary{}) {
+		t.Error(a.Summary())
+	}
+
+	a.Add(1, 2, 3)
+	prev := a.Summary()
+	if prev != (Summary{Count: 3, Avg: 2, Min: 1, Max: 3}) {
+		t.Error(prev)
+	}
+
+	a.Add(7)
+	diff := a.Summary().Diff(prev)
+	if diff != (Summary{Count: 0, Avg: 2, Min: 1, Max: 4}) {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,117 @@
+// Package maarrow exports moving stats windows as Apache Arrow records and Parquet files,
+// so recorded windows can be analyzed directly in e.g. pandas, Polars, or DuckDB.
+//
+// Each value in a window becomes a row with the window's name and labels, the value's
+// position in the window, the time it was added, if the window tracks it, and the value.
+//
+// It is a separate module from movingaverage, so that package doesn't depend on Arrow.
+package maarrow
+
+import (
+	"io"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/apache/arrow/go/v17/parquet"
+	"github.com/apache/arrow/go/v17/parquet/compress"
+	"github.com/apache/arrow/go/v17/parquet/pqarrow"
+	movingaverage "github.com/cdzombak/golang-moving-average"
+)
+
+// Window is a moving stats window to export, with the name and labels identifying it.
+type Window struct {
+	Name   string
+	Labels map[string]string
+	Stats  movingaverage.ReadOnlyView
+}
+
+// Schema is the schema of the records returned by NewRecord, and of the Parquet files
+// written by WriteParquet:
+//
+//   - name: the window's name
+//   - labels: the window's labels, empty if it has none
+//   - index: the value's position in the window, from 0 for the oldest value
+//   - time: the time the value was added, in UTC, or null if the window doesn't track
+//     the times values were added (see movingaverage.Snapshot.Times)
+//   - value: the value
+var Schema = arrow.NewSchema([]arrow.Field{
+	{Name: "name", Type: arrow.BinaryTypes.String},
+	{Name: "labels", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.BinaryTypes.String)},
+	{Name: "index", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "time", Type: arrow.FixedWidthTypes.Timestamp_ns, Nullable: true},
+	{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+}, nil)
+
+// FromRegistry returns the windows registered in the given Registry, sorted by name.
+func FromRegistry(reg *movingaverage.Registry) []Window {
+	names := reg.Names()
+	retv := make([]Window, 0, len(names))
+	for _, name := range names {
+		// The instance may have been unregistered since listing the names
+		if ms, ok := reg.Get(name); ok {
+			retv = append(retv, Window{Name: name, Labels: reg.Labels(name), Stats: ms})
+		}
+	}
+	return retv
+}
+
+// NewRecord returns a record, per Schema, of the values in the given windows, in the given
+// order and oldest first within each window, allocated from mem (or, if it is nil, the Go
+// heap). Each window's values and times are read consistently, from a single Snapshot.
+// The caller must call Release on the record when done with it.
+func NewRecord(mem memory.Allocator, windows ...Window) arrow.Record {
+	if mem == nil {
+		mem = memory.DefaultAllocator
+	}
+	b := array.NewRecordBuilder(mem, Schema)
+	defer b.Release()
+
+	names := b.Field(0).(*array.StringBuilder)
+	labels := b.Field(1).(*array.MapBuilder)
+	labelKeys := labels.KeyBuilder().(*array.StringBuilder)
+	labelValues := labels.ItemBuilder().(*array.StringBuilder)
+	indexes := b.Field(2).(*array.Int64Builder)
+	times := b.Field(3).(*array.TimestampBuilder)
+	values := b.Field(4).(*array.Float64Builder)
+
+	for _, w := range windows {
+		snapshot := w.Stats.Snapshot()
+		for i, v := range snapshot.Values {
+			names.Append(w.Name)
+			labels.Append(true)
+			for k, lv := range w.Labels {
+				labelKeys.Append(k)
+				labelValues.Append(lv)
+			}
+			indexes.Append(int64(i))
+			if snapshot.Times != nil {
+				times.Append(arrow.Timestamp(snapshot.Times[i].UnixNano()))
+			} else {
+				times.AppendNull()
+			}
+			values.Append(v)
+		}
+	}
+	return b.NewRecord()
+}
+
+// WriteParquet writes the values in the given windows, per NewRecord, to w as a Parquet
+// file, compressed with Snappy.
+func WriteParquet(w io.Writer, windows ...Window) error {
+	rec := NewRecord(nil, windows...)
+	defer rec.Release()
+
+	fw, err := pqarrow.NewFileWriter(Schema, w,
+		parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy)),
+		pqarrow.DefaultWriterProps(),
+	)
+	if err != nil {
+		return err
+	}
+	if err := fw.Write(rec); err != nil {
+		_ = fw.Close()
+		return err
+	}
+	return fw.Close()
+}
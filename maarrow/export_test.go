@@ -0,0 +1,165 @@
+package maarrow
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/apache/arrow/go/v17/parquet/pqarrow"
+	movingaverage "github.com/cdzombak/golang-moving-average"
+)
+
+// row is a row of an exported record, for comparison.
+type row struct {
+	name   string
+	labels map[string]string
+	index  int64
+	time   *time.Time
+	value  float64
+}
+
+func TestNewRecord(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	windows, t0 := testWindows()
+	rec := NewRecord(mem, windows...)
+	defer rec.Release()
+
+	if !rec.Schema().Equal(Schema) {
+		t.Error(rec.Schema())
+	}
+	assertRows(t, rows(t, rec), t0)
+}
+
+func TestNewRecordEmpty(t *testing.T) {
+	rec := NewRecord(nil, Window{Name: "empty", Stats: movingaverage.New(movingaverage.Options{Window: 3})})
+	defer rec.Release()
+	if rec.NumRows() != 0 {
+		t.Error(rec.NumRows())
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	windows, t0 := testWindows()
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, windows...); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := pqarrow.ReadTable(context.Background(), bytes.NewReader(buf.Bytes()), nil, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer table.Release()
+	tr := array.NewTableReader(table, -1)
+	defer tr.Release()
+
+	var got []row
+	for tr.Next() {
+		got = append(got, rows(t, tr.Record())...)
+	}
+	assertRows(t, got, t0)
+}
+
+func TestFromRegistry(t *testing.T) {
+	reg := movingaverage.NewRegistry()
+	reg.Register("b", movingaverage.NewConcurrent(movingaverage.Options{Window: 3}), nil)
+	reg.Register("a", movingaverage.NewConcurrent(movingaverage.Options{Window: 3}), map[string]string{"route": "/"})
+
+	windows := FromRegistry(reg)
+	if len(windows) != 2 || windows[0].Name != "a" || windows[0].Labels["route"] != "/" || windows[1].Name != "b" {
+		t.Error(windows)
+	}
+}
+
+// testWindows returns a window which tracks the times values were added, with labels, and
+// one which doesn't, without; and the time the first value was added.
+func testWindows() ([]Window, time.Time) {
+	t0 := time.Now().UTC().Add(-time.Minute)
+	timed := movingaverage.New(movingaverage.Options{Window: 3, MaxAge: time.Hour})
+	timed.Restore(movingaverage.Snapshot{
+		Window: 3,
+		Values: []float64{1, 2},
+		Times:  []time.Time{t0, t0.Add(time.Second)},
+	})
+	untimed := movingaverage.New(movingaverage.Options{Window: 2})
+	untimed.Add(3, 4, 5)
+
+	return []Window{
+		{Name: "timed", Labels: map[string]string{"route": "/", "method": "GET"}, Stats: timed},
+		{Name: "untimed", Stats: untimed},
+	}, t0
+}
+
+func assertRows(t *testing.T, got []row, t0 time.Time) {
+	t.Helper()
+	t1 := t0.Add(time.Second)
+	labels := map[string]string{"route": "/", "method": "GET"}
+	want := []row{
+		{"timed", labels, 0, &t0, 1},
+		{"timed", labels, 1, &t1, 2},
+		{"untimed", map[string]string{}, 0, nil, 4},
+		{"untimed", map[string]string{}, 1, nil, 5},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, expected %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.name != w.name || g.index != w.index || g.value != w.value || len(g.labels) != len(w.labels) ||
+			(g.time == nil) != (w.time == nil) || (g.time != nil && !g.time.Equal(*w.time)) {
+			t.Errorf("row %d: got %+v, expected %+v", i, g, w)
+		}
+		for k, v := range w.labels {
+			if g.labels[k] != v {
+				t.Errorf("row %d: got labels %v, expected %v", i, g.labels, w.labels)
+			}
+		}
+	}
+}
+
+// rows returns the rows of the given record, per Schema.
+func rows(t *testing.T, rec arrow.Record) []row {
+	t.Helper()
+	var fields []string
+	for _, f := range rec.Schema().Fields() {
+		fields = append(fields, f.Name)
+	}
+	if !slices.Equal(fields, []string{"name", "labels", "index", "time", "value"}) {
+		t.Fatal(fields)
+	}
+
+	names := rec.Column(0).(*array.String)
+	labels := rec.Column(1).(*array.Map)
+	labelKeys := labels.Keys().(*array.String)
+	labelValues := labels.Items().(*array.String)
+	indexes := rec.Column(2).(*array.Int64)
+	times := rec.Column(3).(*array.Timestamp)
+	values := rec.Column(4).(*array.Float64)
+
+	var retv []row
+	for i := 0; i < int(rec.NumRows()); i++ {
+		r := row{
+			name:   names.Value(i),
+			labels: make(map[string]string),
+			index:  indexes.Value(i),
+			value:  values.Value(i),
+		}
+		start, end := labels.ValueOffsets(i)
+		for j := start; j < end; j++ {
+			r.labels[labelKeys.Value(int(j))] = labelValues.Value(int(j))
+		}
+		if times.IsValid(i) {
+			tm := time.Unix(0, int64(times.Value(i))).UTC()
+			r.time = &tm
+		}
+		retv = append(retv, r)
+	}
+	return retv
+}
@@ -0,0 +1,98 @@
+package magrpc
+
+import (
+	"context"
+	"time"
+
+	movingaverage "github.com/cdzombak/golang-moving-average"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MethodStats holds, per gRPC method, a window of handler durations and a window of the
+// status codes the handler returned, as recorded by UnaryServerInterceptor and
+// StreamServerInterceptor. Methods are keyed by their full name, e.g.
+// "/movingaverage.v1.MovingStatsService/Get".
+//
+// MethodStats is safe for concurrent use by multiple goroutines.
+type MethodStats struct {
+	durations *movingaverage.MovingStatsMap
+	codes     *movingaverage.MovingStatsMap
+}
+
+// NewMethodStats returns a new, empty MethodStats whose windows are created with the given
+// options, subject to the given limits, which apply to each of the duration and status code
+// maps. Unless opts.Unit is set, the duration windows' unit is UnitNanoseconds, as for
+// movingaverage.DurationStats. The status code windows use only opts.Window, opts.MaxAge, and
+// opts.Eviction, since the other options are meaningless for codes.
+func NewMethodStats(opts movingaverage.Options, limits movingaverage.MapLimits) *MethodStats {
+	durationOpts := opts
+	if durationOpts.Unit == movingaverage.UnitNone {
+		durationOpts.Unit = movingaverage.UnitNanoseconds
+	}
+	codeOpts := movingaverage.Options{
+		Window:   opts.Window,
+		MaxAge:   opts.MaxAge,
+		Eviction: opts.Eviction,
+	}
+	return &MethodStats{
+		durations: movingaverage.NewMovingStatsMap(durationOpts, limits),
+		codes:     movingaverage.NewMovingStatsMap(codeOpts, limits),
+	}
+}
+
+// Record records a call of the given method which took d and returned the given status code.
+// If either map rejects the method, per MapLimits.RejectNewKeys, that part isn't recorded.
+func (s *MethodStats) Record(method string, d time.Duration, code codes.Code) {
+	s.durations.Add(method, float64(d))
+	s.codes.Add(method, float64(code))
+}
+
+// Durations returns the window of handler durations, in nanoseconds, for the given method,
+// and whether there is one.
+func (s *MethodStats) Durations(method string) (movingaverage.MovingStats, bool) {
+	return s.durations.Lookup(method)
+}
+
+// Codes returns the window of status codes, as numbers, returned by the given method's
+// handler, and whether there is one. Its Frequencies give the distribution of codes.
+func (s *MethodStats) Codes(method string) (movingaverage.MovingStats, bool) {
+	return s.codes.Lookup(method)
+}
+
+// Methods returns the methods with a window of handler durations, sorted.
+func (s *MethodStats) Methods() []string {
+	return s.durations.Keys()
+}
+
+// UnaryServerInterceptor returns a gRPC unary server interceptor which records the duration
+// and status code of each call handled into the given MethodStats. If the handler panics,
+// the call is recorded with codes.Internal, and the panic continues.
+func UnaryServerInterceptor(s *MethodStats) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer s.recordCall(info.FullMethod, time.Now(), &err)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor which records the
+// duration and status code of each stream handled into the given MethodStats, as
+// UnaryServerInterceptor does for unary calls. The duration is that of the whole stream.
+func StreamServerInterceptor(s *MethodStats) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer s.recordCall(info.FullMethod, time.Now(), &err)
+		return handler(srv, ss)
+	}
+}
+
+// recordCall records a call of the given method which started at start and returned *err,
+// or, if it is panicking, records it with codes.Internal and continues the panic. It must
+// be deferred directly, so it can recover.
+func (s *MethodStats) recordCall(method string, start time.Time, err *error) {
+	if p := recover(); p != nil {
+		s.Record(method, time.Since(start), codes.Internal)
+		panic(p)
+	}
+	s.Record(method, time.Since(start), status.Code(*err))
+}
@@ -0,0 +1,145 @@
+package magrpc
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	movingaverage "github.com/cdzombak/golang-moving-average"
+	"github.com/cdzombak/golang-moving-average/magrpc/magrpcpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	getMethod       = "/movingaverage.v1.MovingStatsService/Get"
+	subscribeMethod = "/movingaverage.v1.MovingStatsService/Subscribe"
+)
+
+func TestInterceptors(t *testing.T) {
+	reg := movingaverage.NewRegistry()
+	reg.Register("a", movingaverage.NewConcurrent(movingaverage.Options{Window: 3}), nil)
+	stats := NewMethodStats(movingaverage.Options{Window: 10}, movingaverage.MapLimits{})
+	client := newTestClient(t, NewServer(reg), []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(stats)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(stats)),
+	})
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "a", "missing"} {
+		_, _ = client.Get(ctx, &magrpcpb.GetRequest{Name: name})
+	}
+	stream, err := client.Subscribe(ctx, &magrpcpb.SubscribeRequest{Name: "a"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if err == nil {
+		t.Fatal("expected an error for a missing interval")
+	}
+
+	if methods := stats.Methods(); !slices.Equal(methods, []string{getMethod, subscribeMethod}) {
+		t.Fatal(methods)
+	}
+	durations, ok := stats.Durations(getMethod)
+	if !ok || durations.Count() != 3 || durations.Unit() != movingaverage.UnitNanoseconds || durations.Min() <= 0 {
+		t.Error(durations)
+	}
+	if got, ok := stats.Codes(getMethod); !ok || !maps.Equal(got.Frequencies(), map[float64]int{
+		float64(codes.OK):       2,
+		float64(codes.NotFound): 1,
+	}) {
+		t.Error(got.Frequencies())
+	}
+	if got, ok := stats.Codes(subscribeMethod); !ok || !maps.Equal(got.Frequencies(), map[float64]int{
+		float64(codes.InvalidArgument): 1,
+	}) {
+		t.Error(got.Frequencies())
+	}
+	if _, ok := stats.Durations("/missing"); ok {
+		t.Error("expected no window for an uncalled method")
+	}
+}
+
+func TestInterceptorsStreamDuration(t *testing.T) {
+	reg := movingaverage.NewRegistry()
+	reg.Register("a", movingaverage.NewConcurrent(movingaverage.Options{Window: 3}), nil)
+	stats := NewMethodStats(movingaverage.Options{Window: 10}, movingaverage.MapLimits{})
+	client := newTestClient(t, NewServer(reg), []grpc.ServerOption{
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(stats)),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := client.Subscribe(ctx, &magrpcpb.SubscribeRequest{Name: "a", Interval: durationpb.New(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	// The stream is recorded once its handler returns, after the client cancels
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if durations, ok := stats.Durations(subscribeMethod); ok {
+			if durations.Count() != 1 || durations.Max() < float64(20*time.Millisecond) {
+				t.Error(durations.Values())
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream wasn't recorded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestInterceptorsPanic(t *testing.T) {
+	stats := NewMethodStats(movingaverage.Options{Window: 10}, movingaverage.MapLimits{})
+	unary := UnaryServerInterceptor(stats)
+	stream := StreamServerInterceptor(stats)
+
+	for _, call := range []func(){
+		func() {
+			_, _ = unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/unary"}, func(context.Context, any) (any, error) {
+				panic("boom")
+			})
+		},
+		func() {
+			_ = stream(nil, nil, &grpc.StreamServerInfo{FullMethod: "/stream"}, func(any, grpc.ServerStream) error {
+				panic("boom")
+			})
+		},
+	} {
+		func() {
+			defer func() {
+				if p := recover(); p != "boom" {
+					t.Error("expected the panic to continue, got", p)
+				}
+			}()
+			call()
+		}()
+	}
+
+	for _, method := range []string{"/unary", "/stream"} {
+		if got, ok := stats.Codes(method); !ok || !slices.Equal(got.Values(), []float64{float64(codes.Internal)}) {
+			t.Error(method, got)
+		}
+	}
+}
+
+func TestMethodStatsRejectNewKeys(t *testing.T) {
+	stats := NewMethodStats(movingaverage.Options{Window: 10}, movingaverage.MapLimits{MaxKeys: 1, RejectNewKeys: true})
+	stats.Record("/a", time.Millisecond, codes.OK)
+	stats.Record("/b", time.Millisecond, codes.OK)
+	if methods := stats.Methods(); !slices.Equal(methods, []string{"/a"}) {
+		t.Error(methods)
+	}
+	if _, ok := stats.Codes("/b"); ok {
+		t.Error("expected /b to be rejected")
+	}
+}
@@ -0,0 +1,535 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: movingaverage.proto
+
+package magrpcpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// InstanceSummary is the summary of a registered instance at a point in time.
+type InstanceSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	Count         int64                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	Avg           float64                `protobuf:"fixed64,5,opt,name=avg,proto3" json:"avg,omitempty"`
+	Min           float64                `protobuf:"fixed64,6,opt,name=min,proto3" json:"min,omitempty"`
+	Max           float64                `protobuf:"fixed64,7,opt,name=max,proto3" json:"max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstanceSummary) Reset() {
+	*x = InstanceSummary{}
+	mi := &file_movingaverage_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstanceSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstanceSummary) ProtoMessage() {}
+
+func (x *InstanceSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_movingaverage_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstanceSummary.ProtoReflect.Descriptor instead.
+func (*InstanceSummary) Descriptor() ([]byte, []int) {
+	return file_movingaverage_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *InstanceSummary) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InstanceSummary) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *InstanceSummary) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *InstanceSummary) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *InstanceSummary) GetAvg() float64 {
+	if x != nil {
+		return x.Avg
+	}
+	return 0
+}
+
+func (x *InstanceSummary) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *InstanceSummary) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_movingaverage_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movingaverage_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_movingaverage_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Summary       *InstanceSummary       `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_movingaverage_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_movingaverage_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_movingaverage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetResponse) GetSummary() *InstanceSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+type SummaryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SummaryRequest) Reset() {
+	*x = SummaryRequest{}
+	mi := &file_movingaverage_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummaryRequest) ProtoMessage() {}
+
+func (x *SummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movingaverage_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummaryRequest.ProtoReflect.Descriptor instead.
+func (*SummaryRequest) Descriptor() ([]byte, []int) {
+	return file_movingaverage_proto_rawDescGZIP(), []int{3}
+}
+
+type SummaryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Summaries     []*InstanceSummary     `protobuf:"bytes,1,rep,name=summaries,proto3" json:"summaries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SummaryResponse) Reset() {
+	*x = SummaryResponse{}
+	mi := &file_movingaverage_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SummaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummaryResponse) ProtoMessage() {}
+
+func (x *SummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_movingaverage_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummaryResponse.ProtoReflect.Descriptor instead.
+func (*SummaryResponse) Descriptor() ([]byte, []int) {
+	return file_movingaverage_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SummaryResponse) GetSummaries() []*InstanceSummary {
+	if x != nil {
+		return x.Summaries
+	}
+	return nil
+}
+
+type ValuesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValuesRequest) Reset() {
+	*x = ValuesRequest{}
+	mi := &file_movingaverage_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValuesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValuesRequest) ProtoMessage() {}
+
+func (x *ValuesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movingaverage_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValuesRequest.ProtoReflect.Descriptor instead.
+func (*ValuesRequest) Descriptor() ([]byte, []int) {
+	return file_movingaverage_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ValuesRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ValuesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []float64              `protobuf:"fixed64,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValuesResponse) Reset() {
+	*x = ValuesResponse{}
+	mi := &file_movingaverage_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValuesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValuesResponse) ProtoMessage() {}
+
+func (x *ValuesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_movingaverage_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValuesResponse.ProtoReflect.Descriptor instead.
+func (*ValuesResponse) Descriptor() ([]byte, []int) {
+	return file_movingaverage_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ValuesResponse) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the instance to stream, or empty to stream all registered instances.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// How often to send summaries. Must be positive.
+	Interval      *durationpb.Duration `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_movingaverage_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movingaverage_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_movingaverage_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SubscribeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+var File_movingaverage_proto protoreflect.FileDescriptor
+
+const file_movingaverage_proto_rawDesc = "" +
+	"\n" +
+	"\x13movingaverage.proto\x12\x10movingaverage.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa3\x02\n" +
+	"\x0fInstanceSummary\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12E\n" +
+	"\x06labels\x18\x02 \x03(\v2-.movingaverage.v1.InstanceSummary.LabelsEntryR\x06labels\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x03R\x05count\x12\x10\n" +
+	"\x03avg\x18\x05 \x01(\x01R\x03avg\x12\x10\n" +
+	"\x03min\x18\x06 \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\a \x01(\x01R\x03max\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\" \n" +
+	"\n" +
+	"GetRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"J\n" +
+	"\vGetResponse\x12;\n" +
+	"\asummary\x18\x01 \x01(\v2!.movingaverage.v1.InstanceSummaryR\asummary\"\x10\n" +
+	"\x0eSummaryRequest\"R\n" +
+	"\x0fSummaryResponse\x12?\n" +
+	"\tsummaries\x18\x01 \x03(\v2!.movingaverage.v1.InstanceSummaryR\tsummaries\"#\n" +
+	"\rValuesRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"(\n" +
+	"\x0eValuesResponse\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\x01R\x06values\"]\n" +
+	"\x10SubscribeRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x125\n" +
+	"\binterval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\binterval2\xcb\x02\n" +
+	"\x12MovingStatsService\x12B\n" +
+	"\x03Get\x12\x1c.movingaverage.v1.GetRequest\x1a\x1d.movingaverage.v1.GetResponse\x12N\n" +
+	"\aSummary\x12 .movingaverage.v1.SummaryRequest\x1a!.movingaverage.v1.SummaryResponse\x12K\n" +
+	"\x06Values\x12\x1f.movingaverage.v1.ValuesRequest\x1a .movingaverage.v1.ValuesResponse\x12T\n" +
+	"\tSubscribe\x12\".movingaverage.v1.SubscribeRequest\x1a!.movingaverage.v1.SummaryResponse0\x01B;Z9github.com/cdzombak/golang-moving-average/magrpc/magrpcpbb\x06proto3"
+
+var (
+	file_movingaverage_proto_rawDescOnce sync.Once
+	file_movingaverage_proto_rawDescData []byte
+)
+
+func file_movingaverage_proto_rawDescGZIP() []byte {
+	file_movingaverage_proto_rawDescOnce.Do(func() {
+		file_movingaverage_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_movingaverage_proto_rawDesc), len(file_movingaverage_proto_rawDesc)))
+	})
+	return file_movingaverage_proto_rawDescData
+}
+
+var file_movingaverage_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_movingaverage_proto_goTypes = []any{
+	(*InstanceSummary)(nil),       // 0: movingaverage.v1.InstanceSummary
+	(*GetRequest)(nil),            // 1: movingaverage.v1.GetRequest
+	(*GetResponse)(nil),           // 2: movingaverage.v1.GetResponse
+	(*SummaryRequest)(nil),        // 3: movingaverage.v1.SummaryRequest
+	(*SummaryResponse)(nil),       // 4: movingaverage.v1.SummaryResponse
+	(*ValuesRequest)(nil),         // 5: movingaverage.v1.ValuesRequest
+	(*ValuesResponse)(nil),        // 6: movingaverage.v1.ValuesResponse
+	(*SubscribeRequest)(nil),      // 7: movingaverage.v1.SubscribeRequest
+	nil,                           // 8: movingaverage.v1.InstanceSummary.LabelsEntry
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 10: google.protobuf.Duration
+}
+var file_movingaverage_proto_depIdxs = []int32{
+	8,  // 0: movingaverage.v1.InstanceSummary.labels:type_name -> movingaverage.v1.InstanceSummary.LabelsEntry
+	9,  // 1: movingaverage.v1.InstanceSummary.time:type_name -> google.protobuf.Timestamp
+	0,  // 2: movingaverage.v1.GetResponse.summary:type_name -> movingaverage.v1.InstanceSummary
+	0,  // 3: movingaverage.v1.SummaryResponse.summaries:type_name -> movingaverage.v1.InstanceSummary
+	10, // 4: movingaverage.v1.SubscribeRequest.interval:type_name -> google.protobuf.Duration
+	1,  // 5: movingaverage.v1.MovingStatsService.Get:input_type -> movingaverage.v1.GetRequest
+	3,  // 6: movingaverage.v1.MovingStatsService.Summary:input_type -> movingaverage.v1.SummaryRequest
+	5,  // 7: movingaverage.v1.MovingStatsService.Values:input_type -> movingaverage.v1.ValuesRequest
+	7,  // 8: movingaverage.v1.MovingStatsService.Subscribe:input_type -> movingaverage.v1.SubscribeRequest
+	2,  // 9: movingaverage.v1.MovingStatsService.Get:output_type -> movingaverage.v1.GetResponse
+	4,  // 10: movingaverage.v1.MovingStatsService.Summary:output_type -> movingaverage.v1.SummaryResponse
+	6,  // 11: movingaverage.v1.MovingStatsService.Values:output_type -> movingaverage.v1.ValuesResponse
+	4,  // 12: movingaverage.v1.MovingStatsService.Subscribe:output_type -> movingaverage.v1.SummaryResponse
+	9,  // [9:13] is the sub-list for method output_type
+	5,  // [5:9] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_movingaverage_proto_init() }
+func file_movingaverage_proto_init() {
+	if File_movingaverage_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_movingaverage_proto_rawDesc), len(file_movingaverage_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_movingaverage_proto_goTypes,
+		DependencyIndexes: file_movingaverage_proto_depIdxs,
+		MessageInfos:      file_movingaverage_proto_msgTypes,
+	}.Build()
+	File_movingaverage_proto = out.File
+	file_movingaverage_proto_goTypes = nil
+	file_movingaverage_proto_depIdxs = nil
+}
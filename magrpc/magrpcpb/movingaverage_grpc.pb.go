@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: movingaverage.proto
+
+package magrpcpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MovingStatsService_Get_FullMethodName       = "/movingaverage.v1.MovingStatsService/Get"
+	MovingStatsService_Summary_FullMethodName   = "/movingaverage.v1.MovingStatsService/Summary"
+	MovingStatsService_Values_FullMethodName    = "/movingaverage.v1.MovingStatsService/Values"
+	MovingStatsService_Subscribe_FullMethodName = "/movingaverage.v1.MovingStatsService/Subscribe"
+)
+
+// MovingStatsServiceClient is the client API for MovingStatsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MovingStatsService queries the moving stats instances in a running service's registry.
+type MovingStatsServiceClient interface {
+	// Get returns the summary of the named instance.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// Summary returns the summaries of all registered instances, sorted by name.
+	Summary(ctx context.Context, in *SummaryRequest, opts ...grpc.CallOption) (*SummaryResponse, error)
+	// Values returns the values in the named instance, oldest first.
+	Values(ctx context.Context, in *ValuesRequest, opts ...grpc.CallOption) (*ValuesResponse, error)
+	// Subscribe streams the summaries of the named instance, or of all registered instances
+	// if no name is given, immediately and then every interval, until the client cancels.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SummaryResponse], error)
+}
+
+type movingStatsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMovingStatsServiceClient(cc grpc.ClientConnInterface) MovingStatsServiceClient {
+	return &movingStatsServiceClient{cc}
+}
+
+func (c *movingStatsServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, MovingStatsService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movingStatsServiceClient) Summary(ctx context.Context, in *SummaryRequest, opts ...grpc.CallOption) (*SummaryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SummaryResponse)
+	err := c.cc.Invoke(ctx, MovingStatsService_Summary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movingStatsServiceClient) Values(ctx context.Context, in *ValuesRequest, opts ...grpc.CallOption) (*ValuesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValuesResponse)
+	err := c.cc.Invoke(ctx, MovingStatsService_Values_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movingStatsServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SummaryResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MovingStatsService_ServiceDesc.Streams[0], MovingStatsService_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, SummaryResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MovingStatsService_SubscribeClient = grpc.ServerStreamingClient[SummaryResponse]
+
+// MovingStatsServiceServer is the server API for MovingStatsService service.
+// All implementations must embed UnimplementedMovingStatsServiceServer
+// for forward compatibility.
+//
+// MovingStatsService queries the moving stats instances in a running service's registry.
+type MovingStatsServiceServer interface {
+	// Get returns the summary of the named instance.
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	// Summary returns the summaries of all registered instances, sorted by name.
+	Summary(context.Context, *SummaryRequest) (*SummaryResponse, error)
+	// Values returns the values in the named instance, oldest first.
+	Values(context.Context, *ValuesRequest) (*ValuesResponse, error)
+	// Subscribe streams the summaries of the named instance, or of all registered instances
+	// if no name is given, immediately and then every interval, until the client cancels.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[SummaryResponse]) error
+	mustEmbedUnimplementedMovingStatsServiceServer()
+}
+
+// UnimplementedMovingStatsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMovingStatsServiceServer struct{}
+
+func (UnimplementedMovingStatsServiceServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedMovingStatsServiceServer) Summary(context.Context, *SummaryRequest) (*SummaryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Summary not implemented")
+}
+func (UnimplementedMovingStatsServiceServer) Values(context.Context, *ValuesRequest) (*ValuesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Values not implemented")
+}
+func (UnimplementedMovingStatsServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[SummaryResponse]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedMovingStatsServiceServer) mustEmbedUnimplementedMovingStatsServiceServer() {}
+func (UnimplementedMovingStatsServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeMovingStatsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MovingStatsServiceServer will
+// result in compilation errors.
+type UnsafeMovingStatsServiceServer interface {
+	mustEmbedUnimplementedMovingStatsServiceServer()
+}
+
+func RegisterMovingStatsServiceServer(s grpc.ServiceRegistrar, srv MovingStatsServiceServer) {
+	// If the following call panics, it indicates UnimplementedMovingStatsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MovingStatsService_ServiceDesc, srv)
+}
+
+func _MovingStatsService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovingStatsServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovingStatsService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovingStatsServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovingStatsService_Summary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovingStatsServiceServer).Summary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovingStatsService_Summary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovingStatsServiceServer).Summary(ctx, req.(*SummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovingStatsService_Values_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovingStatsServiceServer).Values(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovingStatsService_Values_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovingStatsServiceServer).Values(ctx, req.(*ValuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovingStatsService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MovingStatsServiceServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, SummaryResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MovingStatsService_SubscribeServer = grpc.ServerStreamingServer[SummaryResponse]
+
+// MovingStatsService_ServiceDesc is the grpc.ServiceDesc for MovingStatsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MovingStatsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "movingaverage.v1.MovingStatsService",
+	HandlerType: (*MovingStatsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _MovingStatsService_Get_Handler,
+		},
+		{
+			MethodName: "Summary",
+			Handler:    _MovingStatsService_Summary_Handler,
+		},
+		{
+			MethodName: "Values",
+			Handler:    _MovingStatsService_Values_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _MovingStatsService_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "movingaverage.proto",
+}
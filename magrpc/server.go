@@ -0,0 +1,146 @@
+// Package magrpc integrates moving stats with gRPC: a MovingStatsService which exposes the
+// instances in a movingaverage.Registry, so sidecars and control planes can query rolling
+// stats from running services, and server interceptors which record each method's handler
+// durations and status codes.
+//
+// It is a separate module from movingaverage, so that package doesn't depend on gRPC.
+package magrpc
+
+import (
+	"context"
+	"time"
+
+	movingaverage "github.com/cdzombak/golang-moving-average"
+	"github.com/cdzombak/golang-moving-average/magrpc/magrpcpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//go:generate protoc -I magrpcpb --go_out=magrpcpb --go_opt=paths=source_relative --go-grpc_out=magrpcpb --go-grpc_opt=paths=source_relative movingaverage.proto
+
+// Server implements the MovingStatsService over the instances in a Registry.
+//
+// Server is safe for concurrent use by multiple goroutines.
+type Server struct {
+	magrpcpb.UnimplementedMovingStatsServiceServer
+
+	reg *movingaverage.Registry
+	now func() time.Time
+}
+
+// NewServer returns a new Server exposing the instances in the given Registry.
+func NewServer(reg *movingaverage.Registry) *Server {
+	return &Server{
+		reg: reg,
+		now: time.Now,
+	}
+}
+
+// Register registers the Server's MovingStatsService with the given gRPC server.
+func (s *Server) Register(gs grpc.ServiceRegistrar) {
+	magrpcpb.RegisterMovingStatsServiceServer(gs, s)
+}
+
+// Get returns the summary of the named instance, or a NotFound error if there is none.
+func (s *Server) Get(_ context.Context, req *magrpcpb.GetRequest) (*magrpcpb.GetResponse, error) {
+	summary, err := s.summary(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &magrpcpb.GetResponse{Summary: summary}, nil
+}
+
+// Summary returns the summaries of all registered instances, sorted by name.
+func (s *Server) Summary(context.Context, *magrpcpb.SummaryRequest) (*magrpcpb.SummaryResponse, error) {
+	return s.summaries(""), nil
+}
+
+// Values returns the values in the named instance, oldest first, or a NotFound error if
+// there is none.
+func (s *Server) Values(_ context.Context, req *magrpcpb.ValuesRequest) (*magrpcpb.ValuesResponse, error) {
+	ms, ok := s.reg.Get(req.GetName())
+	if !ok {
+		return nil, notFound(req.GetName())
+	}
+	return &magrpcpb.ValuesResponse{Values: ms.Values()}, nil
+}
+
+// Subscribe streams the summaries of the named instance, or of all registered instances if
+// no name is given, immediately and then every interval, until the client cancels. It
+// returns a NotFound error if the named instance isn't registered, and an InvalidArgument
+// error if the interval isn't positive. If the named instance is unregistered while
+// streaming, the stream ends with a NotFound error.
+func (s *Server) Subscribe(req *magrpcpb.SubscribeRequest, stream grpc.ServerStreamingServer[magrpcpb.SummaryResponse]) error {
+	interval := req.GetInterval().AsDuration()
+	if interval <= 0 {
+		return status.Errorf(codes.InvalidArgument, "interval must be positive, got %s", interval)
+	}
+	name := req.GetName()
+	if name != "" {
+		if _, ok := s.reg.Get(name); !ok {
+			return notFound(name)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		resp := s.summaries(name)
+		if name != "" && len(resp.GetSummaries()) == 0 {
+			return notFound(name)
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// summary returns the summary of the named instance, or a NotFound error if there is none.
+func (s *Server) summary(name string) (*magrpcpb.InstanceSummary, error) {
+	ms, ok := s.reg.Get(name)
+	if !ok {
+		return nil, notFound(name)
+	}
+	return newInstanceSummary(s.now(), name, s.reg.Labels(name), ms.Summary()), nil
+}
+
+// summaries returns the summary of the named instance, if it is registered, or of all
+// registered instances if name is empty.
+func (s *Server) summaries(name string) *magrpcpb.SummaryResponse {
+	retv := &magrpcpb.SummaryResponse{}
+	if name != "" {
+		if summary, err := s.summary(name); err == nil {
+			retv.Summaries = append(retv.Summaries, summary)
+		}
+		return retv
+	}
+
+	now := s.now()
+	for _, rs := range s.reg.Summaries() {
+		retv.Summaries = append(retv.Summaries, newInstanceSummary(now, rs.Name, rs.Labels, rs.Summary))
+	}
+	return retv
+}
+
+func newInstanceSummary(now time.Time, name string, labels map[string]string, summary movingaverage.Summary) *magrpcpb.InstanceSummary {
+	return &magrpcpb.InstanceSummary{
+		Name:   name,
+		Labels: labels,
+		Time:   timestamppb.New(now),
+		Count:  int64(summary.Count),
+		Avg:    summary.Avg,
+		Min:    summary.Min,
+		Max:    summary.Max,
+	}
+}
+
+func notFound(name string) error {
+	return status.Errorf(codes.NotFound, "no instance named %q", name)
+}
@@ -0,0 +1,148 @@
+package magrpc
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	movingaverage "github.com/cdzombak/golang-moving-average"
+	"github.com/cdzombak/golang-moving-average/magrpc/magrpcpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestServer(t *testing.T) {
+	reg := movingaverage.NewRegistry()
+	a := movingaverage.NewConcurrent(movingaverage.Options{Window: 3})
+	a.Add(1, 2, 3)
+	reg.Register("a", a, map[string]string{"route": "/"})
+	reg.Register("b", movingaverage.NewConcurrent(movingaverage.Options{Window: 3}), nil)
+	client := newTestClient(t, NewServer(reg), nil)
+	ctx := context.Background()
+
+	get, err := client.Get(ctx, &magrpcpb.GetRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := get.GetSummary(); s.GetName() != "a" || s.GetLabels()["route"] != "/" || s.GetCount() != 3 ||
+		s.GetAvg() != 2 || s.GetMin() != 1 || s.GetMax() != 3 || s.GetTime() == nil {
+		t.Error(s)
+	}
+
+	summary, err := client.Summary(ctx, &magrpcpb.SummaryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.GetSummaries()) != 2 || summary.GetSummaries()[1].GetName() != "b" {
+		t.Error(summary)
+	}
+
+	values, err := client.Values(ctx, &magrpcpb.ValuesRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(values.GetValues(), []float64{1, 2, 3}) {
+		t.Error(values)
+	}
+
+	if _, err := client.Get(ctx, &magrpcpb.GetRequest{Name: "missing"}); status.Code(err) != codes.NotFound {
+		t.Error(err)
+	}
+	if _, err := client.Values(ctx, &magrpcpb.ValuesRequest{Name: "missing"}); status.Code(err) != codes.NotFound {
+		t.Error(err)
+	}
+}
+
+func TestServerSubscribe(t *testing.T) {
+	reg := movingaverage.NewRegistry()
+	a := movingaverage.NewConcurrent(movingaverage.Options{Window: 3})
+	reg.Register("a", a, nil)
+	reg.Register("b", movingaverage.NewConcurrent(movingaverage.Options{Window: 3}), nil)
+	client := newTestClient(t, NewServer(reg), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Subscribe(ctx, &magrpcpb.SubscribeRequest{Name: "a", Interval: durationpb.New(10 * time.Millisecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two updates, the second sent after the interval
+	for i := 0; i < 2; i++ {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.GetSummaries()) != 1 || resp.GetSummaries()[0].GetCount() != int64(i) {
+			t.Error(resp)
+		}
+		a.Add(1)
+	}
+
+	// the stream ends if the instance is unregistered
+	reg.Unregister("a")
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if status.Code(err) != codes.NotFound {
+				t.Error(err)
+			}
+			break
+		}
+	}
+
+	all, err := client.Subscribe(ctx, &magrpcpb.SubscribeRequest{Interval: durationpb.New(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := all.Recv(); err != nil || len(resp.GetSummaries()) != 1 {
+		t.Error(resp, err)
+	}
+
+	for _, req := range []*magrpcpb.SubscribeRequest{
+		{Name: "b"},
+		{Name: "b", Interval: durationpb.New(-time.Second)},
+	} {
+		stream, err := client.Subscribe(ctx, req)
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.InvalidArgument {
+			t.Error(req, err)
+		}
+	}
+	stream, err = client.Subscribe(ctx, &magrpcpb.SubscribeRequest{Name: "missing", Interval: durationpb.New(time.Second)})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Error(err)
+	}
+}
+
+// newTestClient serves the given Server over an in-memory connection, with the given server
+// options, and returns a client for it.
+func newTestClient(t *testing.T, s *Server, opts []grpc.ServerOption) magrpcpb.MovingStatsServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer(opts...)
+	s.Register(gs)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return magrpcpb.NewMovingStatsServiceClient(conn)
+}
@@ -0,0 +1,86 @@
+package movingaverage
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPMiddleware returns HTTP middleware which records the latency and outcome of
+// each request handled by the wrapped handler into the given RequestStats.
+// Responses with a status code of 500 or greater are recorded as failures, as are
+// requests whose handler panics (the panic is then propagated).
+//
+// The ResponseWriter passed to the wrapped handler supports http.Flusher and
+// http.Hijacker, if the underlying ResponseWriter does, so streaming handlers such as
+// NewSSEHandler and NewWebSocketHandler work behind it.
+func HTTPMiddleware(rs *RequestStats) func(http.Handler) http.Handler {
+	return HTTPMiddlewareFunc(func(*http.Request) *RequestStats {
+		return rs
+	})
+}
+
+// HTTPMiddlewareFunc returns HTTP middleware which records the latency and outcome of
+// each request handled by the wrapped handler into the RequestStats returned by pick
+// for that request, allowing e.g. per-route stats. If pick returns nil, the request
+// is not recorded.
+// Responses and panics are recorded as by HTTPMiddleware.
+func HTTPMiddlewareFunc(pick func(*http.Request) *RequestStats) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rs := pick(r)
+			if rs == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			defer func() {
+				if p := recover(); p != nil {
+					rs.Record(time.Since(start), false)
+					panic(p)
+				}
+				rs.Record(time.Since(start), rec.status < http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// statusRecorder is an http.ResponseWriter which records the response's status code.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying http.ResponseWriter, if it supports flushing.
+func (r *statusRecorder) Flush() {
+	r.wroteHeader = true
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack hijacks the underlying http.ResponseWriter's connection, if it supports hijacking.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,113 @@
+package movingaverage
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	rs := NewRequestStats(Options{Window: 10})
+	h := HTTPMiddleware(rs)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+
+	for _, path := range []string{"/", "/missing", "/fail", "/"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if rs.Count() != 4 {
+		t.Error(rs.Count())
+	}
+	if rs.ErrorRate() != 0.25 {
+		t.Error(rs.ErrorRate())
+	}
+}
+
+func TestHTTPMiddlewareFunc(t *testing.T) {
+	api := NewRequestStats(Options{Window: 10})
+	mw := HTTPMiddlewareFunc(func(r *http.Request) *RequestStats {
+		if r.URL.Path == "/api" {
+			return api
+		}
+		return nil
+	})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/other", nil))
+	if api.Count() != 1 || api.ErrorRate() != 0 {
+		t.Error(api.Count(), api.ErrorRate())
+	}
+}
+
+func TestHTTPMiddlewareStreaming(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("a", New(Options{Window: 3}), nil)
+	rs := NewRequestStats(Options{Window: 10})
+	srv := httptest.NewServer(HTTPMiddleware(rs)(NewSSEHandler(reg, time.Hour)))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal(resp.Status)
+	}
+	event, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if event != "event: summary\n" {
+		t.Errorf("%q", event)
+	}
+	_ = resp.Body.Close()
+
+	// the middleware's ResponseWriter can be hijacked, e.g. by NewWebSocketHandler
+	hijacked := false
+	h := HTTPMiddleware(rs)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		hijacked = true
+		_, _ = conn.Write([]byte("HTTP/1.1 204 No Content\r\n\r\n"))
+		_ = conn.Close()
+	}))
+	srv2 := httptest.NewServer(h)
+	defer srv2.Close()
+	resp, err = http.Get(srv2.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if !hijacked || resp.StatusCode != http.StatusNoContent {
+		t.Error(hijacked, resp.Status)
+	}
+}
+
+func TestHTTPMiddlewarePanic(t *testing.T) {
+	rs := NewRequestStats(Options{Window: 10})
+	h := HTTPMiddleware(rs)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Error("expected the panic to be propagated, got", p)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	if rs.Count() != 1 || rs.ErrorRate() != 1 {
+		t.Error(rs.Count(), rs.ErrorRate())
+	}
+}
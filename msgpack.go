@@ -0,0 +1,352 @@
+package movingaverage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MarshalMsgpack encodes the Snapshot as MessagePack: a map with the keys "window"
+// (an integer), "values" (an array of float64s), and "times" (an array of integers
+// holding Unix times in nanoseconds, or nil).
+func (s Snapshot) MarshalMsgpack() ([]byte, error) {
+	buf := make([]byte, 0, 32+9*len(s.Values)+9*len(s.Times))
+	buf = append(buf, 0x83) // fixmap with 3 entries
+
+	buf = msgpackAppendString(buf, "window")
+	buf = msgpackAppendInt(buf, int64(s.Window))
+
+	buf = msgpackAppendString(buf, "values")
+	buf = msgpackAppendArrayHeader(buf, len(s.Values))
+	for _, v := range s.Values {
+		buf = append(buf, 0xcb)
+		buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(v))
+	}
+
+	buf = msgpackAppendString(buf, "times")
+	if s.Times == nil {
+		buf = append(buf, 0xc0)
+	} else {
+		buf = msgpackAppendArrayHeader(buf, len(s.Times))
+		for _, t := range s.Times {
+			buf = msgpackAppendInt(buf, t.UnixNano())
+		}
+	}
+
+	return buf, nil
+}
+
+// UnmarshalMsgpack decodes a Snapshot encoded by MarshalMsgpack.
+// Unknown map keys are ignored.
+func (s *Snapshot) UnmarshalMsgpack(data []byte) error {
+	d := &msgpackDecoder{data: data}
+	n, err := d.mapHeader()
+	if err != nil {
+		return err
+	}
+
+	var retv Snapshot
+	for i := 0; i < n; i++ {
+		key, err := d.string()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "window":
+			window, err := d.int()
+			if err != nil {
+				return err
+			}
+			retv.Window = int(window)
+		case "values":
+			l, err := d.arrayHeader()
+			if err != nil {
+				return err
+			}
+			retv.Values = make([]float64, l)
+			for j := range retv.Values {
+				if retv.Values[j], err = d.float(); err != nil {
+					return err
+				}
+			}
+		case "times":
+			if d.nil() {
+				continue
+			}
+			l, err := d.arrayHeader()
+			if err != nil {
+				return err
+			}
+			retv.Times = make([]time.Time, l)
+			for j := range retv.Times {
+				ns, err := d.int()
+				if err != nil {
+					return err
+				}
+				retv.Times[j] = time.Unix(0, ns)
+			}
+		default:
+			if err := d.skip(0); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.pos != len(d.data) {
+		return errors.New("msgpack: trailing data")
+	}
+	*s = retv
+	return nil
+}
+
+func msgpackAppendString(buf []byte, v string) []byte {
+	// All strings written by this package are short enough for the fixstr format
+	buf = append(buf, 0xa0|byte(len(v)))
+	return append(buf, v...)
+}
+
+func msgpackAppendInt(buf []byte, v int64) []byte {
+	if v >= 0 && v <= 0x7f {
+		return append(buf, byte(v))
+	}
+	buf = append(buf, 0xd3)
+	return binary.BigEndian.AppendUint64(buf, uint64(v))
+}
+
+func msgpackAppendArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n <= 0x0f:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, 0xdc)
+		return binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, 0xdd)
+		return binary.BigEndian.AppendUint32(buf, uint32(n))
+	}
+}
+
+// msgpackDecoder decodes the subset of MessagePack needed to read a Snapshot.
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+// msgpackMaxDepth is the deepest that arrays and maps in skipped values may be nested,
+// so malicious data can't exhaust the stack.
+const msgpackMaxDepth = 32
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errMsgpackShort
+	}
+	retv := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return retv, nil
+}
+
+func (d *msgpackDecoder) byte() (byte, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// nil consumes a nil value and returns true, if the next value is nil.
+func (d *msgpackDecoder) nil() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == 0xc0 {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *msgpackDecoder) int() (int64, error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b >= 0xcc && b <= 0xcf:
+		v, err := d.uint(1 << (b - 0xcc))
+		if v > math.MaxInt64 {
+			return 0, errors.New("msgpack: integer overflow")
+		}
+		return int64(v), err
+	case b >= 0xd0 && b <= 0xd3:
+		size := 1 << (b - 0xd0)
+		v, err := d.uint(size)
+		if err != nil {
+			return 0, err
+		}
+		// sign-extend
+		shift := 64 - 8*size
+		return int64(v<<shift) >> shift, nil
+	}
+	return 0, fmt.Errorf("msgpack: expected integer, got type 0x%02x", b)
+}
+
+func (d *msgpackDecoder) float() (float64, error) {
+	if d.pos >= len(d.data) {
+		return 0, errMsgpackShort
+	}
+	switch b := d.data[d.pos]; b {
+	case 0xca:
+		d.pos++
+		v, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		d.pos++
+		v, err := d.uint(8)
+		return math.Float64frombits(v), err
+	}
+	// Integers are accepted as float values
+	v, err := d.int()
+	return float64(v), err
+}
+
+func (d *msgpackDecoder) string() (string, error) {
+	b, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	var n uint64
+	switch {
+	case b&0xe0 == 0xa0:
+		n = uint64(b & 0x1f)
+	case b >= 0xd9 && b <= 0xdb:
+		if n, err = d.uint(1 << (b - 0xd9)); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("msgpack: expected string, got type 0x%02x", b)
+	}
+	s, err := d.next(int(n))
+	return string(s), err
+}
+
+func (d *msgpackDecoder) arrayHeader() (int, error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case b&0xf0 == 0x90:
+		return int(b & 0x0f), nil
+	case b == 0xdc || b == 0xdd:
+		n, err := d.uint(2 << (b - 0xdc))
+		if err != nil {
+			return 0, err
+		}
+		// Each element takes at least one byte
+		if n > uint64(len(d.data)-d.pos) {
+			return 0, errMsgpackShort
+		}
+		return int(n), nil
+	}
+	return 0, fmt.Errorf("msgpack: expected array, got type 0x%02x", b)
+}
+
+func (d *msgpackDecoder) mapHeader() (int, error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case b&0xf0 == 0x80:
+		return int(b & 0x0f), nil
+	case b == 0xde || b == 0xdf:
+		n, err := d.uint(2 << (b - 0xde))
+		if err != nil {
+			return 0, err
+		}
+		// Each entry takes at least two bytes
+		if 2*n > uint64(len(d.data)-d.pos) {
+			return 0, errMsgpackShort
+		}
+		return int(n), nil
+	}
+	return 0, fmt.Errorf("msgpack: expected map, got type 0x%02x", b)
+}
+
+// skip consumes a single value of any type, nested depth arrays and maps deep.
+func (d *msgpackDecoder) skip(depth int) error {
+	if depth > msgpackMaxDepth {
+		return fmt.Errorf("msgpack: values nested more than %d deep", msgpackMaxDepth)
+	}
+	if d.pos >= len(d.data) {
+		return errMsgpackShort
+	}
+	b := d.data[d.pos]
+	switch {
+	case b <= 0x7f || b >= 0xe0 || b == 0xc0 || b == 0xc2 || b == 0xc3:
+		d.pos++
+		return nil
+	case b >= 0xcc && b <= 0xd3:
+		_, err := d.int()
+		return err
+	case b == 0xca || b == 0xcb:
+		_, err := d.float()
+		return err
+	case b&0xe0 == 0xa0 || (b >= 0xd9 && b <= 0xdb):
+		_, err := d.string()
+		return err
+	case b >= 0xc4 && b <= 0xc6: // bin
+		d.pos++
+		n, err := d.uint(1 << (b - 0xc4))
+		if err != nil {
+			return err
+		}
+		_, err = d.next(int(n))
+		return err
+	case b&0xf0 == 0x90 || b == 0xdc || b == 0xdd:
+		n, err := d.arrayHeader()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case b&0xf0 == 0x80 || b == 0xde || b == 0xdf:
+		n, err := d.mapHeader()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < 2*n; i++ {
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("msgpack: unsupported type 0x%02x", b)
+}
@@ -0,0 +1,94 @@
+package movingaverage
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSnapshotMsgpackRoundTrip(t *testing.T) {
+	now := time.Now()
+	in := Snapshot{
+		Window: 300,
+		Values: []float64{1.5, -2, math.Inf(1), 0},
+		Times:  []time.Time{now, now.Add(time.Second), now.Add(-time.Hour), time.Unix(0, 5)},
+	}
+	data, err := in.MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out Snapshot
+	if err := out.UnmarshalMsgpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Window != in.Window || !slices.Equal(out.Values, in.Values) {
+		t.Error(out)
+	}
+	if !slices.EqualFunc(out.Times, in.Times, time.Time.Equal) {
+		t.Error(out.Times)
+	}
+}
+
+func TestSnapshotMsgpackNilTimes(t *testing.T) {
+	in := Snapshot{Window: 3, Values: make([]float64, 20)}
+	data, err := in.MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Snapshot
+	if err := out.UnmarshalMsgpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Times != nil || len(out.Values) != 20 {
+		t.Error(out)
+	}
+}
+
+func TestSnapshotMsgpackUnknownKeys(t *testing.T) {
+	// {"extra": [1, "x", {"k": nil}], "window": 2, "values": [1.0]}
+	data := []byte{0x83,
+		0xa5, 'e', 'x', 't', 'r', 'a', 0x93, 0x01, 0xa1, 'x', 0x81, 0xa1, 'k', 0xc0,
+		0xa6, 'w', 'i', 'n', 'd', 'o', 'w', 0x02,
+		0xa6, 'v', 'a', 'l', 'u', 'e', 's', 0x91, 0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0,
+	}
+	var out Snapshot
+	if err := out.UnmarshalMsgpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Window != 2 || !slices.Equal(out.Values, []float64{1}) {
+		t.Error(out)
+	}
+}
+
+func TestSnapshotMsgpackNesting(t *testing.T) {
+	// {"extra": [[...[nil]...]], "window": 2}, with the given number of arrays
+	nested := func(depth int) []byte {
+		data := []byte{0x82, 0xa5, 'e', 'x', 't', 'r', 'a'}
+		data = append(data, bytes.Repeat([]byte{0x91}, depth)...)
+		return append(data, 0xc0, 0xa6, 'w', 'i', 'n', 'd', 'o', 'w', 0x02)
+	}
+	var out Snapshot
+	if err := out.UnmarshalMsgpack(nested(msgpackMaxDepth)); err != nil || out.Window != 2 {
+		t.Error(err, out)
+	}
+	if err := out.UnmarshalMsgpack(nested(msgpackMaxDepth + 1)); err == nil {
+		t.Error("expected error for deeply nested data")
+	}
+	if err := out.UnmarshalMsgpack(nested(10_000_000)); err == nil {
+		t.Error("expected error for deeply nested data")
+	}
+}
+
+func TestSnapshotMsgpackInvalid(t *testing.T) {
+	data, _ := Snapshot{Window: 3, Values: []float64{1, 2}}.MarshalMsgpack()
+	var out Snapshot
+	if err := out.UnmarshalMsgpack(data[:len(data)-3]); err == nil {
+		t.Error("expected error for truncated data")
+	}
+	if err := out.UnmarshalMsgpack(append(data, 0x00)); err == nil {
+		t.Error("expected error for trailing data")
+	}
+}
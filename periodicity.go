@@ -0,0 +1,45 @@
+package movingaverage
+
+import "github.com/montanaflynn/stats"
+
+func (ma *movingStats) Periodicity() (int, float64) {
+	return periodicity(ma.statValues())
+}
+
+// periodicity finds the dominant cycle length in the given chronological values, via their
+// autocorrelation: it returns the lag of the highest peak in the autocorrelation function
+// (a lag whose autocorrelation exceeds both neighbors'), among lags up to half the number of
+// values, and the autocorrelation at that lag. If there is no positive peak, 0 and 0.0 are returned.
+func periodicity(values stats.Float64Data) (int, float64) {
+	n := len(values)
+	if n < 4 {
+		return 0, 0.0
+	}
+	mean, _ := values.Mean()
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	if variance == 0 {
+		return 0, 0.0
+	}
+
+	// acf[l] is the (biased) autocorrelation at lag l; acf[0] is 1
+	maxLag := n / 2
+	acf := make([]float64, maxLag+2)
+	for l := range acf {
+		cov := 0.0
+		for i := 0; i+l < n; i++ {
+			cov += (values[i] - mean) * (values[i+l] - mean)
+		}
+		acf[l] = cov / variance
+	}
+
+	period, strength := 0, 0.0
+	for l := 2; l <= maxLag; l++ {
+		if acf[l] > acf[l-1] && acf[l] >= acf[l+1] && acf[l] > strength {
+			period, strength = l, acf[l]
+		}
+	}
+	return period, strength
+}
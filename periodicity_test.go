@@ -0,0 +1,35 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPeriodicity(t *testing.T) {
+	a := NewConcurrent(Options{Window: 60})
+	if period, strength := a.Periodicity(); period != 0 || strength != 0 {
+		t.Error(period, strength)
+	}
+
+	for i := 0; i < 60; i++ {
+		a.Add(math.Sin(2*math.Pi*float64(i)/12) + 0.1*math.Sin(float64(i)*1.7))
+	}
+	period, strength := a.Periodicity()
+	if period != 12 || strength < 0.5 || strength > 1 {
+		t.Error(period, strength)
+	}
+
+	// constant and monotonic series have no cycles
+	b := New(Options{Window: 20})
+	b.Add(1, 1, 1, 1, 1, 1)
+	if period, _ := b.Periodicity(); period != 0 {
+		t.Error(period)
+	}
+	c := New(Options{Window: 20})
+	for i := 0; i < 20; i++ {
+		c.Add(float64(i))
+	}
+	if period, _ := c.Periodicity(); period != 0 {
+		t.Error(period)
+	}
+}
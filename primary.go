@@ -0,0 +1,85 @@
+package movingaverage
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/montanaflynn/stats"
+)
+
+// PrimaryStat selects the statistic returned by MovingStats.Value, so code consuming an
+// instance can treat it generically while its smoothing semantics are chosen in config.
+type PrimaryStat string
+
+const (
+	// PrimaryMean is the average of the values, as returned by Avg. It is the default.
+	PrimaryMean PrimaryStat = "mean"
+	// PrimaryMedian is the median of the values, as returned by Median.
+	PrimaryMedian PrimaryStat = "median"
+	// PrimaryTrimmedMean is the average of the values after discarding Options.TrimFraction
+	// of them from each end of their sorted order.
+	PrimaryTrimmedMean PrimaryStat = "trimmed_mean"
+	// PrimaryEMA is the exponential moving average of the values, oldest first, with
+	// smoothing factor Options.EMAAlpha.
+	PrimaryEMA PrimaryStat = "ema"
+)
+
+// defaultTrimFraction is the fraction of values trimmed from each end by PrimaryTrimmedMean
+// if Options.TrimFraction is zero.
+const defaultTrimFraction = 0.1
+
+// validate returns an error if the PrimaryStat is not one of the defined values.
+// The empty PrimaryStat is valid, meaning PrimaryMean.
+func (p PrimaryStat) validate() error {
+	switch p {
+	case "", PrimaryMean, PrimaryMedian, PrimaryTrimmedMean, PrimaryEMA:
+		return nil
+	}
+	return fmt.Errorf("unknown primary stat %q", string(p))
+}
+
+func (ma *movingStats) Value() float64 {
+	switch ma.primaryStat {
+	case PrimaryMedian:
+		return ma.Median()
+	case PrimaryTrimmedMean:
+		return trimmedMean(ma.statValues(), ma.trimFraction)
+	case PrimaryEMA:
+		return ema(ma.statValues(), ma.emaAlpha)
+	default:
+		return ma.Avg()
+	}
+}
+
+// trimmedMean returns the average of the given values after discarding the given fraction
+// (0 to 0.5) of them from each end of their sorted order, or 0.0 if there are no values.
+// At least one value is always kept.
+func trimmedMean(values stats.Float64Data, fraction float64) float64 {
+	if len(values) == 0 {
+		return 0.0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	trim := int(math.Floor(float64(len(sorted)) * fraction))
+	trim = min(trim, (len(sorted)-1)/2)
+	mean, _ := sorted[trim : len(sorted)-trim].Mean()
+	return mean
+}
+
+// ema returns the exponential moving average of the given values, oldest first, seeded with
+// the oldest value. If alpha is not in (0, 1], 2/(n+1) is used, where n is the number of
+// values. If there are no values, 0.0 is returned.
+func ema(values stats.Float64Data, alpha float64) float64 {
+	if len(values) == 0 {
+		return 0.0
+	}
+	if alpha <= 0 || alpha > 1 {
+		alpha = 2 / float64(len(values)+1)
+	}
+	retv := values[0]
+	for _, v := range values[1:] {
+		retv += alpha * (v - retv)
+	}
+	return retv
+}
@@ -0,0 +1,41 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 100}
+	for _, tc := range []struct {
+		opts Options
+		want float64
+	}{
+		{Options{}, 22},
+		{Options{PrimaryStat: PrimaryMean}, 22},
+		{Options{PrimaryStat: PrimaryMedian}, 3},
+		// 10% of 5 values rounds down to none trimmed
+		{Options{PrimaryStat: PrimaryTrimmedMean}, 22},
+		{Options{PrimaryStat: PrimaryTrimmedMean, TrimFraction: 0.2}, 3},
+		{Options{PrimaryStat: PrimaryTrimmedMean, TrimFraction: 0.5}, 3},
+		{Options{PrimaryStat: PrimaryEMA, EMAAlpha: 1}, 100},
+		{Options{PrimaryStat: PrimaryEMA, EMAAlpha: 0.5}, 51.5625},
+	} {
+		tc.opts.Window = 5
+		a := NewConcurrent(tc.opts)
+		if a.Value() != 0 {
+			t.Error(tc.opts.PrimaryStat, a.Value())
+		}
+		a.Add(values...)
+		if math.Abs(a.Value()-tc.want) > 1e-9 {
+			t.Error(tc.opts.PrimaryStat, a.Value(), tc.want)
+		}
+	}
+}
+
+func TestEMADefaultAlpha(t *testing.T) {
+	// alpha = 2/(3+1) = 0.5
+	if got := ema([]float64{2, 4, 8}, 0); got != 5.5 {
+		t.Error(got)
+	}
+}
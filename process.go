@@ -0,0 +1,110 @@
+package movingaverage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Names of the windows kept by a ProcessSampler.
+const (
+	// ProcessCPUPercent is the window of the process's CPU usage (user plus system) between
+	// consecutive samples, as a percentage of one CPU; a process busy on two CPUs reports 200.
+	ProcessCPUPercent = "cpu_percent"
+	// ProcessRSSBytes is the window of the process's resident set size, in bytes.
+	ProcessRSSBytes = "rss_bytes"
+)
+
+// ErrProcessStatsUnsupported is returned by ProcessSampler.Sample on platforms where
+// reading the process's CPU usage and resident set size is not supported.
+var ErrProcessStatsUnsupported = errors.New("movingaverage: process stats are not supported on this platform")
+
+// ProcessSampler periodically reads the current process's CPU usage and resident set size
+// into a set of named, concurrency-safe MovingStats instances, for lightweight
+// self-monitoring of daemons which don't run a metrics agent.
+//
+// ProcessSampler is supported on Linux. On other platforms, Sample returns ErrProcessStatsUnsupported.
+// ProcessSampler is safe for concurrent use by multiple goroutines.
+type ProcessSampler struct {
+	interval time.Duration
+	cpu      MovingStats
+	rss      MovingStats
+	lastCPU  time.Duration
+	lastWall time.Time
+	now      func() time.Time
+	mux      sync.Mutex
+}
+
+// NewProcessSampler returns a new ProcessSampler whose windows are created with the given
+// options, and which samples every interval once Run is called.
+func NewProcessSampler(opts Options, interval time.Duration) *ProcessSampler {
+	return &ProcessSampler{
+		interval: interval,
+		cpu:      NewConcurrent(opts),
+		rss:      NewConcurrent(opts),
+		now:      time.Now,
+	}
+}
+
+// Get returns the window with the given name (one of the Process* constants),
+// or nil if there is no such window.
+func (s *ProcessSampler) Get(name string) MovingStats {
+	switch name {
+	case ProcessCPUPercent:
+		return s.cpu
+	case ProcessRSSBytes:
+		return s.rss
+	}
+	return nil
+}
+
+// Register adds the sampler's windows to the given Registry, each under its name
+// prefixed by prefix (e.g. "process_").
+func (s *ProcessSampler) Register(reg *Registry, prefix string) {
+	reg.Register(prefix+ProcessCPUPercent, s.cpu, nil)
+	reg.Register(prefix+ProcessRSSBytes, s.rss, nil)
+}
+
+// Sample immediately reads the process's stats into the sampler's windows.
+// CPU usage is measured between samples, so the first sample adds only the resident set size.
+func (s *ProcessSampler) Sample() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	cpu, rss, err := readProcessStats()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	if !s.lastWall.IsZero() {
+		if wall := now.Sub(s.lastWall); wall > 0 {
+			s.cpu.Add(100 * float64(cpu-s.lastCPU) / float64(wall))
+		}
+	}
+	s.lastCPU = cpu
+	s.lastWall = now
+	s.rss.Add(float64(rss))
+	return nil
+}
+
+// Run calls Sample every interval until the given context is canceled or Sample returns an error.
+// It returns the context's error or the error returned by Sample, or an error immediately if
+// the interval isn't positive.
+func (s *ProcessSampler) Run(ctx context.Context) error {
+	if s.interval <= 0 {
+		return errNonPositiveInterval
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Sample(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build linux
+
+package movingaverage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// readProcessStats returns the CPU time (user plus system) used by the current process
+// so far, and its current resident set size in bytes.
+func readProcessStats() (time.Duration, uint64, error) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0, 0, err
+	}
+	cpu := time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+
+	// /proc/self/statm holds sizes in pages: total program size, then resident set size, ...
+	statm, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := bytes.Fields(statm)
+	if len(fields) < 2 {
+		return 0, 0, fmt.Errorf("movingaverage: unexpected /proc/self/statm contents: %q", statm)
+	}
+	pages, err := strconv.ParseUint(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cpu, pages * uint64(os.Getpagesize()), nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package movingaverage
+
+import "time"
+
+func readProcessStats() (time.Duration, uint64, error) {
+	return 0, 0, ErrProcessStatsUnsupported
+}
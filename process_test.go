@@ -0,0 +1,36 @@
+//go:build linux
+
+package movingaverage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcessSampler(t *testing.T) {
+	s := NewProcessSampler(Options{Window: 10}, 0)
+	if err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Get(ProcessCPUPercent).Count() != 0 {
+		t.Error(s.Get(ProcessCPUPercent).Values())
+	}
+
+	// Burn some CPU
+	deadline := time.Now().Add(20 * time.Millisecond)
+	for time.Now().Before(deadline) {
+	}
+	if err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Get(ProcessCPUPercent).Count() != 1 || s.Get(ProcessCPUPercent).Max() <= 0 {
+		t.Error(s.Get(ProcessCPUPercent).Values())
+	}
+	if s.Get(ProcessRSSBytes).Count() != 2 || s.Get(ProcessRSSBytes).Min() <= 0 {
+		t.Error(s.Get(ProcessRSSBytes).Values())
+	}
+	if s.Get("nonexistent") != nil {
+		t.Error("expected nil")
+	}
+}
@@ -0,0 +1,77 @@
+package movingaverage
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSummaryQuantiles are the quantiles written by WritePrometheusSummary if none are given.
+var DefaultSummaryQuantiles = []float64{0.5, 0.9, 0.99}
+
+// WritePrometheusSummary writes the values in ms to w as a Prometheus summary with the
+// given metric name, in the Prometheus text exposition format: one sample per quantile,
+// followed by the sum and count of the values, so scrapers ingest it as a native summary.
+//
+// Quantiles must be in [0, 1], per Prometheus conventions; if none are given,
+// DefaultSummaryQuantiles are written. They and the sum are calculated by the instance's
+// Percentile (or Min, for 0) and Sum, from a single consistent view of the window, so they honor
+// Options.QuantileInterpolation and Options.MinSamples. If the window is empty, the
+// quantiles are written as NaN, as the Prometheus client libraries do.
+//
+// Unlike those written by the Prometheus client libraries, the _sum and _count samples are
+// the window's sum and count, which fall as values leave the window, not monotonic
+// counters. Don't apply rate() or increase() to them; query them as gauges instead, e.g.
+// <name>_sum / <name>_count for the window's average.
+//
+// The values of instances whose Unit is UnitNanoseconds, e.g. the windows of DurationStats,
+// are written in seconds, per Prometheus conventions.
+func WritePrometheusSummary(w io.Writer, ms MovingStats, name string, quantiles ...float64) error {
+	if len(quantiles) == 0 {
+		quantiles = DefaultSummaryQuantiles
+	}
+	for _, q := range quantiles {
+		if !(q >= 0 && q <= 1) {
+			return fmt.Errorf("quantile %g out of range [0, 1]", q)
+		}
+	}
+
+	results := make([]float64, len(quantiles))
+	var sum float64
+	var count int
+	ms.DoLocked(func(view ReadOnlyView) {
+		count = view.Count()
+		for i, q := range quantiles {
+			switch {
+			case count == 0:
+				results[i] = math.NaN()
+			case q == 0:
+				// Percentile takes (0, 100]; the 0 quantile is the minimum
+				results[i] = view.Min()
+			default:
+				results[i] = view.Percentile(q * 100)
+			}
+		}
+		sum = view.Sum()
+	})
+
+	if ms.Unit() == UnitNanoseconds {
+		for i := range results {
+			results[i] /= float64(time.Second)
+		}
+		sum /= float64(time.Second)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# TYPE %s summary\n", name)
+	for i, q := range quantiles {
+		fmt.Fprintf(&sb, "%s{quantile=\"%s\"} %g\n", name, strconv.FormatFloat(q, 'g', -1, 64), results[i])
+	}
+	fmt.Fprintf(&sb, "%s_sum %g\n", name, sum)
+	fmt.Fprintf(&sb, "%s_count %d\n", name, count)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,83 @@
+package movingaverage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheusSummary(t *testing.T) {
+	ms := New(Options{Window: 10})
+	ms.Add(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+
+	var sb strings.Builder
+	if err := WritePrometheusSummary(&sb, ms, "queue_depth", 0, 0.25, 0.5, 1); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# TYPE queue_depth summary
+queue_depth{quantile="0"} 1
+queue_depth{quantile="0.25"} 3
+queue_depth{quantile="0.5"} 5
+queue_depth{quantile="1"} 10
+queue_depth_sum 55
+queue_depth_count 10
+`
+	if sb.String() != expected {
+		t.Error(sb.String())
+	}
+}
+
+func TestWritePrometheusSummaryOptions(t *testing.T) {
+	ms := NewConcurrent(Options{Window: 10, MinSamples: 2, QuantileInterpolation: QuantileLinear})
+	ms.Add(1)
+
+	// too few samples for the quantiles and sum, but the count is written
+	var sb strings.Builder
+	if err := WritePrometheusSummary(&sb, ms, "x", 0.5); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# TYPE x summary
+x{quantile="0.5"} 0
+x_sum 0
+x_count 1
+`
+	if sb.String() != expected {
+		t.Error(sb.String())
+	}
+
+	ms.Add(2, 3, 4)
+	sb.Reset()
+	if err := WritePrometheusSummary(&sb, ms, "x", 0.5); err != nil {
+		t.Fatal(err)
+	}
+	expected = `# TYPE x summary
+x{quantile="0.5"} 2.5
+x_sum 10
+x_count 4
+`
+	if sb.String() != expected || ms.Percentile(50) != 2.5 {
+		t.Error(sb.String())
+	}
+}
+
+func TestWritePrometheusSummaryDefaults(t *testing.T) {
+	ms := NewConcurrent(Options{Window: 10})
+
+	var sb strings.Builder
+	if err := WritePrometheusSummary(&sb, ms, "empty"); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# TYPE empty summary
+empty{quantile="0.5"} NaN
+empty{quantile="0.9"} NaN
+empty{quantile="0.99"} NaN
+empty_sum 0
+empty_count 0
+`
+	if sb.String() != expected {
+		t.Error(sb.String())
+	}
+
+	if err := WritePrometheusSummary(&sb, ms, "bad", 1.5); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,116 @@
+package movingaverage
+
+import (
+	"math"
+	"slices"
+
+	"github.com/montanaflynn/stats"
+)
+
+// QuantileInterpolation selects how percentiles (including the median) are calculated
+// when the requested percentile falls between two values. The non-default methods match
+// the methods of the same names in NumPy's percentile function.
+type QuantileInterpolation int
+
+const (
+	// QuantileDefault keeps this package's default behavior: Median averages the two middle
+	// values, and other percentiles use the nearest-rank method.
+	QuantileDefault QuantileInterpolation = iota
+	// QuantileLinear interpolates linearly between the two nearest values.
+	QuantileLinear
+	// QuantileLower returns the lower of the two nearest values.
+	QuantileLower
+	// QuantileHigher returns the higher of the two nearest values.
+	QuantileHigher
+	// QuantileNearest returns the nearer of the two nearest values, or the one with an
+	// even index if they are equally near.
+	QuantileNearest
+	// QuantileMidpoint returns the average of the two nearest values.
+	QuantileMidpoint
+)
+
+// percentile returns the given percentile of the given values, which need not be sorted,
+// using the given interpolation method. For QuantileDefault, p must be in (0, 100] and the
+// nearest-rank method is used; otherwise, p must be in [0, 100].
+func percentile(values stats.Float64Data, p float64, method QuantileInterpolation) (float64, error) {
+	if len(values) == 0 {
+		return math.NaN(), stats.ErrEmptyInput
+	}
+	if p < 0 || p > 100 || math.IsNaN(p) || (p == 0 && method == QuantileDefault) {
+		return math.NaN(), stats.ErrBounds
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return percentileSorted(sorted, p, method), nil
+}
+
+// percentileSorted is like percentile, but requires the values to be sorted and non-empty,
+// and p to be in range.
+func percentileSorted(sorted stats.Float64Data, p float64, method QuantileInterpolation) float64 {
+	if method == QuantileDefault {
+		// Nearest rank, as in stats.PercentileNearestRank
+		rank := int(math.Ceil(float64(len(sorted)) * p / 100))
+		return sorted[max(rank, 1)-1]
+	}
+
+	h := float64(len(sorted)-1) * p / 100
+	lo, hi := sorted[int(math.Floor(h))], sorted[int(math.Ceil(h))]
+	switch method {
+	case QuantileLower:
+		return lo
+	case QuantileHigher:
+		return hi
+	case QuantileNearest:
+		return sorted[int(math.RoundToEven(h))]
+	case QuantileMidpoint:
+		return (lo + hi) / 2
+	default:
+		return lo + (h-math.Floor(h))*(hi-lo)
+	}
+}
+
+// quantileTracker maintains a sorted copy of an instance's values, so the quantiles given by
+// Options.TrackQuantiles can be read without sorting the window.
+type quantileTracker struct {
+	sorted stats.Float64Data
+}
+
+func (q *quantileTracker) add(v float64) {
+	i, _ := slices.BinarySearch(q.sorted, v)
+	q.sorted = slices.Insert(q.sorted, i, v)
+}
+
+func (q *quantileTracker) remove(v float64) {
+	if i, found := slices.BinarySearch(q.sorted, v); found {
+		q.sorted = slices.Delete(q.sorted, i, i+1)
+	}
+}
+
+func (ma *movingStats) Quantiles() []float64 {
+	if len(ma.trackQuantiles) == 0 {
+		return nil
+	}
+	retv := make([]float64, len(ma.trackQuantiles))
+	values := ma.statValues()
+	if len(values) == 0 {
+		return retv
+	}
+
+	// The tracked values include any which have expired since the last Add, and views
+	// created by LastN have none; in either case, fall back to sorting the live values.
+	var sorted stats.Float64Data
+	if ma.quantiles != nil && len(ma.quantiles.sorted) == len(values) {
+		sorted = ma.quantiles.sorted
+	} else {
+		sorted = slices.Clone(values)
+		slices.Sort(sorted)
+	}
+
+	for i, q := range ma.trackQuantiles {
+		if q >= 0 && q <= 1 {
+			retv[i] = percentileSorted(sorted, q*100, ma.interpolation)
+		}
+	}
+	return retv
+}
@@ -0,0 +1,113 @@
+package movingaverage
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+func TestPercentileInterpolation(t *testing.T) {
+	values := stats.Float64Data{4, 1, 3, 2}
+	for _, tc := range []struct {
+		method QuantileInterpolation
+		p      float64
+		want   float64
+	}{
+		// expected values match numpy.percentile([1, 2, 3, 4], p, method=...)
+		{QuantileLinear, 40, 2.2},
+		{QuantileLower, 40, 2},
+		{QuantileHigher, 40, 3},
+		{QuantileNearest, 40, 2},
+		{QuantileMidpoint, 40, 2.5},
+		{QuantileNearest, 50, 3},
+		{QuantileLinear, 0, 1},
+		{QuantileLinear, 100, 4},
+		{QuantileDefault, 40, 2},
+	} {
+		got, err := percentile(values, tc.p, tc.method)
+		if err != nil || math.Abs(got-tc.want) > 1e-9 {
+			t.Error(tc.method, tc.p, got, err)
+		}
+	}
+
+	if _, err := percentile(values, 101, QuantileLinear); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := percentile(nil, 50, QuantileLinear); err == nil {
+		t.Error("expected error")
+	}
+	// the input must not be reordered
+	if values[0] != 4 {
+		t.Error(values)
+	}
+}
+
+func TestQuantileInterpolationOption(t *testing.T) {
+	a := New(Options{Window: 4, QuantileInterpolation: QuantileLower})
+	a.Add(1, 2, 3, 4)
+	if a.Median() != 2 {
+		t.Error(a.Median())
+	}
+
+	lt := NewLatencyTracker(Options{Window: 4, QuantileInterpolation: QuantileLinear})
+	lt.Observe(1*time.Second, 2*time.Second, 3*time.Second, 4*time.Second)
+	if lt.Percentile(40) != 2200*time.Millisecond {
+		t.Error(lt.Percentile(40))
+	}
+	if lt.Median() != 2500*time.Millisecond {
+		t.Error(lt.Median())
+	}
+}
+
+func TestTrackQuantiles(t *testing.T) {
+	targets := []float64{0, 0.5, 0.9, 0.99, 1}
+	a := New(Options{Window: 50, TrackQuantiles: targets, QuantileInterpolation: QuantileLinear})
+	if !slices.Equal(a.Quantiles(), []float64{0, 0, 0, 0, 0}) {
+		t.Error(a.Quantiles())
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a.Add(math.Round(rng.NormFloat64() * 10))
+		got := a.Quantiles()
+		for j, q := range targets {
+			want, _ := percentile(a.Values(), q*100, QuantileLinear)
+			if got[j] != want {
+				t.Fatalf("add %d: quantile %g = %g, expected %g", i, q, got[j], want)
+			}
+		}
+	}
+
+	if New(Options{Window: 3}).Quantiles() != nil {
+		t.Error("expected nil without tracked quantiles")
+	}
+}
+
+func TestTrackQuantilesExpiry(t *testing.T) {
+	now := time.Now()
+	a := newMovingStats(Options{Window: 10, MaxAge: time.Minute, TrackQuantiles: []float64{0.5, 2}})
+	a.now = func() time.Time { return now }
+	a.Add(100, 200)
+	now = now.Add(45 * time.Second)
+	a.Add(1, 2, 3)
+	if !slices.Equal(a.Quantiles(), []float64{3, 0}) {
+		t.Error(a.Quantiles())
+	}
+
+	// the oldest values expire before the tracked values are updated
+	now = now.Add(30 * time.Second)
+	if !slices.Equal(a.Quantiles(), []float64{2, 0}) {
+		t.Error(a.Quantiles())
+	}
+	if v := a.LastN(2); !slices.Equal(v.Quantiles(), []float64{2, 0}) {
+		t.Error(v.Quantiles())
+	}
+	a.Add(4)
+	if !slices.Equal(a.Quantiles(), []float64{2, 0}) || len(a.quantiles.sorted) != 4 {
+		t.Error(a.Quantiles(), a.quantiles.sorted)
+	}
+}
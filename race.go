@@ -0,0 +1,491 @@
+package movingaverage
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+// raceDetectingStats wraps a MovingStats instance which is not safe for concurrent use,
+// panicking if a method which modifies the instance overlaps any other method call.
+// Concurrent calls to methods which only read the instance are allowed.
+type raceDetectingStats struct {
+	ma MovingStats
+	// state is -1 while a modifying method is running, or the number of running reading methods.
+	// It is shared with views created by LastN.
+	state *atomic.Int32
+}
+
+const raceDetectedMessage = "movingaverage: concurrent use of a MovingStats instance created by New; use NewConcurrent instead"
+
+func (r *raceDetectingStats) enterRead() {
+	for {
+		n := r.state.Load()
+		if n < 0 {
+			panic(raceDetectedMessage)
+		}
+		if r.state.CompareAndSwap(n, n+1) {
+			return
+		}
+	}
+}
+
+func (r *raceDetectingStats) exitRead() {
+	r.state.Add(-1)
+}
+
+func (r *raceDetectingStats) enterWrite() {
+	if !r.state.CompareAndSwap(0, -1) {
+		panic(raceDetectedMessage)
+	}
+}
+
+func (r *raceDetectingStats) exitWrite() {
+	r.state.Store(0)
+}
+
+func (r *raceDetectingStats) Add(values ...float64) {
+	r.enterWrite()
+	defer r.exitWrite()
+	r.ma.Add(values...)
+}
+
+func (r *raceDetectingStats) AddAccepted(values ...float64) int {
+	r.enterWrite()
+	defer r.exitWrite()
+	return r.ma.AddAccepted(values...)
+}
+
+func (r *raceDetectingStats) Window() int {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Window()
+}
+
+func (r *raceDetectingStats) SlotsFilled() bool {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.SlotsFilled()
+}
+
+func (r *raceDetectingStats) FillRatio() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.FillRatio()
+}
+
+func (r *raceDetectingStats) IngestRate() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.IngestRate()
+}
+
+func (r *raceDetectingStats) OldestAge() time.Duration {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.OldestAge()
+}
+
+func (r *raceDetectingStats) NewestAge() time.Duration {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.NewestAge()
+}
+
+func (r *raceDetectingStats) Span() time.Duration {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Span()
+}
+
+func (r *raceDetectingStats) Values() stats.Float64Data {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Values()
+}
+
+func (r *raceDetectingStats) ValuesWithTimes() []TimedValue {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.ValuesWithTimes()
+}
+
+func (r *raceDetectingStats) ValuesDownsampled(maxPoints int) stats.Float64Data {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.ValuesDownsampled(maxPoints)
+}
+
+// SortedValues is treated as modifying the instance, since it may populate the
+// underlying instance's sorted values cache.
+func (r *raceDetectingStats) SortedValues() stats.Float64Data {
+	r.enterWrite()
+	defer r.exitWrite()
+	return r.ma.SortedValues()
+}
+
+func (r *raceDetectingStats) Count() int {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Count()
+}
+
+func (r *raceDetectingStats) CountAbove(t float64) int {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.CountAbove(t)
+}
+
+func (r *raceDetectingStats) CountBelow(t float64) int {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.CountBelow(t)
+}
+
+func (r *raceDetectingStats) Avg() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Avg()
+}
+
+func (r *raceDetectingStats) Sum() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Sum()
+}
+
+func (r *raceDetectingStats) SumOfSquares() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.SumOfSquares()
+}
+
+func (r *raceDetectingStats) Product() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Product()
+}
+
+func (r *raceDetectingStats) SumLast(k int) float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.SumLast(k)
+}
+
+func (r *raceDetectingStats) AvgLast(k int) float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.AvgLast(k)
+}
+
+func (r *raceDetectingStats) TrendPct() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.TrendPct()
+}
+
+func (r *raceDetectingStats) Median() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Median()
+}
+
+func (r *raceDetectingStats) MedianAbsoluteDeviation() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.MedianAbsoluteDeviation()
+}
+
+func (r *raceDetectingStats) Percentile(p float64) float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Percentile(p)
+}
+
+func (r *raceDetectingStats) PercentileSummary(ps ...float64) map[float64]float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.PercentileSummary(ps...)
+}
+
+func (r *raceDetectingStats) IQR() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.IQR()
+}
+
+func (r *raceDetectingStats) PercentileRank(x float64) float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.PercentileRank(x)
+}
+
+func (r *raceDetectingStats) CDF(x float64) float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.CDF(x)
+}
+
+func (r *raceDetectingStats) Quantiles() []float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Quantiles()
+}
+
+func (r *raceDetectingStats) Min() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Min()
+}
+
+func (r *raceDetectingStats) Value() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Value()
+}
+
+func (r *raceDetectingStats) EWVariance() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.EWVariance()
+}
+
+func (r *raceDetectingStats) EWStdDev() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.EWStdDev()
+}
+
+func (r *raceDetectingStats) Max() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Max()
+}
+
+func (r *raceDetectingStats) MinMax() (float64, float64) {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.MinMax()
+}
+
+func (r *raceDetectingStats) Range() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Range()
+}
+
+func (r *raceDetectingStats) RMS() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.RMS()
+}
+
+func (r *raceDetectingStats) Variance() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Variance()
+}
+
+func (r *raceDetectingStats) StdDev() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.StdDev()
+}
+
+func (r *raceDetectingStats) CV() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.CV()
+}
+
+func (r *raceDetectingStats) HealthScore() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.HealthScore()
+}
+
+func (r *raceDetectingStats) ZScore() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.ZScore()
+}
+
+func (r *raceDetectingStats) ZScoreOf(x float64) float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.ZScoreOf(x)
+}
+
+func (r *raceDetectingStats) Skewness() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Skewness()
+}
+
+func (r *raceDetectingStats) Kurtosis() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Kurtosis()
+}
+
+func (r *raceDetectingStats) GeometricMean() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.GeometricMean()
+}
+
+func (r *raceDetectingStats) HarmonicMean() float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.HarmonicMean()
+}
+
+func (r *raceDetectingStats) TrimmedMean(fraction float64) float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.TrimmedMean(fraction)
+}
+
+func (r *raceDetectingStats) Summary() Summary {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Summary()
+}
+
+func (r *raceDetectingStats) Unit() Unit {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Unit()
+}
+
+func (r *raceDetectingStats) FormatSummary() string {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.FormatSummary()
+}
+
+func (r *raceDetectingStats) String() string {
+	return r.FormatSummary()
+}
+
+func (r *raceDetectingStats) TopK(k int) stats.Float64Data {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.TopK(k)
+}
+
+func (r *raceDetectingStats) BottomK(k int) stats.Float64Data {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.BottomK(k)
+}
+
+func (r *raceDetectingStats) Frequencies() map[float64]int {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Frequencies()
+}
+
+func (r *raceDetectingStats) Mode() []float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Mode()
+}
+
+func (r *raceDetectingStats) Periodicity() (int, float64) {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Periodicity()
+}
+
+func (r *raceDetectingStats) Compute(kinds ...StatKind) map[StatKind]float64 {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Compute(kinds...)
+}
+
+func (r *raceDetectingStats) LastN(k int) ReadOnlyView {
+	r.enterRead()
+	defer r.exitRead()
+	return readOnlyView{&raceDetectingStats{ma: r.ma.(*movingStats).lastN(k), state: r.state}}
+}
+
+func (r *raceDetectingStats) Snapshot() Snapshot {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.Snapshot()
+}
+
+func (r *raceDetectingStats) Restore(s Snapshot) {
+	r.enterWrite()
+	defer r.exitWrite()
+	r.ma.Restore(s)
+}
+
+func (r *raceDetectingStats) ApplyOptions(opts Options) {
+	r.enterWrite()
+	defer r.exitWrite()
+	r.ma.ApplyOptions(opts)
+}
+
+func (r *raceDetectingStats) WriteTo(w io.Writer) (int64, error) {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.WriteTo(w)
+}
+
+func (r *raceDetectingStats) ReadFrom(rd io.Reader) (int64, error) {
+	s, n, err := readBinarySnapshot(rd)
+	if err != nil {
+		return n, err
+	}
+	r.Restore(s)
+	return n, nil
+}
+
+func (r *raceDetectingStats) UnsafeDoStat(f func(stats.Float64Data) (float64, error)) (float64, error) {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.UnsafeDoStat(f)
+}
+
+func (r *raceDetectingStats) UnsafeDo(f func(stats.Float64Data) error) error {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.UnsafeDo(f)
+}
+
+func (r *raceDetectingStats) DoStatCtx(ctx context.Context, f func(context.Context, stats.Float64Data) (float64, error)) (float64, error) {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.DoStatCtx(ctx, f)
+}
+
+func (r *raceDetectingStats) DoCtx(ctx context.Context, f func(context.Context, stats.Float64Data) error) error {
+	r.enterRead()
+	defer r.exitRead()
+	return r.ma.DoCtx(ctx, f)
+}
+
+func (r *raceDetectingStats) DoLocked(f func(ReadOnlyView)) {
+	r.enterRead()
+	defer r.exitRead()
+	r.ma.DoLocked(f)
+}
+
+// BorrowValues counts as a running read until release is called.
+func (r *raceDetectingStats) BorrowValues() (stats.Float64Data, func()) {
+	r.enterRead()
+	values, release := r.ma.BorrowValues()
+	var once sync.Once
+	return values, func() {
+		once.Do(func() {
+			release()
+			r.exitRead()
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package movingaverage
+
+import (
+	"testing"
+
+	"github.com/montanaflynn/stats"
+)
+
+func expectPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	f()
+}
+
+func TestDetectRaces(t *testing.T) {
+	ms := New(Options{Window: 3, DetectRaces: true})
+	ms.Add(1, 2, 3)
+	if ms.Avg() != 2 {
+		t.Error(ms.Avg())
+	}
+
+	// Overlapping reads are fine
+	values, release := ms.BorrowValues()
+	if ms.Count() != 3 || len(values) != 3 {
+		t.Error(ms.Count(), values)
+	}
+
+	// A write overlapping a read is not
+	expectPanic(t, func() { ms.Add(4) })
+	release()
+	release()
+	ms.Add(4)
+
+	expectPanic(t, func() {
+		_ = ms.UnsafeDo(func(stats.Float64Data) error {
+			_ = ms.SortedValues()
+			return nil
+		})
+	})
+}
+
+func TestDetectRacesConcurrent(t *testing.T) {
+	ms := NewConcurrent(Options{Window: 3, DetectRaces: true})
+	values, release := ms.BorrowValues()
+	defer release()
+	if ms.Count() != 0 || len(values) != 0 {
+		t.Error(ms.Count(), values)
+	}
+}
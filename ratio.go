@@ -0,0 +1,120 @@
+package movingaverage
+
+import (
+	"math"
+
+	"github.com/montanaflynn/stats"
+)
+
+// DivideByZeroPolicy determines how a Ratio handles zero denominators.
+type DivideByZeroPolicy int
+
+const (
+	// DivideByZeroSkip omits ratios with a zero denominator from the window of ratios.
+	// A rolling ratio whose denominators sum to zero is reported as 0.0.
+	DivideByZeroSkip DivideByZeroPolicy = iota
+
+	// DivideByZeroZero records ratios with a zero denominator as 0.0.
+	// A rolling ratio whose denominators sum to zero is reported as 0.0.
+	DivideByZeroZero
+
+	// DivideByZeroNaN records ratios with a zero denominator as NaN.
+	// A rolling ratio whose denominators sum to zero is reported as NaN.
+	DivideByZeroNaN
+)
+
+// Ratio tracks the ratio between two series (e.g. error count / request count per
+// interval) over a moving window.
+//
+// Each call to Add records one numerator/denominator pair. The rolling ratio
+// (the sum of the numerators in the window divided by the sum of the denominators)
+// is available via Value, and stats over the individual pairs' ratios are available
+// via Stats.
+//
+// Ratio is not safe for concurrent use by multiple goroutines.
+type Ratio struct {
+	numerators   MovingStats
+	denominators MovingStats
+	ratios       MovingStats
+	policy       DivideByZeroPolicy
+}
+
+// NewRatio returns a new Ratio with the given options and divide-by-zero policy.
+//
+// The IgnoreNanValues and IgnoreInfValues options apply to the window of ratios;
+// numerators and denominators are always recorded, so they stay aligned. The Window, MaxAge,
+// and Eviction options apply to all three windows, so a time-based Ratio reports the ratio
+// over the last MaxAge. Eviction policies are applied to each window's own values, so only
+// those which don't depend on the values (e.g. MaxAgeEviction and MaxCountEviction) keep
+// the numerators and denominators aligned.
+func NewRatio(opts Options, policy DivideByZeroPolicy) *Ratio {
+	windowOpts := Options{Window: opts.Window, MaxAge: opts.MaxAge, Eviction: opts.Eviction}
+	return &Ratio{
+		numerators:   New(windowOpts),
+		denominators: New(windowOpts),
+		ratios:       New(opts),
+		policy:       policy,
+	}
+}
+
+// Add records a numerator/denominator pair.
+func (r *Ratio) Add(numerator, denominator float64) {
+	r.numerators.Add(numerator)
+	r.denominators.Add(denominator)
+	if ratio, ok := r.divide(numerator, denominator); ok {
+		r.ratios.Add(ratio)
+	}
+}
+
+// Value returns the rolling ratio: the sum of the numerators in the window divided
+// by the sum of the denominators in the window.
+// If no values have been added, 0.0 is returned.
+func (r *Ratio) Value() float64 {
+	num, err := r.numerators.UnsafeDoStat(stats.Sum)
+	if err != nil {
+		return 0.0
+	}
+	den, err := r.denominators.UnsafeDoStat(stats.Sum)
+	if err != nil {
+		return 0.0
+	}
+	retv, ok := r.divide(num, den)
+	if !ok {
+		return 0.0
+	}
+	return retv
+}
+
+// Stats returns the MovingStats instance holding the window of individual pairs' ratios.
+// Values must not be added directly to the returned instance; use Ratio.Add instead.
+func (r *Ratio) Stats() MovingStats {
+	return r.ratios
+}
+
+// Numerators returns the MovingStats instance holding the window of numerators.
+// Values must not be added directly to the returned instance; use Ratio.Add instead.
+func (r *Ratio) Numerators() MovingStats {
+	return r.numerators
+}
+
+// Denominators returns the MovingStats instance holding the window of denominators.
+// Values must not be added directly to the returned instance; use Ratio.Add instead.
+func (r *Ratio) Denominators() MovingStats {
+	return r.denominators
+}
+
+// divide returns numerator/denominator, applying the divide-by-zero policy.
+// It returns false if the result should be skipped.
+func (r *Ratio) divide(numerator, denominator float64) (float64, bool) {
+	if denominator != 0 {
+		return numerator / denominator, true
+	}
+	switch r.policy {
+	case DivideByZeroZero:
+		return 0.0, true
+	case DivideByZeroNaN:
+		return math.NaN(), true
+	default:
+		return 0.0, false
+	}
+}
@@ -0,0 +1,84 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRatio(t *testing.T) {
+	r := NewRatio(Options{Window: 3}, DivideByZeroSkip)
+	if r.Value() != 0 {
+		t.Error(r.Value())
+	}
+
+	r.Add(1, 10)
+	r.Add(3, 10)
+	r.Add(0, 0)
+	// rolling ratio: 4/20
+	if r.Value() != 0.2 {
+		t.Error(r.Value())
+	}
+	// the 0/0 pair is skipped from the ratios window
+	if r.Stats().Count() != 2 {
+		t.Error(r.Stats().Count())
+	}
+	if r.Stats().Avg() != 0.2 {
+		t.Error(r.Stats().Avg())
+	}
+
+	// evicts the first two pairs
+	r.Add(5, 10)
+	r.Add(0, 0)
+	if r.Value() != 0.5 {
+		t.Error(r.Value())
+	}
+}
+
+func TestRatioDivideByZeroPolicies(t *testing.T) {
+	r := NewRatio(Options{Window: 3}, DivideByZeroZero)
+	r.Add(1, 0)
+	if r.Value() != 0 {
+		t.Error(r.Value())
+	}
+	if r.Stats().Count() != 1 || r.Stats().Avg() != 0 {
+		t.Error(r.Stats().Values())
+	}
+
+	r = NewRatio(Options{Window: 3}, DivideByZeroNaN)
+	r.Add(1, 0)
+	if !math.IsNaN(r.Value()) {
+		t.Error(r.Value())
+	}
+	if !math.IsNaN(r.Stats().Avg()) {
+		t.Error(r.Stats().Avg())
+	}
+
+	r = NewRatio(Options{Window: 3, IgnoreNanValues: true}, DivideByZeroNaN)
+	r.Add(1, 0)
+	if r.Stats().Count() != 0 {
+		t.Error(r.Stats().Count())
+	}
+}
+
+func TestRatioMaxAge(t *testing.T) {
+	r := NewRatio(Options{Window: 100, MaxAge: time.Minute}, DivideByZeroSkip)
+	now := time.Now()
+	clock := func() time.Time { return now }
+	for _, ms := range []MovingStats{r.Numerators(), r.Denominators(), r.Stats()} {
+		ms.(*movingStats).now = clock
+	}
+
+	r.Add(9, 10)
+	now = now.Add(30 * time.Second)
+	r.Add(1, 10)
+	if r.Value() != 0.5 {
+		t.Error(r.Value())
+	}
+
+	// the first pair has expired from all three windows
+	now = now.Add(45 * time.Second)
+	if r.Value() != 0.1 || r.Numerators().Count() != 1 || r.Denominators().Count() != 1 || r.Stats().Count() != 1 {
+		t.Error(r.Value(), r.Numerators().Count(), r.Denominators().Count(), r.Stats().Count())
+	}
+}
@@ -0,0 +1,106 @@
+package movingaverage
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Registry holds a set of named MovingStats instances, so exporters and debug
+// handlers can discover them.
+//
+// Registry is safe for concurrent use by multiple goroutines. Whether the registered
+// instances are is up to their creators; instances read by exporters while values
+// are being added should be created by NewConcurrent.
+type Registry struct {
+	entries map[string]registryEntry
+	mux     sync.RWMutex
+}
+
+type registryEntry struct {
+	ms     MovingStats
+	labels map[string]string
+}
+
+// NewRegistry returns a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: make(map[string]registryEntry),
+	}
+}
+
+// Register adds a MovingStats instance to the registry under the given name, with the
+// given (optional) labels. Registering an instance under an existing name replaces
+// the instance previously registered under that name.
+func (r *Registry) Register(name string, ms MovingStats, labels map[string]string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.entries[name] = registryEntry{ms: ms, labels: labels}
+}
+
+// Unregister removes the instance registered under the given name, if any.
+func (r *Registry) Unregister(name string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	delete(r.entries, name)
+}
+
+// Get returns the instance registered under the given name, and whether one was found.
+func (r *Registry) Get(name string) (MovingStats, bool) {
+	entry, ok := r.entry(name)
+	return entry.ms, ok
+}
+
+// Labels returns the labels of the instance registered under the given name.
+func (r *Registry) Labels(name string) map[string]string {
+	entry, _ := r.entry(name)
+	return entry.labels
+}
+
+func (r *Registry) entry(name string) (registryEntry, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	entry, ok := r.entries[name]
+	return entry, ok
+}
+
+// Names returns the names of all registered instances, sorted.
+func (r *Registry) Names() []string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	retv := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		retv = append(retv, name)
+	}
+	slices.Sort(retv)
+	return retv
+}
+
+// RegisteredSummary is the Summary of a registered instance, along with its name and labels.
+type RegisteredSummary struct {
+	Name   string
+	Labels map[string]string
+	Summary
+}
+
+// Summaries returns the Summaries of all registered instances, with their names and labels,
+// sorted by name: the building block for exporters and debug handlers. The registry's lock is
+// held only while listing the instances, not while each is summarized.
+func (r *Registry) Summaries() []RegisteredSummary {
+	r.mux.RLock()
+	retv := make([]RegisteredSummary, 0, len(r.entries))
+	instances := make([]MovingStats, 0, len(r.entries))
+	for name, entry := range r.entries {
+		retv = append(retv, RegisteredSummary{Name: name, Labels: entry.labels})
+		instances = append(instances, entry.ms)
+	}
+	r.mux.RUnlock()
+
+	for i, ms := range instances {
+		retv[i].Summary = ms.Summary()
+	}
+	slices.SortFunc(retv, func(a, b RegisteredSummary) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return retv
+}
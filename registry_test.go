@@ -0,0 +1,55 @@
+package movingaverage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	a := New(Options{Window: 3})
+	b := New(Options{Window: 3})
+	r.Register("b", b, nil)
+	r.Register("a", a, map[string]string{"route": "/"})
+
+	if !slices.Equal(r.Names(), []string{"a", "b"}) {
+		t.Error(r.Names())
+	}
+	if ms, ok := r.Get("a"); !ok || ms != a {
+		t.Error(ms, ok)
+	}
+	if r.Labels("a")["route"] != "/" {
+		t.Error(r.Labels("a"))
+	}
+
+	r.Unregister("a")
+	if _, ok := r.Get("a"); ok {
+		t.Error("expected a to be unregistered")
+	}
+	if !slices.Equal(r.Names(), []string{"b"}) {
+		t.Error(r.Names())
+	}
+}
+
+func TestRegistrySummaries(t *testing.T) {
+	r := NewRegistry()
+	a := NewConcurrent(Options{Window: 3})
+	a.Add(1, 2, 3)
+	r.Register("b", New(Options{Window: 3}), nil)
+	r.Register("a", a, map[string]string{"route": "/"})
+
+	got := r.Summaries()
+	if len(got) != 2 || got[0].Name != "a" || got[1].Name != "b" {
+		t.Fatal(got)
+	}
+	if got[0].Labels["route"] != "/" || got[0].Avg != 2 || got[0].Count != 3 {
+		t.Error(got[0])
+	}
+	if got[1].Labels != nil || got[1].Count != 0 {
+		t.Error(got[1])
+	}
+
+	if len(NewRegistry().Summaries()) != 0 {
+		t.Error("expected no summaries")
+	}
+}
@@ -0,0 +1,120 @@
+package movingaverage
+
+import (
+	"sync"
+	"time"
+)
+
+// RequestStats tracks a moving window of requests, recording each request's latency
+// and whether it succeeded, and provides the rolling request rate, error rate, and
+// latency percentiles over the same window (e.g. for circuit-breaker style decisions).
+//
+// RequestStats is safe for concurrent use by multiple goroutines.
+type RequestStats struct {
+	latencies *movingStats
+	errors    *movingStats
+	maxAge    time.Duration
+	mux       sync.RWMutex
+}
+
+// NewRequestStats returns a new RequestStats with the given options. Only Window, MaxAge,
+// MinSamples, and QuantileInterpolation apply; the other options are ignored. (Latencies are
+// never NaN or infinite, and eviction policies, which may depend on the values, would evict
+// latencies and outcomes differently.)
+func NewRequestStats(opts Options) *RequestStats {
+	windowOpts := Options{
+		Window:                opts.Window,
+		MaxAge:                opts.MaxAge,
+		MinSamples:            opts.MinSamples,
+		QuantileInterpolation: opts.QuantileInterpolation,
+	}
+	r := &RequestStats{
+		latencies: newMovingStats(windowOpts),
+		errors:    newMovingStats(windowOpts),
+		maxAge:    opts.MaxAge,
+	}
+	r.latencies.trackTimes()
+	return r
+}
+
+// Record records a request with the given latency and outcome.
+func (r *RequestStats) Record(latency time.Duration, success bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.latencies.Add(float64(latency))
+	if success {
+		r.errors.Add(0)
+	} else {
+		r.errors.Add(1)
+	}
+}
+
+// reset removes all requests from the window.
+func (r *RequestStats) reset() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.latencies.Restore(Snapshot{})
+	r.errors.Restore(Snapshot{})
+}
+
+// Count returns the number of requests in the window.
+func (r *RequestStats) Count() int {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.latencies.Count()
+}
+
+// RequestRate returns the rate of requests in the window, in requests per second.
+//
+// If MaxAge is set, this is the number of requests in the window divided by MaxAge.
+// Otherwise, it is calculated from the time between the oldest and newest requests
+// in the window. If the rate cannot be determined, 0.0 is returned.
+func (r *RequestStats) RequestRate() float64 {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	if r.maxAge > 0 {
+		return float64(r.latencies.Count()) / r.maxAge.Seconds()
+	}
+
+	_, times := r.latencies.live()
+	count := len(times)
+	if count < 2 {
+		return 0.0
+	}
+	span := times[count-1].Sub(times[0])
+	if span <= 0 {
+		return 0.0
+	}
+	return float64(count-1) / span.Seconds()
+}
+
+// ErrorRate returns the fraction of requests in the window which failed.
+// If no requests have been recorded, 0.0 is returned.
+func (r *RequestStats) ErrorRate() float64 {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.errors.Avg()
+}
+
+// AvgLatency returns the average latency of the requests in the window.
+// If no requests have been recorded, 0 is returned.
+func (r *RequestStats) AvgLatency() time.Duration {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return time.Duration(r.latencies.Avg())
+}
+
+// LatencyPercentile returns the given percentile (0-100] of the latencies of the
+// requests in the window, calculated using the nearest-rank method unless
+// Options.QuantileInterpolation is set.
+// If no requests have been recorded or any other error occurs, 0 is returned.
+func (r *RequestStats) LatencyPercentile(p float64) time.Duration {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	retv, err := percentile(r.latencies.statValues(), p, r.latencies.interpolation)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(retv)
+}
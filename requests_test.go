@@ -0,0 +1,85 @@
+package movingaverage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestStats(t *testing.T) {
+	now := time.Now()
+	r := NewRequestStats(Options{Window: 4})
+	r.latencies.now = func() time.Time { return now }
+
+	if r.RequestRate() != 0 || r.ErrorRate() != 0 || r.LatencyPercentile(99) != 0 {
+		t.Error(r.RequestRate(), r.ErrorRate(), r.LatencyPercentile(99))
+	}
+
+	for i := 1; i <= 4; i++ {
+		r.Record(time.Duration(i)*10*time.Millisecond, i != 4)
+		now = now.Add(500 * time.Millisecond)
+	}
+	if r.Count() != 4 {
+		t.Error(r.Count())
+	}
+	// 3 intervals of 500ms
+	if r.RequestRate() != 2 {
+		t.Error(r.RequestRate())
+	}
+	if r.ErrorRate() != 0.25 {
+		t.Error(r.ErrorRate())
+	}
+	if r.AvgLatency() != 25*time.Millisecond {
+		t.Error(r.AvgLatency())
+	}
+	if r.LatencyPercentile(50) != 20*time.Millisecond {
+		t.Error(r.LatencyPercentile(50))
+	}
+}
+
+func TestRequestStatsMaxAge(t *testing.T) {
+	now := time.Now()
+	r := NewRequestStats(Options{Window: 100, MaxAge: 10 * time.Second})
+	r.latencies.now = func() time.Time { return now }
+	r.errors.now = r.latencies.now
+
+	for i := 0; i < 20; i++ {
+		r.Record(time.Millisecond, false)
+	}
+	if r.RequestRate() != 2 {
+		t.Error(r.RequestRate())
+	}
+	if r.ErrorRate() != 1 {
+		t.Error(r.ErrorRate())
+	}
+
+	now = now.Add(time.Minute)
+	if r.RequestRate() != 0 || r.ErrorRate() != 0 {
+		t.Error(r.RequestRate(), r.ErrorRate())
+	}
+}
+
+func TestRequestStatsOptions(t *testing.T) {
+	opts := Options{Window: 4, MinSamples: 3, QuantileInterpolation: QuantileLinear}
+	r := NewRequestStats(opts)
+	ms := New(opts)
+	for i := 1; i <= 4; i++ {
+		latency := time.Duration(i) * 10
+		r.Record(latency, i != 1)
+		ms.Add(float64(latency))
+
+		if r.LatencyPercentile(50) != time.Duration(ms.Percentile(50)) ||
+			r.AvgLatency() != time.Duration(ms.Avg()) {
+			t.Error(i, r.LatencyPercentile(50), ms.Percentile(50), r.AvgLatency(), ms.Avg())
+		}
+	}
+	if r.LatencyPercentile(50) != 25 || r.ErrorRate() != 0.25 {
+		t.Error(r.LatencyPercentile(50), r.ErrorRate())
+	}
+
+	// ErrorRate honors MinSamples too
+	r = NewRequestStats(opts)
+	r.Record(10, false)
+	if r.ErrorRate() != 0 || r.LatencyPercentile(50) != 0 {
+		t.Error(r.ErrorRate(), r.LatencyPercentile(50))
+	}
+}
@@ -0,0 +1,118 @@
+// Package ringbuf provides a generic, fixed-capacity FIFO buffer.
+package ringbuf
+
+// Ring is a fixed-capacity FIFO buffer. Once the Ring is full, pushing a value
+// evicts the oldest value.
+//
+// Unlike a classic circular buffer, Ring keeps its values contiguous in memory,
+// oldest first, so they can be read via Slice without copying. It does so by
+// allocating room for twice its capacity and moving its values back to the start
+// of that space when the end is reached, which costs amortized O(1) per Push.
+// The cost is memory: a Ring holds room for 2·capacity values for its whole life,
+// twice what a circular buffer would.
+//
+// Ring is not safe for concurrent use by multiple goroutines.
+type Ring[T any] struct {
+	buf      []T
+	start    int
+	end      int
+	capacity int
+}
+
+// New returns a new, empty Ring with the given capacity, allocating room for
+// twice that many values up front.
+func New[T any](capacity int) *Ring[T] {
+	capacity = max(capacity, 0)
+	return &Ring[T]{
+		buf:      make([]T, 2*capacity),
+		capacity: capacity,
+	}
+}
+
+// Push adds a value to the Ring. If the Ring was full, the oldest value is evicted
+// and returned, along with true.
+// A Ring with a capacity of zero holds no values; pushing to it evicts the pushed value.
+func (r *Ring[T]) Push(v T) (evicted T, ok bool) {
+	if r.capacity == 0 {
+		return v, true
+	}
+	if r.Len() == r.capacity {
+		evicted, ok = r.PopOldest()
+	}
+	if r.end == len(r.buf) {
+		n := copy(r.buf, r.buf[r.start:r.end])
+		clear(r.buf[n:])
+		r.start, r.end = 0, n
+	}
+	r.buf[r.end] = v
+	r.end++
+	return evicted, ok
+}
+
+// PopOldest removes the oldest value from the Ring and returns it, along with true.
+// If the Ring is empty, the zero value and false are returned.
+func (r *Ring[T]) PopOldest() (T, bool) {
+	var zero T
+	if r.start == r.end {
+		return zero, false
+	}
+	v := r.buf[r.start]
+	r.buf[r.start] = zero
+	r.start++
+	return v, true
+}
+
+// Oldest returns the oldest value in the Ring, along with true.
+// If the Ring is empty, the zero value and false are returned.
+func (r *Ring[T]) Oldest() (T, bool) {
+	if r.start == r.end {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.start], true
+}
+
+// Newest returns the most recently pushed value in the Ring, along with true.
+// If the Ring is empty, the zero value and false are returned.
+func (r *Ring[T]) Newest() (T, bool) {
+	if r.start == r.end {
+		var zero T
+		return zero, false
+	}
+	return r.buf[r.end-1], true
+}
+
+// At returns the i-th oldest value in the Ring. It panics if i is out of range.
+func (r *Ring[T]) At(i int) T {
+	return r.Slice()[i]
+}
+
+// Len returns the number of values in the Ring.
+func (r *Ring[T]) Len() int {
+	return r.end - r.start
+}
+
+// Cap returns the capacity of the Ring.
+func (r *Ring[T]) Cap() int {
+	return r.capacity
+}
+
+// Do calls f for each value in the Ring, oldest first.
+// f must not modify the Ring.
+func (r *Ring[T]) Do(f func(T)) {
+	for _, v := range r.Slice() {
+		f(v)
+	}
+}
+
+// Slice returns the values in the Ring, oldest first, without copying them.
+// The returned slice is only valid until the Ring is next modified, and must not be modified.
+func (r *Ring[T]) Slice() []T {
+	return r.buf[r.start:r.end:r.end]
+}
+
+// Reset removes all values from the Ring.
+func (r *Ring[T]) Reset() {
+	clear(r.buf)
+	r.start, r.end = 0, 0
+}
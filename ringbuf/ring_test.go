@@ -0,0 +1,95 @@
+package ringbuf
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := New[int](3)
+	if r.Len() != 0 || r.Cap() != 3 {
+		t.Error(r.Len(), r.Cap())
+	}
+	if _, ok := r.Oldest(); ok {
+		t.Error("expected empty ring")
+	}
+	if _, ok := r.Newest(); ok {
+		t.Error("expected empty ring")
+	}
+
+	for i := 1; i <= 3; i++ {
+		if _, ok := r.Push(i); ok {
+			t.Error("unexpected eviction", i)
+		}
+	}
+	if evicted, ok := r.Push(4); !ok || evicted != 1 {
+		t.Error(evicted, ok)
+	}
+	if !slices.Equal(r.Slice(), []int{2, 3, 4}) {
+		t.Error(r.Slice())
+	}
+	if v, _ := r.Oldest(); v != 2 {
+		t.Error(v)
+	}
+	if v, _ := r.Newest(); v != 4 {
+		t.Error(v)
+	}
+	if r.At(1) != 3 {
+		t.Error(r.At(1))
+	}
+
+	var seen []int
+	r.Do(func(v int) {
+		seen = append(seen, v)
+	})
+	if !slices.Equal(seen, []int{2, 3, 4}) {
+		t.Error(seen)
+	}
+}
+
+func TestRingWraparound(t *testing.T) {
+	r := New[int](4)
+	for i := 1; i <= 50; i++ {
+		r.Push(i)
+		if r.Len() != min(i, 4) {
+			t.Error(i, r.Len())
+		}
+		if v, _ := r.Newest(); v != i {
+			t.Error(i, v)
+		}
+	}
+	if !slices.Equal(r.Slice(), []int{47, 48, 49, 50}) {
+		t.Error(r.Slice())
+	}
+}
+
+func TestRingPopOldest(t *testing.T) {
+	r := New[string](2)
+	r.Push("a")
+	r.Push("b")
+	if v, ok := r.PopOldest(); !ok || v != "a" {
+		t.Error(v, ok)
+	}
+	if v, ok := r.PopOldest(); !ok || v != "b" {
+		t.Error(v, ok)
+	}
+	if _, ok := r.PopOldest(); ok {
+		t.Error("expected empty ring")
+	}
+
+	r.Push("c")
+	r.Reset()
+	if r.Len() != 0 {
+		t.Error(r.Len())
+	}
+}
+
+func TestRingZeroCapacity(t *testing.T) {
+	r := New[int](0)
+	if evicted, ok := r.Push(1); !ok || evicted != 1 {
+		t.Error(evicted, ok)
+	}
+	if r.Len() != 0 {
+		t.Error(r.Len())
+	}
+}
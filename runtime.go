@@ -0,0 +1,138 @@
+package movingaverage
+
+import (
+	"context"
+	"math"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// Names of the windows kept by a RuntimeSampler.
+const (
+	// RuntimeHeapBytes is the window of bytes occupied by live and not-yet-swept heap objects.
+	RuntimeHeapBytes = "heap_bytes"
+	// RuntimeGoroutines is the window of the number of live goroutines.
+	RuntimeGoroutines = "goroutines"
+	// RuntimeGCPauseSeconds is the window of individual GC stop-the-world pause durations, in seconds.
+	RuntimeGCPauseSeconds = "gc_pause_seconds"
+)
+
+var runtimeSamplerMetrics = []struct {
+	name   string
+	metric string
+}{
+	{RuntimeHeapBytes, "/memory/classes/heap/objects:bytes"},
+	{RuntimeGoroutines, "/sched/goroutines:goroutines"},
+	{RuntimeGCPauseSeconds, "/sched/pauses/total/gc:seconds"},
+}
+
+// RuntimeSampler periodically reads metrics from the Go runtime (via runtime/metrics)
+// into a set of named, concurrency-safe MovingStats instances, giving an application
+// rolling views of its own heap size, goroutine count, and GC pauses.
+//
+// Heap size and goroutine count are added once per sample. GC pauses which occurred
+// since the previous sample are each added individually, approximated by the upper bound
+// of the runtime's histogram bucket they fall in; at most Window pauses are added per sample.
+//
+// RuntimeSampler is safe for concurrent use by multiple goroutines.
+type RuntimeSampler struct {
+	interval time.Duration
+	windows  map[string]MovingStats
+	samples  []metrics.Sample
+	gcPauses []uint64
+	mux      sync.Mutex
+}
+
+// NewRuntimeSampler returns a new RuntimeSampler whose windows are created with the given
+// options, and which samples every interval once Run is called.
+func NewRuntimeSampler(opts Options, interval time.Duration) *RuntimeSampler {
+	s := &RuntimeSampler{
+		interval: interval,
+		windows:  make(map[string]MovingStats, len(runtimeSamplerMetrics)),
+		samples:  make([]metrics.Sample, len(runtimeSamplerMetrics)),
+	}
+	for i, m := range runtimeSamplerMetrics {
+		s.windows[m.name] = NewConcurrent(opts)
+		s.samples[i].Name = m.metric
+	}
+	return s
+}
+
+// Get returns the window with the given name (one of the Runtime* constants),
+// or nil if there is no such window.
+func (s *RuntimeSampler) Get(name string) MovingStats {
+	return s.windows[name]
+}
+
+// Register adds the sampler's windows to the given Registry, each under its name
+// prefixed by prefix (e.g. "runtime_").
+func (s *RuntimeSampler) Register(reg *Registry, prefix string) {
+	for name, ms := range s.windows {
+		reg.Register(prefix+name, ms, nil)
+	}
+}
+
+// Sample immediately reads the runtime's metrics into the sampler's windows.
+func (s *RuntimeSampler) Sample() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	metrics.Read(s.samples)
+	for i, m := range runtimeSamplerMetrics {
+		v := s.samples[i].Value
+		switch v.Kind() {
+		case metrics.KindUint64:
+			s.windows[m.name].Add(float64(v.Uint64()))
+		case metrics.KindFloat64:
+			s.windows[m.name].Add(v.Float64())
+		case metrics.KindFloat64Histogram:
+			s.addHistogramDelta(s.windows[m.name], v.Float64Histogram())
+		}
+	}
+}
+
+// addHistogramDelta adds one value to ms for each observation recorded in the histogram
+// since the previous sample. The first sample only records the histogram's state.
+func (s *RuntimeSampler) addHistogramDelta(ms MovingStats, h *metrics.Float64Histogram) {
+	first := s.gcPauses == nil
+	if len(s.gcPauses) != len(h.Counts) {
+		s.gcPauses = make([]uint64, len(h.Counts))
+	}
+
+	var values []float64
+	for i, count := range h.Counts {
+		delta := count - s.gcPauses[i]
+		s.gcPauses[i] = count
+		if first {
+			continue
+		}
+		// Bucket i spans [Buckets[i], Buckets[i+1])
+		v := h.Buckets[i+1]
+		if math.IsInf(v, 1) {
+			v = h.Buckets[i]
+		}
+		for ; delta > 0 && len(values) < ms.Window(); delta-- {
+			values = append(values, v)
+		}
+	}
+	ms.Add(values...)
+}
+
+// Run calls Sample every interval until the given context is canceled.
+// It returns the context's error, or an error immediately if the interval isn't positive.
+func (s *RuntimeSampler) Run(ctx context.Context) error {
+	if s.interval <= 0 {
+		return errNonPositiveInterval
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.Sample()
+		}
+	}
+}
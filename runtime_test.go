@@ -0,0 +1,37 @@
+package movingaverage
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestRuntimeSampler(t *testing.T) {
+	s := NewRuntimeSampler(Options{Window: 10}, 0)
+	s.Sample()
+	runtime.GC()
+	runtime.GC()
+	s.Sample()
+
+	if n := s.Get(RuntimeHeapBytes).Count(); n != 2 {
+		t.Error(n)
+	}
+	if s.Get(RuntimeHeapBytes).Min() <= 0 {
+		t.Error(s.Get(RuntimeHeapBytes).Min())
+	}
+	if s.Get(RuntimeGoroutines).Min() < 1 {
+		t.Error(s.Get(RuntimeGoroutines).Min())
+	}
+	// Each GC has at least two stop-the-world pauses
+	if n := s.Get(RuntimeGCPauseSeconds).Count(); n < 2 {
+		t.Error(n)
+	}
+	if s.Get("nonexistent") != nil {
+		t.Error("expected nil")
+	}
+
+	reg := NewRegistry()
+	s.Register(reg, "runtime_")
+	if _, ok := reg.Get("runtime_goroutines"); !ok {
+		t.Error(reg.Names())
+	}
+}
@@ -0,0 +1,65 @@
+package movingaverage
+
+import (
+	"github.com/montanaflynn/stats"
+)
+
+// SLO computes the fraction of values in a MovingStats instance's window which meet
+// a service level objective, e.g. for availability or latency SLO dashboards.
+//
+// Values less than or equal to the SLO's threshold are considered good; values
+// greater than the threshold (including +Inf) are considered bad. NaN values are
+// considered bad.
+//
+// SLO reads from the underlying MovingStats instance without copying its values.
+// It is safe for concurrent use if the underlying instance is.
+type SLO struct {
+	ms        MovingStats
+	threshold float64
+}
+
+// NewSLO returns a new SLO over the given MovingStats instance, with the given threshold.
+func NewSLO(ms MovingStats, threshold float64) *SLO {
+	return &SLO{
+		ms:        ms,
+		threshold: threshold,
+	}
+}
+
+// Threshold returns the SLO's threshold.
+func (s *SLO) Threshold() float64 {
+	return s.threshold
+}
+
+// GoodFraction returns the fraction of values in the window which meet the objective.
+// If no values have been added, 0.0 is returned.
+func (s *SLO) GoodFraction() float64 {
+	good, total := s.counts()
+	if total == 0 {
+		return 0.0
+	}
+	return float64(good) / float64(total)
+}
+
+// BadFraction returns the fraction of values in the window which do not meet the objective.
+// If no values have been added, 0.0 is returned.
+func (s *SLO) BadFraction() float64 {
+	good, total := s.counts()
+	if total == 0 {
+		return 0.0
+	}
+	return float64(total-good) / float64(total)
+}
+
+func (s *SLO) counts() (good, total int) {
+	_ = s.ms.UnsafeDo(func(values stats.Float64Data) error {
+		total = len(values)
+		for _, v := range values {
+			if v <= s.threshold {
+				good++
+			}
+		}
+		return nil
+	})
+	return good, total
+}
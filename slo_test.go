@@ -0,0 +1,31 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSLO(t *testing.T) {
+	ms := New(Options{Window: 4})
+	slo := NewSLO(ms, 250)
+	if slo.GoodFraction() != 0 || slo.BadFraction() != 0 {
+		t.Error(slo.GoodFraction(), slo.BadFraction())
+	}
+
+	ms.Add(100, 250, 300, math.NaN())
+	if slo.GoodFraction() != 0.5 {
+		t.Error(slo.GoodFraction())
+	}
+	if slo.BadFraction() != 0.5 {
+		t.Error(slo.BadFraction())
+	}
+
+	ms.Add(1, 2)
+	if slo.GoodFraction() != 0.5 {
+		t.Error(slo.GoodFraction())
+	}
+	ms.Add(3, 4)
+	if slo.GoodFraction() != 1 || slo.BadFraction() != 0 {
+		t.Error(slo.GoodFraction(), slo.BadFraction())
+	}
+}
@@ -0,0 +1,62 @@
+package movingaverage
+
+import (
+	"time"
+)
+
+// Snapshot is the serializable state of a moving stats instance, as returned by
+// MovingStats.Snapshot and consumed by MovingStats.Restore.
+type Snapshot struct {
+	// The number of values kept by the instance the snapshot was taken from.
+	Window int
+
+	// The values in the instance, oldest first.
+	Values []float64
+
+	// The times the values were added, in the same order as Values.
+	// Times is nil if the instance does not track the times values were added, i.e., if
+	// it has no MaxAge or Eviction policy, TrackIngestRate is unset, and Health.MaxStaleness
+	// is zero.
+	Times []time.Time
+}
+
+func (ma *movingStats) Snapshot() Snapshot {
+	values, times := ma.live()
+	retv := Snapshot{
+		Window: ma.window,
+		Values: make([]float64, len(values)),
+	}
+	_ = copy(retv.Values, values)
+	if times != nil {
+		retv.Times = make([]time.Time, len(times))
+		_ = copy(retv.Times, times)
+	}
+	return retv
+}
+
+func (ma *movingStats) Restore(s Snapshot) {
+	for ma.values.Len() > 0 {
+		ma.evictOldest()
+	}
+	ma.sorted = nil
+	if ma.previous != nil {
+		ma.previous.reset()
+	}
+	if ma.cusum != nil {
+		*ma.cusum = cusum{}
+	}
+	if ma.ew != nil {
+		*ma.ew = ewMoments{}
+	}
+
+	now := ma.now()
+	for i, val := range s.Values {
+		t := now
+		if i < len(s.Times) {
+			t = s.Times[i]
+		}
+		ma.push(val, t)
+	}
+
+	ma.applyEviction(now)
+}
@@ -0,0 +1,87 @@
+package movingaverage
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	a := New(Options{Window: 3})
+	a.Add(1, 2, 3, 4)
+	snap := a.Snapshot()
+	if snap.Window != 3 || !slices.Equal(snap.Values, []float64{2, 3, 4}) || snap.Times != nil {
+		t.Error(snap)
+	}
+
+	b := NewConcurrent(Options{Window: 2})
+	b.Add(100)
+	b.Restore(snap)
+	if !slices.Equal(b.Values(), stats.Float64Data{3, 4}) {
+		t.Error(b.Values())
+	}
+}
+
+func TestSnapshotRestoreTimes(t *testing.T) {
+	now := time.Now()
+	a := newMovingStats(Options{Window: 3, MaxAge: time.Minute})
+	a.now = func() time.Time { return now }
+	a.Add(1)
+	now = now.Add(30 * time.Second)
+	a.Add(2)
+
+	snap := a.Snapshot()
+	if len(snap.Times) != 2 || !snap.Times[1].Equal(now) {
+		t.Error(snap.Times)
+	}
+
+	// the restored values keep their times, so the first expires first
+	b := newMovingStats(Options{Window: 3, MaxAge: time.Minute})
+	b.now = a.now
+	b.Restore(snap)
+	now = now.Add(45 * time.Second)
+	if !slices.Equal(b.Values(), stats.Float64Data{2}) {
+		t.Error(b.Values())
+	}
+}
+
+// TestSnapshotRestoreTrackedTimes checks that the times kept for IngestRate and the
+// staleness detector survive a snapshot and restore, in windows which aren't time-based.
+func TestSnapshotRestoreTrackedTimes(t *testing.T) {
+	for name, opts := range map[string]Options{
+		"TrackIngestRate":     {Window: 10, TrackIngestRate: true},
+		"Health.MaxStaleness": {Window: 10, Health: HealthOptions{MaxStaleness: time.Minute}},
+	} {
+		now := time.Now()
+		clock := func() time.Time { return now }
+		a := newMovingStats(opts)
+		a.now = clock
+		for i := 0; i < 5; i++ {
+			a.Add(float64(i))
+			now = now.Add(time.Second)
+		}
+
+		snap := a.Snapshot()
+		if len(snap.Times) != 5 || !snap.Times[4].Equal(now.Add(-time.Second)) {
+			t.Error(name, snap.Times)
+		}
+
+		// restored later, the values keep the times they were added
+		now = now.Add(29 * time.Second)
+		b := newMovingStats(opts)
+		b.now = clock
+		b.Restore(snap)
+		if b.IngestRate() != a.IngestRate() || b.HealthScore() != a.HealthScore() {
+			t.Error(name, b.IngestRate(), a.IngestRate(), b.HealthScore(), a.HealthScore())
+		}
+		if opts.TrackIngestRate && math.Abs(b.IngestRate()-4.0/34) > 0.0001 {
+			t.Error(name, b.IngestRate())
+		}
+		if opts.Health.MaxStaleness > 0 && math.Abs(b.HealthScore()-0.5) > 0.0001 {
+			t.Error(name, b.HealthScore())
+		}
+	}
+}
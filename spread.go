@@ -0,0 +1,43 @@
+package movingaverage
+
+// Spread tracks the difference between two series (x - y) over a moving window,
+// e.g. for monitoring replica lag differences or price spreads.
+//
+// Spread is not safe for concurrent use by multiple goroutines.
+type Spread struct {
+	ms *movingStats
+}
+
+// NewSpread returns a new Spread with the given options.
+func NewSpread(opts Options) *Spread {
+	return &Spread{
+		ms: newMovingStats(opts),
+	}
+}
+
+// Add adds the spread between x and y (x - y) to the window.
+func (s *Spread) Add(x, y float64) {
+	s.ms.Add(x - y)
+}
+
+// Stats returns the MovingStats instance holding the window of spreads.
+// Values must not be added directly to the returned instance; use Spread.Add instead.
+func (s *Spread) Stats() MovingStats {
+	return s.ms
+}
+
+// Current returns the most recently added spread.
+// If no values have been added, 0.0 is returned.
+func (s *Spread) Current() float64 {
+	retv, _ := s.ms.newest()
+	return retv
+}
+
+// ZScore returns the number of standard deviations the most recently added spread is from
+// the mean spread in the window, as returned by the ZScore method of Stats, so it honors
+// Options.VarianceEstimator and Options.MinSamples.
+// If no values have been added, the standard deviation is zero, or any other error occurs,
+// 0.0 is returned.
+func (s *Spread) ZScore() float64 {
+	return s.ms.ZScore()
+}
@@ -0,0 +1,60 @@
+package movingaverage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpread(t *testing.T) {
+	s := NewSpread(Options{Window: 4})
+	if s.Current() != 0 || s.ZScore() != 0 {
+		t.Error(s.Current(), s.ZScore())
+	}
+
+	s.Add(10, 9)
+	s.Add(10, 7)
+	s.Add(10, 9)
+	s.Add(10, 7)
+	if s.Stats().Avg() != 2 {
+		t.Error(s.Stats().Avg())
+	}
+	if s.Current() != 3 {
+		t.Error(s.Current())
+	}
+	// mean 2, population stddev 1
+	if math.Abs(s.ZScore()-1) > 0.0001 {
+		t.Error(s.ZScore())
+	}
+
+	s.Add(5, 5)
+	if s.Current() != 0 {
+		t.Error(s.Current())
+	}
+	if s.ZScore() >= 0 {
+		t.Error(s.ZScore())
+	}
+}
+
+func TestSpreadZScoreOptions(t *testing.T) {
+	opts := Options{Window: 4, VarianceEstimator: VarianceSample, MinSamples: 3}
+	s := NewSpread(opts)
+	ms := New(opts)
+
+	s.Add(10, 9)
+	ms.Add(1)
+	s.Add(10, 7)
+	ms.Add(3)
+	// fewer than MinSamples values
+	if s.ZScore() != 0 {
+		t.Error(s.ZScore())
+	}
+
+	s.Add(10, 9)
+	ms.Add(1)
+	s.Add(10, 7)
+	ms.Add(3)
+	// mean 2, sample stddev 2/√3
+	if s.ZScore() != ms.ZScore() || math.Abs(s.ZScore()-math.Sqrt(3)/2) > 0.0001 {
+		t.Error(s.ZScore(), ms.ZScore())
+	}
+}
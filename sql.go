@@ -0,0 +1,203 @@
+package movingaverage
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// WrapSQLConnector returns a driver.Connector which wraps the given connector, recording
+// the duration of each query and exec into the DurationStats returned by pick for that
+// statement's query text, allowing e.g. per-statement-label stats. If pick returns nil,
+// the statement is not recorded.
+//
+// Use the result with sql.OpenDB. The duration of a query covers executing it and
+// returning its rows, not iterating over them. Statements which fail are recorded, too.
+func WrapSQLConnector(c driver.Connector, pick func(query string) *DurationStats) driver.Connector {
+	return &sqlConnector{Connector: c, pick: pick}
+}
+
+type sqlConnector struct {
+	driver.Connector
+	pick func(query string) *DurationStats
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{Conn: conn, pick: c.pick}, nil
+}
+
+// observe records the time since start into the DurationStats for the given query,
+// unless err is driver.ErrSkip, in which case database/sql retries the statement
+// another way and that attempt is recorded instead.
+func observe(pick func(query string) *DurationStats, query string, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	if d := pick(query); d != nil {
+		d.Observe(time.Since(start))
+	}
+}
+
+// sqlConn wraps a driver.Conn, forwarding the optional interfaces database/sql uses.
+type sqlConn struct {
+	driver.Conn
+	pick func(query string) *DurationStats
+}
+
+var (
+	_ driver.ConnPrepareContext = (*sqlConn)(nil)
+	_ driver.ConnBeginTx        = (*sqlConn)(nil)
+	_ driver.ExecerContext      = (*sqlConn)(nil)
+	_ driver.QueryerContext     = (*sqlConn)(nil)
+	_ driver.Pinger             = (*sqlConn)(nil)
+	_ driver.SessionResetter    = (*sqlConn)(nil)
+	_ driver.Validator          = (*sqlConn)(nil)
+	_ driver.NamedValueChecker  = (*sqlConn)(nil)
+)
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sqlStmt{Stmt: stmt, query: query, pick: c.pick}, nil
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts != (driver.TxOptions{}) {
+		return nil, errors.New("movingaverage: driver does not support transaction options")
+	}
+	return c.Conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := e.ExecContext(ctx, query, args)
+	observe(c.pick, query, start, err)
+	return res, err
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	observe(c.pick, query, start, err)
+	return rows, err
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// sqlStmt wraps a prepared driver.Stmt, recording the duration of each exec and query.
+type sqlStmt struct {
+	driver.Stmt
+	query string
+	pick  func(query string) *DurationStats
+}
+
+var (
+	_ driver.StmtExecContext   = (*sqlStmt)(nil)
+	_ driver.StmtQueryContext  = (*sqlStmt)(nil)
+	_ driver.NamedValueChecker = (*sqlStmt)(nil)
+)
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			res, err = s.Stmt.Exec(values) //nolint:staticcheck // fallback for drivers without ExecContext
+		}
+	}
+	observe(s.pick, s.query, start, err)
+	return res, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = s.Stmt.Query(values) //nolint:staticcheck // fallback for drivers without QueryContext
+		}
+	}
+	observe(s.pick, s.query, start, err)
+	return rows, err
+}
+
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// namedValuesToValues converts args for drivers which don't support named parameters.
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("movingaverage: driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
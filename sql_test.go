@@ -0,0 +1,96 @@
+package movingaverage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeConnector is a minimal driver whose connections implement ExecerContext,
+// but whose queries go through prepared statements.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if query == "bad" {
+		return nil, errors.New("syntax error")
+	}
+	return fakeStmt{}, nil
+}
+func (fakeConn) Close() error              { return nil }
+func (fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("unsupported") }
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "fail" {
+		return nil, errors.New("exec failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return []string{"x"} }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+func TestWrapSQLConnector(t *testing.T) {
+	writes := NewDurationStats(Options{Window: 10})
+	reads := NewDurationStats(Options{Window: 10})
+	db := sql.OpenDB(WrapSQLConnector(fakeConnector{}, func(query string) *DurationStats {
+		switch query {
+		case "insert", "fail":
+			return writes
+		case "select":
+			return reads
+		}
+		return nil
+	}))
+	defer db.Close()
+
+	if _, err := db.Exec("insert", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("fail"); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := db.Exec("other"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query("select", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	stmt, err := db.Prepare("select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stmt.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	_ = stmt.Close()
+	if _, err := db.Query("bad"); err == nil {
+		t.Error("expected error")
+	}
+
+	if writes.Stats().Count() != 2 {
+		t.Error(writes.Stats().Count())
+	}
+	if reads.Stats().Count() != 2 {
+		t.Error(reads.Stats().Count())
+	}
+}
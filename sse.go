@@ -0,0 +1,96 @@
+package movingaverage
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// NewSSEHandler returns an http.Handler which streams Summary updates for the instances
+// in the given Registry as Server-Sent Events, immediately and then every interval,
+// until the client disconnects.
+//
+// If the request has a "name" query parameter, only the instance registered under that
+// name is streamed; otherwise, all registered instances are. Each instance's Summary is
+// sent as a "summary" event whose data has the same format as the lines written by
+// JSONEmitter, plus the instance's name:
+//
+//	event: summary
+//	data: {"time":"2024-05-01T12:00:00Z","name":"api","count":3,"avg":2,"min":1,"max":3}
+//
+// The interval must be positive; if it isn't, requests fail with a 500 error.
+func NewSSEHandler(reg *Registry, interval time.Duration) http.Handler {
+	return &sseHandler{
+		reg:      reg,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+type sseHandler struct {
+	reg      *Registry
+	interval time.Duration
+	now      func() time.Time
+}
+
+func (h *sseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.interval <= 0 {
+		http.Error(w, errNonPositiveInterval.Error(), http.StatusInternalServerError)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name != "" {
+		if _, ok := h.reg.Get(name); !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Write(h.events(name)); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// events returns the events for the named instance, or all instances if name is empty.
+func (h *sseHandler) events(name string) []byte {
+	var summaries []RegisteredSummary
+	if name == "" {
+		summaries = h.reg.Summaries()
+	} else if entry, ok := h.reg.entry(name); ok {
+		summaries = []RegisteredSummary{{Name: name, Labels: entry.labels, Summary: entry.ms.Summary()}}
+	}
+
+	now := h.now()
+	var buf bytes.Buffer
+	for _, s := range summaries {
+		data, err := json.Marshal(newJSONEmitterRecord(now, s.Name, s.Labels, s.Summary))
+		if err != nil {
+			continue
+		}
+		buf.WriteString("event: summary\ndata: ")
+		buf.Write(data)
+		buf.WriteString("\n\n")
+	}
+	return buf.Bytes()
+}
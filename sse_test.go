@@ -0,0 +1,73 @@
+package movingaverage
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEHandler(t *testing.T) {
+	reg := NewRegistry()
+	a := New(Options{Window: 3})
+	a.Add(1, 2, 3)
+	reg.Register("a", a, map[string]string{"route": "/"})
+	reg.Register("b", New(Options{Window: 3}), nil)
+
+	srv := httptest.NewServer(NewSSEHandler(reg, 10*time.Millisecond))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?name=a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Error(resp.Header.Get("Content-Type"))
+	}
+
+	r := bufio.NewReader(resp.Body)
+	for i := 0; i < 2; i++ {
+		event, _ := r.ReadString('\n')
+		data, _ := r.ReadString('\n')
+		blank, _ := r.ReadString('\n')
+		if event != "event: summary\n" || blank != "\n" {
+			t.Errorf("%q %q", event, blank)
+		}
+		if !strings.HasPrefix(data, `data: {"time":`) ||
+			!strings.Contains(data, `"name":"a","labels":{"route":"/"},"count":3,"avg":2,"min":1,"max":3}`) {
+			t.Error(data)
+		}
+	}
+}
+
+func TestSSEHandlerAll(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("a", New(Options{Window: 3}), nil)
+	reg.Register("b", New(Options{Window: 3}), nil)
+
+	h := NewSSEHandler(reg, time.Second).(*sseHandler)
+	events := string(h.events(""))
+	if strings.Count(events, "event: summary\n") != 2 ||
+		!strings.Contains(events, `"name":"a"`) || !strings.Contains(events, `"name":"b"`) {
+		t.Error(events)
+	}
+}
+
+func TestSSEHandlerNotFound(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewSSEHandler(NewRegistry(), time.Second).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Error(rec.Code)
+	}
+}
+
+func TestSSEHandlerInvalidInterval(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewSSEHandler(NewRegistry(), 0).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Error(rec.Code)
+	}
+}
@@ -0,0 +1,264 @@
+package movingaverage
+
+import (
+	"container/list"
+	"hash/maphash"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// MapLimits bound the number of keys in a MovingStatsMap and the memory their instances use,
+// so that (for example) an attacker generating unique keys can't exhaust memory.
+// A zero limit means no limit.
+type MapLimits struct {
+	// The maximum number of keys.
+	MaxKeys int
+
+	// The maximum total memory, in bytes, used by the keys and their instances. Each instance's
+	// memory use is estimated from its options when it is created, assuming a full window;
+	// for compressed instances, which use less, the estimate is an upper bound.
+	MaxBytes int
+
+	// Whether to reject new keys when a limit would be exceeded, instead of evicting the
+	// least recently used keys to make room.
+	RejectNewKeys bool
+
+	// If set, called with each key evicted to make room for a new one, and its instance.
+	// It is called while the map's lock is held, so it must not call the map's methods.
+	OnEvict func(key string, ms MovingStats)
+}
+
+// MovingStatsMap holds a concurrency-safe MovingStats instance per key, created with the
+// same options on first use, e.g. to track latency per tenant or per route. Its MapLimits
+// bound the number of keys and their memory use; by default, the least recently used keys
+// are evicted to make room for new ones.
+//
+// Keys are sharded by hash, each shard with its own lock, so getting the instances for
+// existing keys on different shards doesn't serialize. Only creating and removing keys
+// takes the map-wide lock.
+//
+// MovingStatsMap is safe for concurrent use by multiple goroutines.
+type MovingStatsMap struct {
+	opts   Options
+	limits MapLimits
+	seed   maphash.Seed
+	shards [mapShards]mapShard
+	clock  atomic.Uint64 // stamps entries as they're used
+	len    int
+	lru    *list.List // of *mapEntry, ordered by their stamps when they were (re)inserted, newest first
+	bytes  int
+	mux    sync.Mutex // guards len, lru, bytes, and adding and removing entries
+}
+
+// mapShards is the number of shards in a MovingStatsMap.
+const mapShards = 64
+
+type mapShard struct {
+	entries map[string]*mapEntry
+	mux     sync.RWMutex
+}
+
+type mapEntry struct {
+	key      string
+	ms       MovingStats
+	bytes    int
+	el       *list.Element
+	stamp    uint64        // lastUsed when the entry was (re)inserted into the lru list
+	lastUsed atomic.Uint64 // updated without the map-wide lock, so lru may be stale
+}
+
+// NewMovingStatsMap returns a new, empty MovingStatsMap whose instances are created with the
+// given options, subject to the given limits.
+func NewMovingStatsMap(opts Options, limits MapLimits) *MovingStatsMap {
+	m := &MovingStatsMap{
+		opts:   opts,
+		limits: limits,
+		seed:   maphash.MakeSeed(),
+		lru:    list.New(),
+	}
+	for i := range m.shards {
+		m.shards[i].entries = make(map[string]*mapEntry)
+	}
+	return m
+}
+
+// Get returns the instance for the given key, creating it if there is none, and marks the
+// key as recently used. If creating the instance would exceed the map's limits, the least
+// recently used keys are evicted to make room; or, if MapLimits.RejectNewKeys is set or the
+// instance alone exceeds MapLimits.MaxBytes, nil is returned.
+func (m *MovingStatsMap) Get(key string) MovingStats {
+	shard := m.shard(key)
+	shard.mux.RLock()
+	entry, ok := shard.entries[key]
+	if ok {
+		entry.lastUsed.Store(m.clock.Add(1))
+	}
+	shard.mux.RUnlock()
+	if ok {
+		return entry.ms
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	// Another goroutine may have created the instance since the shard was unlocked
+	shard.mux.RLock()
+	entry, ok = shard.entries[key]
+	shard.mux.RUnlock()
+	if ok {
+		entry.lastUsed.Store(m.clock.Add(1))
+		return entry.ms
+	}
+
+	entry = &mapEntry{key: key, bytes: estimatedInstanceBytes(m.opts) + len(key)}
+	if m.limits.MaxBytes > 0 && entry.bytes > m.limits.MaxBytes {
+		return nil
+	}
+	for m.full(entry.bytes) {
+		if m.limits.RejectNewKeys {
+			return nil
+		}
+		m.evictOldest()
+	}
+
+	entry.ms = NewConcurrent(m.opts)
+	entry.stamp = m.clock.Add(1)
+	entry.lastUsed.Store(entry.stamp)
+	entry.el = m.lru.PushFront(entry)
+	m.len++
+	m.bytes += entry.bytes
+	shard.mux.Lock()
+	shard.entries[key] = entry
+	shard.mux.Unlock()
+	return entry.ms
+}
+
+// Add adds the given values to the instance for the given key, per Get.
+// It returns false if the key was rejected, per MapLimits.RejectNewKeys.
+func (m *MovingStatsMap) Add(key string, values ...float64) bool {
+	ms := m.Get(key)
+	if ms == nil {
+		return false
+	}
+	ms.Add(values...)
+	return true
+}
+
+// Lookup returns the instance for the given key, and whether there is one,
+// without creating it or marking the key as recently used.
+func (m *MovingStatsMap) Lookup(key string) (MovingStats, bool) {
+	shard := m.shard(key)
+	shard.mux.RLock()
+	defer shard.mux.RUnlock()
+	if entry, ok := shard.entries[key]; ok {
+		return entry.ms, true
+	}
+	return nil, false
+}
+
+// Delete removes the instance for the given key, if any.
+func (m *MovingStatsMap) Delete(key string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	shard := m.shard(key)
+	shard.mux.Lock()
+	defer shard.mux.Unlock()
+	if entry, ok := shard.entries[key]; ok {
+		m.remove(shard, entry)
+	}
+}
+
+// Len returns the number of keys in the map.
+func (m *MovingStatsMap) Len() int {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.len
+}
+
+// Bytes returns the estimated memory, in bytes, used by the map's keys and instances,
+// as limited by MapLimits.MaxBytes.
+func (m *MovingStatsMap) Bytes() int {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.bytes
+}
+
+// Keys returns the map's keys, sorted.
+func (m *MovingStatsMap) Keys() []string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	retv := make([]string, 0, m.len)
+	for el := m.lru.Front(); el != nil; el = el.Next() {
+		retv = append(retv, el.Value.(*mapEntry).key)
+	}
+	slices.Sort(retv)
+	return retv
+}
+
+func (m *MovingStatsMap) shard(key string) *mapShard {
+	return &m.shards[maphash.String(m.seed, key)%mapShards]
+}
+
+// full returns whether adding an instance of the given size would exceed the map's limits.
+func (m *MovingStatsMap) full(bytes int) bool {
+	return (m.limits.MaxKeys > 0 && m.len+1 > m.limits.MaxKeys) ||
+		(m.limits.MaxBytes > 0 && m.bytes+bytes > m.limits.MaxBytes)
+}
+
+// evictOldest evicts the least recently used key. Since Get marks keys as used without the
+// map-wide lock, the lru list is ordered by the stamps the entries had when they were last
+// (re)inserted into it, not by when they were last used: entries at the back which have
+// been used since are reinserted in order of their new stamps, until the entry at the back
+// is one which hasn't been, and so is the least recently used.
+func (m *MovingStatsMap) evictOldest() {
+	for {
+		entry := m.lru.Back().Value.(*mapEntry)
+		shard := m.shard(entry.key)
+		shard.mux.Lock()
+		if lastUsed := entry.lastUsed.Load(); lastUsed != entry.stamp {
+			shard.mux.Unlock()
+			entry.stamp = lastUsed
+			at := m.lru.Front()
+			for at.Value.(*mapEntry).stamp > lastUsed {
+				at = at.Next()
+			}
+			m.lru.MoveBefore(entry.el, at)
+			continue
+		}
+		m.remove(shard, entry)
+		shard.mux.Unlock()
+		if m.limits.OnEvict != nil {
+			m.limits.OnEvict(entry.key, entry.ms)
+		}
+		return
+	}
+}
+
+// remove removes the given entry; its shard's lock must be held.
+func (m *MovingStatsMap) remove(shard *mapShard, entry *mapEntry) {
+	m.lru.Remove(entry.el)
+	delete(shard.entries, entry.key)
+	m.len--
+	m.bytes -= entry.bytes
+}
+
+// estimatedInstanceBytes estimates the memory used by a concurrency-safe instance created
+// with the given options, with a full window: a fixed overhead for the instance itself,
+// plus its values and times, whose rings allocate room for twice the window, per ringbuf.Ring.
+// The overhead is measured by TestEstimatedInstanceBytes.
+func estimatedInstanceBytes(opts Options) int {
+	const (
+		overheadBytes = 672
+		valueBytes    = 8  // float64
+		timeBytes     = 24 // time.Time
+	)
+	retv := overheadBytes + 2*opts.Window*valueBytes
+	if opts.MaxAge > 0 || opts.Eviction != nil || opts.TrackIngestRate || opts.Health.MaxStaleness > 0 {
+		retv += 2 * opts.Window * timeBytes
+	}
+	if opts.TrackTrend {
+		retv += 2 * opts.Window * valueBytes // the previous window's values
+	}
+	return retv
+}
@@ -0,0 +1,320 @@
+package movingaverage
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Uint64MapLimits bound the number of keys in a MovingStatsUint64Map and the memory their
+// instances use, as MapLimits do for a MovingStatsMap. A zero limit means no limit.
+type Uint64MapLimits struct {
+	// The maximum number of keys.
+	MaxKeys int
+
+	// The maximum total memory, in bytes, used by the keys (including their slots in the
+	// map's tables) and their instances, estimated as for MapLimits.MaxBytes.
+	MaxBytes int
+
+	// Whether to reject new keys when a limit would be exceeded, instead of evicting
+	// keys which haven't been used recently to make room.
+	RejectNewKeys bool
+
+	// If set, called with each key evicted to make room for a new one, and its instance.
+	// It is called while the map's lock is held, so it must not call the map's methods.
+	OnEvict func(key uint64, ms MovingStats)
+}
+
+// MovingStatsUint64Map is like MovingStatsMap, but keyed by uint64 (e.g. hashed IDs), for
+// millions of keys. Rather than a Go map and a linked list, it holds its instances in
+// compact open-addressing hash tables, sharded by key hash, each shard with its own lock,
+// so getting the instances for existing keys on different shards doesn't serialize.
+// This saves the per-key overhead of the map and list, but not of the instances, which
+// dominate each key's memory, as in a MovingStatsMap.
+// Only creating and removing keys takes the map-wide lock. When a limit is reached, keys
+// are evicted in approximately least recently used order, per the CLOCK algorithm: keys
+// used since the eviction scan last passed them are skipped once.
+//
+// MovingStatsUint64Map is safe for concurrent use by multiple goroutines, as are its
+// instances, each of which has its own lock, as if created by NewConcurrent.
+type MovingStatsUint64Map struct {
+	opts          Options
+	limits        Uint64MapLimits
+	shards        [mapShards]uint64MapShard
+	len           int
+	bytes         int
+	instanceBytes int
+	handShard     int        // the shard the CLOCK algorithm's hand is in
+	mux           sync.Mutex // guards len, bytes, handShard, and adding and removing keys
+}
+
+type uint64MapShard struct {
+	slots []uint64MapSlot // linear probing; len is a power of two
+	len   int
+	hand  int // the CLOCK algorithm's next slot to consider for eviction, when in this shard
+	mux   sync.RWMutex
+}
+
+type uint64MapSlot struct {
+	key  uint64
+	ms   MovingStats // nil if the slot is empty
+	used uint32      // whether the key was used since the clock hand last passed; set atomically
+}
+
+const (
+	uint64MapMinSlots = 16
+
+	// The estimated memory used by a key in its shard's table: a slot is 32 bytes, and the
+	// tables are between 3/8 and 3/4 full, so each key takes about two slots.
+	uint64MapKeyBytes = 64
+)
+
+// NewMovingStatsUint64Map returns a new, empty MovingStatsUint64Map whose instances are
+// created with the given options, subject to the given limits.
+func NewMovingStatsUint64Map(opts Options, limits Uint64MapLimits) *MovingStatsUint64Map {
+	m := &MovingStatsUint64Map{
+		opts:          opts,
+		limits:        limits,
+		instanceBytes: estimatedInstanceBytes(opts) + uint64MapKeyBytes,
+	}
+	for i := range m.shards {
+		m.shards[i].slots = make([]uint64MapSlot, uint64MapMinSlots)
+	}
+	return m
+}
+
+// Get returns the instance for the given key, creating it if there is none, and marks the
+// key as recently used. If creating the instance would exceed the map's limits, keys which
+// haven't been used recently are evicted to make room; or, if Uint64MapLimits.RejectNewKeys
+// is set or the instance alone exceeds Uint64MapLimits.MaxBytes, nil is returned.
+func (m *MovingStatsUint64Map) Get(key uint64) MovingStats {
+	shard := m.shard(key)
+	if ms, ok := shard.get(key, true); ok {
+		return ms
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	// Another goroutine may have created the instance since the shard was unlocked
+	if ms, ok := shard.get(key, true); ok {
+		return ms
+	}
+
+	if m.limits.MaxBytes > 0 && m.instanceBytes > m.limits.MaxBytes {
+		return nil
+	}
+	for m.full() {
+		if m.limits.RejectNewKeys {
+			return nil
+		}
+		m.evict()
+	}
+
+	ms := NewConcurrent(m.opts)
+	shard.mux.Lock()
+	shard.insert(key, ms)
+	shard.mux.Unlock()
+	m.len++
+	m.bytes += m.instanceBytes
+	return ms
+}
+
+// Add adds the given values to the instance for the given key, per Get.
+// It returns false if the key was rejected, per Uint64MapLimits.RejectNewKeys.
+func (m *MovingStatsUint64Map) Add(key uint64, values ...float64) bool {
+	ms := m.Get(key)
+	if ms == nil {
+		return false
+	}
+	ms.Add(values...)
+	return true
+}
+
+// Lookup returns the instance for the given key, and whether there is one,
+// without creating it or marking the key as recently used.
+func (m *MovingStatsUint64Map) Lookup(key uint64) (MovingStats, bool) {
+	return m.shard(key).get(key, false)
+}
+
+// Delete removes the instance for the given key, if any.
+func (m *MovingStatsUint64Map) Delete(key uint64) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	shard := m.shard(key)
+	shard.mux.Lock()
+	defer shard.mux.Unlock()
+	if i, ok := shard.find(key); ok {
+		shard.remove(i)
+		m.len--
+		m.bytes -= m.instanceBytes
+	}
+}
+
+// Len returns the number of keys in the map.
+func (m *MovingStatsUint64Map) Len() int {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.len
+}
+
+// Bytes returns the estimated memory, in bytes, used by the map's keys and instances,
+// as limited by Uint64MapLimits.MaxBytes.
+func (m *MovingStatsUint64Map) Bytes() int {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.bytes
+}
+
+// Keys returns the map's keys, sorted.
+func (m *MovingStatsUint64Map) Keys() []uint64 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	retv := make([]uint64, 0, m.len)
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mux.RLock()
+		for _, s := range shard.slots {
+			if s.ms != nil {
+				retv = append(retv, s.key)
+			}
+		}
+		shard.mux.RUnlock()
+	}
+	slices.Sort(retv)
+	return retv
+}
+
+// shard returns the shard holding the given key, chosen by the high bits of its hash,
+// since the low bits choose its slot in the shard's table.
+func (m *MovingStatsUint64Map) shard(key uint64) *uint64MapShard {
+	return &m.shards[hashUint64(key)>>58%mapShards]
+}
+
+// full returns whether adding an instance would exceed the map's limits.
+func (m *MovingStatsUint64Map) full() bool {
+	return (m.limits.MaxKeys > 0 && m.len+1 > m.limits.MaxKeys) ||
+		(m.limits.MaxBytes > 0 && m.bytes+m.instanceBytes > m.limits.MaxBytes)
+}
+
+// evict evicts the first key reached by the clock hand, which sweeps each shard's table
+// in turn, which hasn't been used since the hand last passed it. The map must not be empty.
+func (m *MovingStatsUint64Map) evict() {
+	for {
+		shard := &m.shards[m.handShard]
+		shard.mux.Lock()
+		key, ms, ok := shard.evict()
+		shard.mux.Unlock()
+		if ok {
+			m.len--
+			m.bytes -= m.instanceBytes
+			if m.limits.OnEvict != nil {
+				m.limits.OnEvict(key, ms)
+			}
+			return
+		}
+		m.handShard = (m.handShard + 1) % mapShards
+	}
+}
+
+// get returns the instance for the given key, and whether there is one, marking the key as
+// used if markUsed is set.
+func (s *uint64MapShard) get(key uint64, markUsed bool) (MovingStats, bool) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	i, ok := s.find(key)
+	if !ok {
+		return nil, false
+	}
+	if markUsed {
+		atomic.StoreUint32(&s.slots[i].used, 1)
+	}
+	return s.slots[i].ms, true
+}
+
+// find returns the index of the slot holding the given key and true, or the index of the
+// empty slot where it would be inserted and false.
+func (s *uint64MapShard) find(key uint64) (int, bool) {
+	mask := len(s.slots) - 1
+	for i := int(hashUint64(key)) & mask; ; i = (i + 1) & mask {
+		switch {
+		case s.slots[i].ms == nil:
+			return i, false
+		case s.slots[i].key == key:
+			return i, true
+		}
+	}
+}
+
+// insert inserts the given key, which must not be in the shard, growing its table if needed.
+func (s *uint64MapShard) insert(key uint64, ms MovingStats) {
+	if (s.len+1)*4 > len(s.slots)*3 {
+		s.grow()
+	}
+	i, _ := s.find(key)
+	s.slots[i] = uint64MapSlot{key: key, ms: ms}
+	s.len++
+}
+
+// evict evicts the first key from the clock hand to the end of the table which hasn't been
+// used since the hand last passed it, and returns it, its instance, and true; or, if there
+// is none, moves the hand back to the start of the table and returns false.
+func (s *uint64MapShard) evict() (uint64, MovingStats, bool) {
+	for ; s.hand < len(s.slots); s.hand++ {
+		slot := &s.slots[s.hand]
+		switch {
+		case slot.ms == nil:
+		case slot.used != 0:
+			slot.used = 0
+		default:
+			key, ms := slot.key, slot.ms
+			// Removing the key may shift another into this slot, so the hand stays put
+			s.remove(s.hand)
+			return key, ms, true
+		}
+	}
+	s.hand = 0
+	return 0, nil, false
+}
+
+// remove empties the slot at index i, shifting back any keys after it which would
+// otherwise no longer be found by linear probing.
+func (s *uint64MapShard) remove(i int) {
+	s.slots[i] = uint64MapSlot{}
+	s.len--
+
+	mask := len(s.slots) - 1
+	for j := (i + 1) & mask; s.slots[j].ms != nil; j = (j + 1) & mask {
+		// A key stays put if its home slot is cyclically in (i, j]
+		home := int(hashUint64(s.slots[j].key)) & mask
+		if (i < j && i < home && home <= j) || (i > j && (i < home || home <= j)) {
+			continue
+		}
+		s.slots[i], s.slots[j] = s.slots[j], uint64MapSlot{}
+		i = j
+	}
+}
+
+// grow doubles the number of slots, reinserting every key.
+func (s *uint64MapShard) grow() {
+	old := s.slots
+	s.slots = make([]uint64MapSlot, 2*len(old))
+	s.hand = 0
+	for _, slot := range old {
+		if slot.ms != nil {
+			i, _ := s.find(slot.key)
+			s.slots[i] = slot
+		}
+	}
+}
+
+// hashUint64 mixes the bits of the given key (per the SplitMix64 finalizer), so that
+// sequential keys don't cluster in the table.
+func hashUint64(key uint64) uint64 {
+	key ^= key >> 30
+	key *= 0xbf58476d1ce4e5b9
+	key ^= key >> 27
+	key *= 0x94d049bb133111eb
+	key ^= key >> 31
+	return key
+}
@@ -0,0 +1,166 @@
+package movingaverage
+
+import (
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestMovingStatsUint64Map(t *testing.T) {
+	m := NewMovingStatsUint64Map(Options{Window: 3}, Uint64MapLimits{})
+	m.Add(1, 1, 2)
+	m.Add(2, 10)
+	m.Get(1).Add(3)
+
+	if a, ok := m.Lookup(1); !ok || a.Avg() != 2 {
+		t.Error(ok, a)
+	}
+	if _, ok := m.Lookup(3); ok {
+		t.Error("expected no instance for 3")
+	}
+	if m.Len() != 2 || !slices.Equal(m.Keys(), []uint64{1, 2}) {
+		t.Error(m.Len(), m.Keys())
+	}
+
+	m.Delete(1)
+	if m.Len() != 1 || m.Bytes() != estimatedInstanceBytes(Options{Window: 3})+uint64MapKeyBytes {
+		t.Error(m.Len(), m.Bytes())
+	}
+}
+
+func TestMovingStatsUint64MapEvicts(t *testing.T) {
+	var evicted []uint64
+	m := NewMovingStatsUint64Map(Options{Window: 3}, Uint64MapLimits{
+		MaxKeys: 2,
+		OnEvict: func(key uint64, _ MovingStats) { evicted = append(evicted, key) },
+	})
+	m.Add(1, 1)
+	m.Add(2, 2)
+	m.Add(1, 3) // 2 hasn't been used since it was added
+	m.Add(3, 4)
+
+	if !slices.Equal(m.Keys(), []uint64{1, 3}) || !slices.Equal(evicted, []uint64{2}) {
+		t.Error(m.Keys(), evicted)
+	}
+}
+
+func TestMovingStatsUint64MapMaxBytes(t *testing.T) {
+	size := estimatedInstanceBytes(Options{Window: 100}) + uint64MapKeyBytes
+	m := NewMovingStatsUint64Map(Options{Window: 100}, Uint64MapLimits{MaxBytes: 3 * size})
+	for i := 0; i < 1000; i++ {
+		m.Add(uint64(i%100), float64(i))
+	}
+	if m.Len() != 3 || m.Bytes() > 3*size {
+		t.Error(m.Len(), m.Bytes())
+	}
+
+	small := NewMovingStatsUint64Map(Options{Window: 100}, Uint64MapLimits{MaxBytes: 10})
+	if small.Get(1) != nil || small.Len() != 0 {
+		t.Error("expected an instance too large for the map to be rejected")
+	}
+}
+
+func TestMovingStatsUint64MapBytes(t *testing.T) {
+	for _, opts := range []Options{{Window: 1}, {Window: 100}} {
+		var m *MovingStatsUint64Map
+		measured := heapBytes(func() {
+			m = NewMovingStatsUint64Map(opts, Uint64MapLimits{})
+			for i := 0; i < 10000; i++ {
+				m.Add(uint64(i), make([]float64, opts.Window)...)
+			}
+		})
+		runtime.KeepAlive(m)
+
+		assertEstimatedBytes(t, m.Bytes(), measured, opts)
+	}
+}
+
+func TestMovingStatsUint64MapRejectNewKeys(t *testing.T) {
+	m := NewMovingStatsUint64Map(Options{Window: 3}, Uint64MapLimits{MaxKeys: 1, RejectNewKeys: true})
+	if !m.Add(1, 1) {
+		t.Error("1 should be accepted")
+	}
+	if m.Add(2, 1) || m.Get(2) != nil {
+		t.Error("2 should be rejected")
+	}
+	if !m.Add(1, 2) || m.Get(1).Count() != 2 {
+		t.Error("1 should still be accepted")
+	}
+}
+
+// TestMovingStatsUint64MapRandom checks the map's table against a Go map through
+// random inserts and deletes, as it grows and as keys are shifted back on deletion.
+func TestMovingStatsUint64MapRandom(t *testing.T) {
+	m := NewMovingStatsUint64Map(Options{Window: 1}, Uint64MapLimits{})
+	want := make(map[uint64]float64)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		key := uint64(r.Intn(2000))
+		if r.Intn(3) == 0 {
+			m.Delete(key)
+			delete(want, key)
+			continue
+		}
+		m.Add(key, float64(i))
+		want[key] = float64(i)
+	}
+
+	if m.Len() != len(want) || len(m.Keys()) != len(want) {
+		t.Fatal(m.Len(), len(m.Keys()), len(want))
+	}
+	for key, v := range want {
+		if ms, ok := m.Lookup(key); !ok || ms.Avg() != v {
+			t.Fatal(key, ok, v)
+		}
+	}
+}
+
+func TestMovingStatsUint64MapConcurrent(t *testing.T) {
+	m := NewMovingStatsUint64Map(Options{Window: 10}, Uint64MapLimits{MaxKeys: 50})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Add(uint64(i%100), float64(i))
+				m.Delete(uint64(i % 7))
+			}
+		}()
+	}
+	wg.Wait()
+	if m.Len() > 50 {
+		t.Error(m.Len())
+	}
+}
+
+// TestMovingStatsUint64MapLocking checks that getting existing keys doesn't wait on the
+// map-wide lock, and that holding one instance's lock doesn't block adding to other keys.
+func TestMovingStatsUint64MapLocking(t *testing.T) {
+	m := NewMovingStatsUint64Map(Options{Window: 10}, Uint64MapLimits{})
+	m.Add(1, 1)
+
+	m.mux.Lock()
+	done := make(chan struct{})
+	go func() {
+		m.Get(1).Add(2)
+		close(done)
+	}()
+	<-done
+	m.mux.Unlock()
+
+	m.Get(1).DoLocked(func(ReadOnlyView) {
+		for key := uint64(2); key < 1000; key++ {
+			m.Add(key, float64(key))
+		}
+	})
+	values, release := m.Get(1).BorrowValues()
+	m.Add(1000, 1)
+	release()
+
+	if m.Len() != 1000 || len(values) != 2 {
+		t.Error(m.Len(), values)
+	}
+}
@@ -0,0 +1,206 @@
+package movingaverage
+
+import (
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMovingStatsMap(t *testing.T) {
+	m := NewMovingStatsMap(Options{Window: 3}, MapLimits{})
+	m.Add("a", 1, 2)
+	m.Add("b", 10)
+	m.Get("a").Add(3)
+
+	if a, ok := m.Lookup("a"); !ok || a.Avg() != 2 {
+		t.Error(ok, a)
+	}
+	if _, ok := m.Lookup("c"); ok {
+		t.Error("expected no instance for c")
+	}
+	if m.Len() != 2 || !slices.Equal(m.Keys(), []string{"a", "b"}) {
+		t.Error(m.Len(), m.Keys())
+	}
+
+	m.Delete("a")
+	if m.Len() != 1 || m.Bytes() != estimatedInstanceBytes(Options{Window: 3})+1 {
+		t.Error(m.Len(), m.Bytes())
+	}
+}
+
+func TestMovingStatsMapEvictsLRU(t *testing.T) {
+	var evicted []string
+	m := NewMovingStatsMap(Options{Window: 3}, MapLimits{
+		MaxKeys: 2,
+		OnEvict: func(key string, _ MovingStats) { evicted = append(evicted, key) },
+	})
+	m.Add("a", 1)
+	m.Add("b", 2)
+	m.Add("a", 3) // b is now the least recently used
+	m.Add("c", 4)
+
+	if !slices.Equal(m.Keys(), []string{"a", "c"}) || !slices.Equal(evicted, []string{"b"}) {
+		t.Error(m.Keys(), evicted)
+	}
+
+	// Lookup doesn't mark a key as used
+	m.Lookup("a")
+	m.Add("d", 5)
+	if !slices.Equal(m.Keys(), []string{"c", "d"}) {
+		t.Error(m.Keys())
+	}
+}
+
+func TestMovingStatsMapMaxBytes(t *testing.T) {
+	size := estimatedInstanceBytes(Options{Window: 100}) + len("k99")
+	m := NewMovingStatsMap(Options{Window: 100}, MapLimits{MaxBytes: 3 * size})
+	for i := 0; i < 1000; i++ {
+		m.Add("k"+strconv.Itoa(i%100), float64(i))
+	}
+	if m.Len() != 3 || m.Bytes() > 3*size {
+		t.Error(m.Len(), m.Bytes())
+	}
+
+	small := NewMovingStatsMap(Options{Window: 100}, MapLimits{MaxBytes: 10})
+	if small.Get("a") != nil || small.Len() != 0 {
+		t.Error("expected an instance too large for the map to be rejected")
+	}
+}
+
+func TestMovingStatsMapRejectNewKeys(t *testing.T) {
+	m := NewMovingStatsMap(Options{Window: 3}, MapLimits{MaxKeys: 1, RejectNewKeys: true})
+	if !m.Add("a", 1) {
+		t.Error("a should be accepted")
+	}
+	if m.Add("b", 1) || m.Get("b") != nil {
+		t.Error("b should be rejected")
+	}
+	if !m.Add("a", 2) || m.Get("a").Count() != 2 {
+		t.Error("a should still be accepted")
+	}
+}
+
+func TestMovingStatsMapConcurrent(t *testing.T) {
+	m := NewMovingStatsMap(Options{Window: 10}, MapLimits{MaxKeys: 50})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Add(strconv.Itoa(i%100), float64(i))
+			}
+		}()
+	}
+	wg.Wait()
+	if m.Len() != 50 {
+		t.Error(m.Len())
+	}
+}
+
+// TestMovingStatsMapEvictsLRUAcrossShards checks that eviction stays in exact LRU order
+// although keys on different shards are marked as used without the map-wide lock.
+func TestMovingStatsMapEvictsLRUAcrossShards(t *testing.T) {
+	var evicted []string
+	m := NewMovingStatsMap(Options{Window: 1}, MapLimits{
+		MaxKeys: 100,
+		OnEvict: func(key string, _ MovingStats) { evicted = append(evicted, key) },
+	})
+	for i := 0; i < 100; i++ {
+		m.Add(strconv.Itoa(i), 1)
+	}
+	// use the keys in another order: odd keys, descending, then even keys, ascending
+	var order []string
+	for i := 99; i > 0; i -= 2 {
+		order = append(order, strconv.Itoa(i))
+	}
+	for i := 0; i < 100; i += 2 {
+		order = append(order, strconv.Itoa(i))
+	}
+	for _, key := range order {
+		m.Get(key)
+	}
+
+	for i := 100; i < 200; i++ {
+		m.Add(strconv.Itoa(i), 1)
+	}
+	if !slices.Equal(evicted, order) {
+		t.Error(evicted)
+	}
+}
+
+func TestMovingStatsMapConcurrentGet(t *testing.T) {
+	m := NewMovingStatsMap(Options{Window: 10}, MapLimits{MaxKeys: 20})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := strconv.Itoa(i % 30)
+				if ms := m.Get(key); ms == nil {
+					t.Error("expected an instance for", key)
+					return
+				}
+				if i%50 == 0 {
+					m.Delete(key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if m.Len() > 20 || len(m.Keys()) != m.Len() {
+		t.Error(m.Len(), m.Keys())
+	}
+}
+
+func TestEstimatedInstanceBytes(t *testing.T) {
+	for _, opts := range []Options{
+		{Window: 1},
+		{Window: 10},
+		{Window: 100},
+		{Window: 1000},
+		{Window: 100, MaxAge: time.Hour},
+		{Window: 100, TrackTrend: true},
+	} {
+		const n = 1000
+		instances := make([]MovingStats, n)
+		measured := heapBytes(func() {
+			for i := range instances {
+				instances[i] = NewConcurrent(opts)
+				for j := 0; j < 2*opts.Window; j++ {
+					instances[i].Add(float64(j))
+				}
+			}
+		}) / n
+		runtime.KeepAlive(instances)
+
+		assertEstimatedBytes(t, estimatedInstanceBytes(opts), measured, opts)
+	}
+}
+
+// heapBytes returns the growth of the heap, in bytes, over a call to f, which must keep
+// what it allocates reachable.
+func heapBytes(f func()) int {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	return int(after.HeapAlloc) - int(before.HeapAlloc)
+}
+
+// assertEstimatedBytes fails the test if an estimate of the memory used by an instance
+// created with the given options is more than 15% off the measured memory. (Allocations
+// are rounded up to size classes, which the estimates ignore.)
+func assertEstimatedBytes(t *testing.T, estimated, measured int, opts Options) {
+	t.Helper()
+	if float64(estimated) < 0.85*float64(measured) || float64(estimated) > 1.15*float64(measured) {
+		t.Errorf("Window %d, MaxAge %v, TrackTrend %v: estimated %d bytes, measured %d",
+			opts.Window, opts.MaxAge, opts.TrackTrend, estimated, measured)
+	}
+}
@@ -0,0 +1,182 @@
+package movingaverage
+
+import (
+	"math"
+
+	"github.com/montanaflynn/stats"
+)
+
+// runningSum is the sum of an instance's values, updated incrementally as values are added
+// and evicted. Finite values are summed with Neumaier's compensated summation; NaN and
+// infinite values are counted instead, so the sum recovers once they are evicted.
+// To bound the error accumulated by subtracting evicted values, the sum should be
+// recalculated via reset once evictions reaches the window size.
+type runningSum struct {
+	sum, comp float64
+	nan       int
+	posInf    int
+	negInf    int
+	evictions int
+}
+
+func (s *runningSum) add(v float64) {
+	switch {
+	case math.IsNaN(v):
+		s.nan++
+	case math.IsInf(v, 1):
+		s.posInf++
+	case math.IsInf(v, -1):
+		s.negInf++
+	default:
+		s.accumulate(v)
+	}
+}
+
+func (s *runningSum) remove(v float64) {
+	s.evictions++
+	switch {
+	case math.IsNaN(v):
+		s.nan--
+	case math.IsInf(v, 1):
+		s.posInf--
+	case math.IsInf(v, -1):
+		s.negInf--
+	default:
+		s.accumulate(-v)
+	}
+}
+
+func (s *runningSum) accumulate(v float64) {
+	t := s.sum + v
+	if math.Abs(s.sum) >= math.Abs(v) {
+		s.comp += (s.sum - t) + v
+	} else {
+		s.comp += (v - t) + s.sum
+	}
+	s.sum = t
+}
+
+// reset recalculates the sum from the given values.
+func (s *runningSum) reset(values []float64) {
+	*s = runningSum{}
+	for _, v := range values {
+		s.add(v)
+	}
+}
+
+func (s *runningSum) value() float64 {
+	switch {
+	case s.nan > 0 || (s.posInf > 0 && s.negInf > 0):
+		return math.NaN()
+	case s.posInf > 0:
+		return math.Inf(1)
+	case s.negInf > 0:
+		return math.Inf(-1)
+	default:
+		return s.sum + s.comp
+	}
+}
+
+// runningProduct is the product of an instance's values, updated incrementally as values
+// are added and evicted: the sum of their logarithms (of their absolute values), from which
+// zero and infinite values are counted, as by runningSum, and the number of them which are
+// negative.
+type runningProduct struct {
+	logs     runningSum
+	negative int
+}
+
+func (p *runningProduct) add(v float64) {
+	p.logs.add(math.Log(math.Abs(v)))
+	if v < 0 {
+		p.negative++
+	}
+}
+
+func (p *runningProduct) remove(v float64) {
+	p.logs.remove(math.Log(math.Abs(v)))
+	if v < 0 {
+		p.negative--
+	}
+}
+
+// reset recalculates the product from the given values.
+func (p *runningProduct) reset(values []float64) {
+	*p = runningProduct{}
+	for _, v := range values {
+		p.add(v)
+	}
+}
+
+func (p *runningProduct) value() float64 {
+	retv := math.Exp(p.logs.value())
+	if p.negative%2 == 1 {
+		return -retv
+	}
+	return retv
+}
+
+func (ma *movingStats) Sum() float64 {
+	values := ma.statValues()
+	if len(values) == 0 {
+		return 0.0
+	}
+	if ma.sum == nil {
+		retv, _ := values.Sum()
+		return retv
+	}
+
+	// The running sum includes any values which have expired since the last Add
+	rs := *ma.sum
+	for _, v := range ma.expired(values) {
+		rs.remove(v)
+	}
+	return rs.value()
+}
+
+func (ma *movingStats) SumOfSquares() float64 {
+	values := ma.statValues()
+	if len(values) == 0 {
+		return 0.0
+	}
+	if ma.sumSquares == nil {
+		retv := 0.0
+		for _, v := range values {
+			retv += v * v
+		}
+		return retv
+	}
+
+	rs := *ma.sumSquares
+	for _, v := range ma.expired(values) {
+		rs.remove(v * v)
+	}
+	return rs.value()
+}
+
+func (ma *movingStats) Product() float64 {
+	values := ma.statValues()
+	if len(values) == 0 {
+		return 0.0
+	}
+	var rp runningProduct
+	if ma.product == nil {
+		rp.reset(values)
+		return rp.value()
+	}
+
+	rp = *ma.product
+	for _, v := range ma.expired(values) {
+		rp.remove(v)
+	}
+	return rp.value()
+}
+
+// expired returns the values which have expired since the last Add, given the live values:
+// they're still included in the running sums.
+func (ma *movingStats) expired(values stats.Float64Data) []float64 {
+	if n := ma.values.Len() - len(values); n > 0 {
+		return ma.values.Slice()[:n]
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package movingaverage
+
+import "strconv"
+
+// Summary is a point-in-time summary of the values in a moving stats instance.
+type Summary struct {
+	// The number of values in the window.
+	Count int
+
+	// The average of the values in the window.
+	Avg float64
+
+	// The minimum of the values in the window.
+	Min float64
+
+	// The maximum of the values in the window.
+	Max float64
+}
+
+// Diff returns a Summary holding the differences between s and an earlier Summary,
+// prev (i.e. s - prev, for each field).
+func (s Summary) Diff(prev Summary) Summary {
+	return Summary{
+		Count: s.Count - prev.Count,
+		Avg:   s.Avg - prev.Avg,
+		Min:   s.Min - prev.Min,
+		Max:   s.Max - prev.Max,
+	}
+}
+
+// Format renders the Summary as a human-friendly string with values in the given unit,
+// e.g. "count 3, avg 12.3ms, min 1ms, max 20ms".
+func (s Summary) Format(unit Unit) string {
+	if s.Count == 0 {
+		return "count 0"
+	}
+	return "count " + strconv.Itoa(s.Count) +
+		", avg " + unit.Format(s.Avg) +
+		", min " + unit.Format(s.Min) +
+		", max " + unit.Format(s.Max)
+}
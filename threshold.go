@@ -0,0 +1,147 @@
+package movingaverage
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// AdaptiveThreshold derives dynamic lower and upper bounds from the values in a MovingStats
+// instance, either mean ± k·stddev or a pair of percentiles, and smooths the bounds themselves
+// so they don't jump with every update. Autoscalers and alerting can consume the bounds
+// directly, e.g. to flag values outside the window's normal range.
+//
+// The bounds are recalculated by Update, which can be called every interval by Run.
+//
+// AdaptiveThreshold is safe for concurrent use by multiple goroutines, provided the
+// MovingStats instance it reads is (i.e. it was created by NewConcurrent).
+type AdaptiveThreshold struct {
+	ms           MovingStats
+	k            float64 // if >= 0, bounds are mean ± k·stddev
+	lowerP       float64
+	upperP       float64
+	alpha        float64
+	lower, upper float64
+	started      bool
+	mux          sync.RWMutex
+}
+
+// NewStdDevThreshold returns a new AdaptiveThreshold whose bounds are the mean of the values
+// in ms ± k standard deviations, per the instance's Options.VarianceEstimator.
+//
+// Each update moves the bounds by alpha, from 0 to 1, of the distance to their newly
+// calculated values: lower values smooth the bounds more. If alpha is not in (0, 1],
+// the bounds are not smoothed.
+func NewStdDevThreshold(ms MovingStats, k, alpha float64) *AdaptiveThreshold {
+	return &AdaptiveThreshold{
+		ms:    ms,
+		k:     max(k, 0),
+		alpha: alpha,
+	}
+}
+
+// NewQuantileThreshold returns a new AdaptiveThreshold whose bounds are the lowerP and upperP
+// percentiles, from 0 to 100, of the values in ms, per the instance's Options.QuantileInterpolation.
+// The bounds are smoothed per alpha, as for NewStdDevThreshold.
+func NewQuantileThreshold(ms MovingStats, lowerP, upperP, alpha float64) *AdaptiveThreshold {
+	return &AdaptiveThreshold{
+		ms:     ms,
+		k:      -1,
+		lowerP: lowerP,
+		upperP: upperP,
+		alpha:  alpha,
+	}
+}
+
+// Update recalculates the bounds from the values in the MovingStats instance, smooths them,
+// and returns the smoothed bounds. The first update with values in the window sets the bounds
+// without smoothing. The bounds are calculated by the instance's Avg and StdDev, or its
+// Percentile, so they honor its Options. If the window holds fewer than Options.MinSamples
+// values (or none), or a percentile is out of range, the bounds are unchanged.
+func (a *AdaptiveThreshold) Update() (lower, upper float64) {
+	var rawLower, rawUpper float64
+	ok := false
+	a.ms.DoLocked(func(view ReadOnlyView) {
+		if !hasStatValues(view) {
+			return
+		}
+		if a.k >= 0 {
+			mean, sd := view.Avg(), view.StdDev()
+			rawLower, rawUpper = mean-a.k*sd, mean+a.k*sd
+			ok = true
+			return
+		}
+		var lowerOK, upperOK bool
+		rawLower, lowerOK = viewPercentile(view, a.lowerP)
+		rawUpper, upperOK = viewPercentile(view, a.upperP)
+		ok = lowerOK && upperOK
+	})
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if !ok {
+		return a.lower, a.upper
+	}
+	if !a.started || a.alpha <= 0 || a.alpha > 1 {
+		a.started = true
+		a.lower, a.upper = rawLower, rawUpper
+	} else {
+		a.lower += a.alpha * (rawLower - a.lower)
+		a.upper += a.alpha * (rawUpper - a.upper)
+	}
+	return a.lower, a.upper
+}
+
+// Bounds returns the bounds as of the last Update. Before the first Update with values
+// in the window, they are 0.0.
+func (a *AdaptiveThreshold) Bounds() (lower, upper float64) {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+	return a.lower, a.upper
+}
+
+// Outside returns whether v is below the lower bound or above the upper bound,
+// as of the last Update. Before the first Update with values in the window, it returns false.
+func (a *AdaptiveThreshold) Outside(v float64) bool {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+	return a.started && (v < a.lower || v > a.upper)
+}
+
+// Run calls Update every interval until the given context is canceled.
+// It returns the context's error.
+func (a *AdaptiveThreshold) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			a.Update()
+		}
+	}
+}
+
+// hasStatValues returns whether view holds enough values to calculate statistics from,
+// i.e. at least Options.MinSamples of them, and at least one.
+func hasStatValues(view ReadOnlyView) bool {
+	if ma, ok := view.(*movingStats); ok {
+		return len(ma.statValues()) > 0
+	}
+	return view.Count() > 0
+}
+
+// viewPercentile returns the given percentile, from 0 to 100, of the values in view, and
+// false if it is out of range. The 0th percentile is the minimum, which Percentile rejects.
+func viewPercentile(view ReadOnlyView, p float64) (float64, bool) {
+	switch {
+	case !(p >= 0 && p <= 100):
+		return math.NaN(), false
+	case p == 0:
+		return view.Min(), true
+	default:
+		return view.Percentile(p), true
+	}
+}
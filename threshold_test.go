@@ -0,0 +1,103 @@
+package movingaverage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStdDevThreshold(t *testing.T) {
+	ms := New(Options{Window: 4})
+	a := NewStdDevThreshold(ms, 2, 0.5)
+
+	if lower, upper := a.Update(); lower != 0 || upper != 0 || a.Outside(100) {
+		t.Error(lower, upper)
+	}
+
+	ms.Add(2, 4, 4, 6) // mean 4, population stddev √2
+	lower, upper := a.Update()
+	if !approxEqual(lower, 4-2*1.4142135623730951) || !approxEqual(upper, 4+2*1.4142135623730951) {
+		t.Error(lower, upper)
+	}
+	if !a.Outside(7) || a.Outside(5) {
+		t.Error("Outside")
+	}
+
+	// the bounds move halfway to the new mean ± 2·stddev, 14 ± 2√2
+	ms.Add(12, 14, 14, 16)
+	lower, upper = a.Update()
+	if !approxEqual(lower, 9-2*1.4142135623730951) || !approxEqual(upper, 9+2*1.4142135623730951) {
+		t.Error(lower, upper)
+	}
+	if l, u := a.Bounds(); l != lower || u != upper {
+		t.Error(l, u)
+	}
+}
+
+func TestQuantileThreshold(t *testing.T) {
+	ms := NewConcurrent(Options{Window: 101})
+	for i := 0; i <= 100; i++ {
+		ms.Add(float64(i))
+	}
+	a := NewQuantileThreshold(ms, 5, 95, 0)
+	if lower, upper := a.Update(); lower != 5 || upper != 95 {
+		t.Error(lower, upper)
+	}
+
+	ms.Add(1000)
+	if lower, upper := a.Update(); lower != 6 || upper != 96 {
+		t.Error(lower, upper)
+	}
+}
+
+func TestAdaptiveThresholdOptions(t *testing.T) {
+	ms := NewConcurrent(Options{Window: 4, MinSamples: 3, VarianceEstimator: VarianceSample, QuantileInterpolation: QuantileLower})
+	sd := NewStdDevThreshold(ms, 1, 0)
+	q := NewQuantileThreshold(ms, 0, 50, 0)
+
+	// below MinSamples, the bounds are unchanged
+	ms.Add(2, 4)
+	if lower, upper := sd.Update(); lower != 0 || upper != 0 || sd.Outside(100) {
+		t.Error(lower, upper)
+	}
+	if lower, upper := q.Update(); lower != 0 || upper != 0 || q.Outside(100) {
+		t.Error(lower, upper)
+	}
+
+	ms.Add(4, 6) // mean 4, sample stddev √(8/3)
+	if lower, upper := sd.Update(); !approxEqual(lower, 4-1.632993161855452) || !approxEqual(upper, 4+1.632993161855452) {
+		t.Error(lower, upper)
+	}
+	if lower, upper := q.Update(); lower != 2 || upper != 4 {
+		t.Error(lower, upper)
+	}
+
+	// out of range percentiles leave the bounds unchanged
+	if lower, upper := NewQuantileThreshold(ms, 5, 101, 0).Update(); lower != 0 || upper != 0 {
+		t.Error(lower, upper)
+	}
+}
+
+func TestAdaptiveThresholdRun(t *testing.T) {
+	ms := NewConcurrent(Options{Window: 2})
+	ms.Add(1)
+	a := NewStdDevThreshold(ms, 1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- a.Run(ctx, time.Millisecond) }()
+
+	for {
+		if lower, _ := a.Bounds(); lower == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Error(err)
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
@@ -0,0 +1,69 @@
+package movingaverage
+
+import (
+	"container/heap"
+	"math"
+	"slices"
+
+	"github.com/montanaflynn/stats"
+)
+
+func (ma *movingStats) TopK(k int) stats.Float64Data {
+	return extremes(ma.filledValues(), k, func(a, b float64) bool { return a > b })
+}
+
+func (ma *movingStats) BottomK(k int) stats.Float64Data {
+	return extremes(ma.filledValues(), k, func(a, b float64) bool { return a < b })
+}
+
+// extremes returns the k values which come first in the order defined by before (e.g. the k
+// largest values, if before is >), in that order, without sorting all of the values: a heap
+// holds the k best values seen so far, with the worst of them on top, so selection is O(n log k).
+// NaN values are never selected.
+func extremes(values stats.Float64Data, k int, before func(a, b float64) bool) stats.Float64Data {
+	k = min(k, len(values))
+	if k <= 0 {
+		return stats.Float64Data{}
+	}
+
+	h := &extremesHeap{values: make([]float64, 0, k), before: before}
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if len(h.values) < k {
+			heap.Push(h, v)
+		} else if before(v, h.values[0]) {
+			h.values[0] = v
+			heap.Fix(h, 0)
+		}
+	}
+
+	retv := stats.Float64Data(h.values)
+	slices.SortFunc(retv, func(a, b float64) int {
+		switch {
+		case before(a, b):
+			return -1
+		case before(b, a):
+			return 1
+		}
+		return 0
+	})
+	return retv
+}
+
+// extremesHeap is a heap whose top is the value which comes last in the order defined by before.
+type extremesHeap struct {
+	values []float64
+	before func(a, b float64) bool
+}
+
+func (h *extremesHeap) Len() int           { return len(h.values) }
+func (h *extremesHeap) Less(i, j int) bool { return h.before(h.values[j], h.values[i]) }
+func (h *extremesHeap) Swap(i, j int)      { h.values[i], h.values[j] = h.values[j], h.values[i] }
+func (h *extremesHeap) Push(x any)         { h.values = append(h.values, x.(float64)) }
+func (h *extremesHeap) Pop() any {
+	v := h.values[len(h.values)-1]
+	h.values = h.values[:len(h.values)-1]
+	return v
+}
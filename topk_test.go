@@ -0,0 +1,44 @@
+package movingaverage
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/montanaflynn/stats"
+)
+
+func TestTopK(t *testing.T) {
+	a := NewConcurrent(Options{Window: 6})
+	if got := a.TopK(3); len(got) != 0 {
+		t.Error(got)
+	}
+
+	a.Add(100, 5, 1, 9, math.NaN(), 3, 7, 9)
+	for k, want := range map[int]stats.Float64Data{
+		-1: {},
+		0:  {},
+		1:  {9},
+		3:  {9, 9, 7},
+		10: {9, 9, 7, 3, 1},
+	} {
+		if got := a.TopK(k); !slices.Equal(got, want) {
+			t.Error(k, got)
+		}
+	}
+}
+
+func TestBottomK(t *testing.T) {
+	a := New(Options{Window: 6})
+	a.Add(-100, 5, 1, 9, math.NaN(), 3, 7, 1)
+	for k, want := range map[int]stats.Float64Data{
+		0:  {},
+		1:  {1},
+		3:  {1, 1, 3},
+		10: {1, 1, 3, 7, 9},
+	} {
+		if got := a.BottomK(k); !slices.Equal(got, want) {
+			t.Error(k, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package movingaverage
+
+import (
+	"math"
+
+	"github.com/cdzombak/golang-moving-average/ringbuf"
+)
+
+// previousWindow holds the values most recently evicted from an instance, up to its window
+// size: the window which preceded its current one. Their sum is maintained incrementally.
+type previousWindow struct {
+	values *ringbuf.Ring[float64]
+	sum    runningSum
+}
+
+func newPreviousWindow(window int) *previousWindow {
+	return &previousWindow{values: ringbuf.New[float64](window)}
+}
+
+func (p *previousWindow) add(v float64) {
+	p.sum.add(v)
+	if evicted, ok := p.values.Push(v); ok {
+		p.sum.remove(evicted)
+	}
+	if p.sum.evictions >= p.values.Cap() {
+		p.sum.reset(p.values.Slice())
+	}
+}
+
+func (p *previousWindow) reset() {
+	p.values.Reset()
+	p.sum = runningSum{}
+}
+
+// mean returns the mean of the values in the previous window, and false if it is empty.
+func (p *previousWindow) mean() (float64, bool) {
+	if p.values.Len() == 0 {
+		return 0.0, false
+	}
+	return p.sum.value() / float64(p.values.Len()), true
+}
+
+func (ma *movingStats) TrendPct() float64 {
+	if ma.previous == nil {
+		return 0.0
+	}
+	prev, ok := ma.previous.mean()
+	if !ok || prev == 0 {
+		return 0.0
+	}
+	current, err := ma.statValues().Mean()
+	if err != nil {
+		return 0.0
+	}
+	return (current - prev) / math.Abs(prev) * 100
+}
@@ -0,0 +1,78 @@
+package movingaverage
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Unit declares the unit of the values in a moving stats instance, used to render
+// them in human-friendly form.
+type Unit string
+
+const (
+	// UnitNone formats values as plain numbers.
+	UnitNone Unit = ""
+	// UnitSeconds formats values as durations, e.g. 0.0123 as "12.3ms".
+	UnitSeconds Unit = "seconds"
+	// UnitNanoseconds formats values as durations, e.g. 12300000 as "12.3ms".
+	// DurationStats windows use this unit unless another is given.
+	UnitNanoseconds Unit = "nanoseconds"
+	// UnitBytes formats values as binary byte sizes, e.g. 1288490189 as "1.2GiB".
+	UnitBytes Unit = "bytes"
+	// UnitPercent formats values as percentages, e.g. 12.34 as "12.3%".
+	UnitPercent Unit = "percent"
+)
+
+// Format renders the given value in the unit, rounded to one decimal place
+// (or six significant digits, for UnitNone).
+func (u Unit) Format(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	switch u {
+	case UnitSeconds:
+		return formatDuration(v * float64(time.Second))
+	case UnitNanoseconds:
+		return formatDuration(v)
+	case UnitBytes:
+		return formatBytes(v)
+	case UnitPercent:
+		return formatDecimal(v) + "%"
+	default:
+		return strconv.FormatFloat(v, 'g', 6, 64)
+	}
+}
+
+// formatDuration renders the given number of nanoseconds as a time.Duration,
+// rounded to one decimal place of its largest unit.
+func formatDuration(ns float64) string {
+	if math.Abs(ns) >= math.MaxInt64 {
+		return strconv.FormatFloat(ns/float64(time.Second), 'g', 6, 64) + "s"
+	}
+	d := time.Duration(ns)
+	switch abs := d.Abs(); {
+	case abs >= time.Second:
+		d = d.Round(100 * time.Millisecond)
+	case abs >= time.Millisecond:
+		d = d.Round(100 * time.Microsecond)
+	case abs >= time.Microsecond:
+		d = d.Round(100 * time.Nanosecond)
+	}
+	return d.String()
+}
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+func formatBytes(v float64) string {
+	i := 0
+	for ; math.Abs(v) >= 1024 && i < len(byteUnits)-1; i++ {
+		v /= 1024
+	}
+	return formatDecimal(v) + byteUnits[i]
+}
+
+// formatDecimal renders v rounded to one decimal place, without trailing zeros.
+func formatDecimal(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
@@ -0,0 +1,52 @@
+package movingaverage
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestUnitFormat(t *testing.T) {
+	for _, tc := range []struct {
+		unit Unit
+		v    float64
+		want string
+	}{
+		{UnitNone, 2.0 / 3, "0.666667"},
+		{UnitSeconds, 0.01234, "12.3ms"},
+		{UnitSeconds, 90, "1m30s"},
+		{UnitNanoseconds, 1500, "1.5µs"},
+		{UnitNanoseconds, 12, "12ns"},
+		{UnitBytes, 512, "512B"},
+		{UnitBytes, 1.2 * (1 << 30), "1.2GiB"},
+		{UnitBytes, -2048, "-2KiB"},
+		{UnitPercent, 12.34, "12.3%"},
+		{UnitSeconds, math.NaN(), "NaN"},
+		{UnitBytes, math.Inf(1), "+Inf"},
+	} {
+		if got := tc.unit.Format(tc.v); got != tc.want {
+			t.Errorf("%q.Format(%v) = %q, want %q", tc.unit, tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestFormatSummary(t *testing.T) {
+	a := NewConcurrent(Options{Window: 3, Unit: UnitBytes})
+	if a.FormatSummary() != "count 0" {
+		t.Error(a.FormatSummary())
+	}
+	a.Add(1024, 2048, 3072)
+	if a.Unit() != UnitBytes {
+		t.Error(a.Unit())
+	}
+	if want := "count 3, avg 2KiB, min 1KiB, max 3KiB"; a.FormatSummary() != want || fmt.Sprint(a) != want {
+		t.Error(a.FormatSummary(), fmt.Sprint(a))
+	}
+
+	d := NewDurationStats(Options{Window: 3})
+	d.Observe(time.Millisecond, 3*time.Millisecond)
+	if want := "count 2, avg 2ms, min 1ms, max 3ms"; fmt.Sprint(d.Stats()) != want {
+		t.Error(fmt.Sprint(d.Stats()))
+	}
+}
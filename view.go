@@ -0,0 +1,145 @@
+package movingaverage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+// ReadOnlyView provides the methods of a MovingStats instance which read, but don't modify, it.
+// See MovingStats for documentation of each method.
+type ReadOnlyView interface {
+	Window() int
+	SlotsFilled() bool
+	FillRatio() float64
+	IngestRate() float64
+	OldestAge() time.Duration
+	NewestAge() time.Duration
+	Span() time.Duration
+	Values() stats.Float64Data
+	ValuesWithTimes() []TimedValue
+	ValuesDownsampled(maxPoints int) stats.Float64Data
+	Count() int
+	CountAbove(t float64) int
+	CountBelow(t float64) int
+	Avg() float64
+	Sum() float64
+	SumOfSquares() float64
+	Product() float64
+	SumLast(k int) float64
+	AvgLast(k int) float64
+	TrendPct() float64
+	Median() float64
+	MedianAbsoluteDeviation() float64
+	Percentile(p float64) float64
+	PercentileSummary(ps ...float64) map[float64]float64
+	IQR() float64
+	PercentileRank(x float64) float64
+	CDF(x float64) float64
+	Quantiles() []float64
+	Value() float64
+	EWVariance() float64
+	EWStdDev() float64
+	Min() float64
+	Max() float64
+	MinMax() (min, max float64)
+	Range() float64
+	RMS() float64
+	Variance() float64
+	StdDev() float64
+	CV() float64
+	HealthScore() float64
+	ZScore() float64
+	ZScoreOf(x float64) float64
+	Skewness() float64
+	Kurtosis() float64
+	GeometricMean() float64
+	HarmonicMean() float64
+	TrimmedMean(fraction float64) float64
+	Summary() Summary
+	TopK(k int) stats.Float64Data
+	BottomK(k int) stats.Float64Data
+	Frequencies() map[float64]int
+	Mode() []float64
+	Periodicity() (period int, strength float64)
+	Compute(kinds ...StatKind) map[StatKind]float64
+	LastN(k int) ReadOnlyView
+	Unit() Unit
+	FormatSummary() string
+	Snapshot() Snapshot
+	WriteTo(w io.Writer) (n int64, err error)
+	UnsafeDoStat(func(stats.Float64Data) (float64, error)) (float64, error)
+	UnsafeDo(func(stats.Float64Data) error) error
+	DoStatCtx(ctx context.Context, f func(context.Context, stats.Float64Data) (float64, error)) (float64, error)
+	DoCtx(ctx context.Context, f func(context.Context, stats.Float64Data) error) error
+}
+
+func (ma *movingStats) DoLocked(f func(ReadOnlyView)) {
+	f(ma)
+}
+
+// readOnlyView hides the methods of a view created by LastN which aren't in ReadOnlyView.
+type readOnlyView struct {
+	ReadOnlyView
+}
+
+func (ma *movingStats) LastN(k int) ReadOnlyView {
+	return readOnlyView{ma.lastN(k)}
+}
+
+// lastN returns a view of the newest k values in the instance, with the same options.
+func (ma *movingStats) lastN(k int) *movingStats {
+	k = min(max(k, 0), ma.window)
+	view := *ma
+	view.window = k
+	view.values = tailBuffer[float64]{func() buffer[float64] { return ma.values }, k}
+	if ma.times != nil {
+		view.times = tailBuffer[time.Time]{func() buffer[time.Time] { return ma.times }, k}
+	}
+	view.sorted = nil
+	view.aggregators = nil
+	view.ew = nil
+	view.quantiles = nil
+	view.sum = nil
+	view.sumSquares = nil
+	view.product = nil
+	view.previous = nil
+	view.cusum = nil
+	return &view
+}
+
+// tailBuffer is a read-only buffer of the newest k values in another buffer. The other
+// buffer is looked up on each call, since ApplyOptions replaces an instance's buffers.
+type tailBuffer[T any] struct {
+	parent func() buffer[T]
+	k      int
+}
+
+const readOnlyViewMessage = "movingaverage: views created by LastN are read-only"
+
+func (b tailBuffer[T]) Push(T) (T, bool) {
+	panic(readOnlyViewMessage)
+}
+
+func (b tailBuffer[T]) PopOldest() (T, bool) {
+	panic(readOnlyViewMessage)
+}
+
+func (b tailBuffer[T]) Len() int {
+	parent := b.parent()
+	if parent == nil {
+		return 0
+	}
+	return min(parent.Len(), b.k)
+}
+
+func (b tailBuffer[T]) Slice() []T {
+	parent := b.parent()
+	if parent == nil {
+		return nil
+	}
+	values := parent.Slice()
+	return values[len(values)-min(len(values), b.k):]
+}
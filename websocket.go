@@ -0,0 +1,309 @@
+package movingaverage
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is the GUID used to compute the Sec-WebSocket-Accept header, per RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes, per RFC 6455.
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// wsMaxControlPayload is the maximum payload length of a WebSocket control frame.
+const wsMaxControlPayload = 125
+
+// wsCloseMessageTooBig is the close status code for a frame too large to process, per RFC 6455.
+const wsCloseMessageTooBig = 1009
+
+// wsDefaultWriteTimeout is the default WebSocketOptions.WriteTimeout.
+const wsDefaultWriteTimeout = 10 * time.Second
+
+// errWSFrameTooLarge is returned by readFrame for a frame whose payload length has its most
+// significant bit set, which RFC 6455 forbids.
+var errWSFrameTooLarge = errors.New("websocket: frame payload too long")
+
+// WebSocketOptions configures a WebSocket handler created by NewWebSocketHandlerWithOptions.
+type WebSocketOptions struct {
+	// Returns whether to accept a request, given its Origin header. If nil, only requests
+	// with no Origin header (i.e. not from browsers) or whose Origin's host matches the
+	// request's Host are accepted, so other sites' pages can't read the stats.
+	CheckOrigin func(r *http.Request) bool
+
+	// The maximum time to write each frame to a client before giving up on it. If zero,
+	// 10 seconds is used.
+	WriteTimeout time.Duration
+}
+
+// NewWebSocketHandler returns an http.Handler which upgrades requests to WebSocket
+// connections and pushes the given MovingStats instance's Summary to the client as
+// a JSON text message immediately, then every interval, until the client disconnects.
+//
+// Messages have the same format as the lines written by JSONEmitter (without labels):
+//
+//	{"time":"2024-05-01T12:00:00Z","count":3,"avg":2,"min":1,"max":3}
+//
+// Messages sent by the client are ignored, apart from ping and close control frames.
+// Requests from other origins are rejected, per WebSocketOptions.CheckOrigin.
+//
+// The interval must be positive; if it isn't, requests fail with a 500 error.
+func NewWebSocketHandler(ms MovingStats, interval time.Duration) http.Handler {
+	return NewWebSocketHandlerWithOptions(ms, interval, WebSocketOptions{})
+}
+
+// NewWebSocketHandlerWithOptions returns a WebSocket handler like NewWebSocketHandler,
+// configured by the given options.
+func NewWebSocketHandlerWithOptions(ms MovingStats, interval time.Duration, opts WebSocketOptions) http.Handler {
+	if opts.CheckOrigin == nil {
+		opts.CheckOrigin = sameOrigin
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = wsDefaultWriteTimeout
+	}
+	return &webSocketHandler{
+		ms:       ms,
+		interval: interval,
+		opts:     opts,
+		now:      time.Now,
+	}
+}
+
+type webSocketHandler struct {
+	ms       MovingStats
+	interval time.Duration
+	opts     WebSocketOptions
+	now      func() time.Time
+}
+
+func (h *webSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet ||
+		!headerContainsToken(r.Header, "Connection", "upgrade") ||
+		!headerContainsToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "expected a WebSocket upgrade request", http.StatusBadRequest)
+		return
+	}
+	if !h.opts.CheckOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return
+	}
+	if h.interval <= 0 {
+		http.Error(w, errNonPositiveInterval.Error(), http.StatusInternalServerError)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket upgrade not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	accept := sha1.Sum([]byte(key + websocketGUID))
+	_ = conn.SetWriteDeadline(time.Now().Add(h.opts.WriteTimeout))
+	_, err = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n")
+	if err == nil {
+		err = brw.Flush()
+	}
+	if err != nil {
+		return
+	}
+
+	ws := &wsConn{conn: conn, writeTimeout: h.opts.WriteTimeout}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ws.readLoop(brw.Reader)
+	}()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		if err := ws.writeFrame(wsOpText, h.message()); err != nil {
+			return
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *webSocketHandler) message() []byte {
+	msg, _ := json.Marshal(newJSONEmitterRecord(h.now(), "", nil, h.ms.Summary()))
+	return msg
+}
+
+// wsConn is a minimal server-side WebSocket connection, sufficient for pushing
+// messages to a client.
+type wsConn struct {
+	conn         net.Conn
+	writeTimeout time.Duration
+	mux          sync.Mutex
+}
+
+// writeFrame writes a single, unfragmented frame with the given opcode and payload,
+// giving up if it takes longer than the connection's write timeout.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout)); err != nil {
+		return err
+	}
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// readLoop reads frames from the client until the connection is closed or an error
+// occurs, replying to ping and close frames and discarding everything else.
+func (c *wsConn) readLoop(r *bufio.Reader) {
+	for {
+		opcode, payload, err := readFrame(r)
+		if errors.Is(err, errWSFrameTooLarge) {
+			_ = c.writeFrame(wsOpClose, binary.BigEndian.AppendUint16(nil, wsCloseMessageTooBig))
+		}
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return
+			}
+		case wsOpClose:
+			_ = c.writeFrame(wsOpClose, payload)
+			return
+		}
+	}
+}
+
+// readFrame reads a single frame from a client, unmasking its payload.
+// Payloads of data frames are discarded, and nil is returned in their place.
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	if header[1]&0x80 == 0 {
+		return 0, nil, errors.New("websocket: client frame is not masked")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+		if length >= 1<<63 {
+			return 0, nil, errWSFrameTooLarge
+		}
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	if opcode < wsOpClose {
+		// Data frames are ignored
+		_, err := io.CopyN(io.Discard, r, int64(length))
+		return opcode, nil, err
+	}
+	if length > wsMaxControlPayload {
+		return 0, nil, errors.New("websocket: control frame payload too long")
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+// sameOrigin returns whether the given request has no Origin header, or one whose host
+// matches the request's Host, compared case-insensitively.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// headerContainsToken returns whether the given comma-separated header contains the given token,
+// compared case-insensitively.
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
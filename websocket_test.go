@@ -0,0 +1,182 @@
+package movingaverage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	ms := New(Options{Window: 3})
+	ms.Add(1, 2, 3)
+	srv := httptest.NewServer(NewWebSocketHandler(ms, 10*time.Millisecond))
+	defer srv.Close()
+
+	conn, r, resp := dialWebSocket(t, srv.URL, "")
+	defer func() { _ = conn.Close() }()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatal(resp.Status)
+	}
+	// example from RFC 6455
+	if resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Error(resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+
+	// two messages, the second pushed after the interval
+	for i := 0; i < 2; i++ {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(r, header); err != nil {
+			t.Fatal(err)
+		}
+		if header[0] != 0x81 {
+			t.Errorf("unexpected frame header %x", header[0])
+		}
+		payload := make([]byte, header[1])
+		if _, err := io.ReadFull(r, payload); err != nil {
+			t.Fatal(err)
+		}
+		var msg struct {
+			Count int     `json:"count"`
+			Avg   float64 `json:"avg"`
+		}
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Count != 3 || msg.Avg != 2 {
+			t.Error(string(payload))
+		}
+	}
+
+	// masked close frame
+	if _, err := conn.Write([]byte{0x88, 0x80, 1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebSocketHandlerRejectsPlainRequests(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewWebSocketHandler(New(Options{Window: 3}), time.Second).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Error(rec.Code)
+	}
+}
+
+func TestWebSocketHandlerOrigin(t *testing.T) {
+	srv := httptest.NewServer(NewWebSocketHandler(New(Options{Window: 3}), time.Second))
+	defer srv.Close()
+
+	for origin, want := range map[string]int{
+		"":                         http.StatusSwitchingProtocols,
+		srv.URL:                    http.StatusSwitchingProtocols,
+		"https://evil.example.com": http.StatusForbidden,
+		"http://%zz":               http.StatusForbidden,
+	} {
+		conn, _, resp := dialWebSocket(t, srv.URL, origin)
+		_ = conn.Close()
+		if resp.StatusCode != want {
+			t.Error(origin, resp.Status)
+		}
+	}
+
+	allowAll := httptest.NewServer(NewWebSocketHandlerWithOptions(New(Options{Window: 3}), time.Second, WebSocketOptions{
+		CheckOrigin: func(*http.Request) bool { return true },
+	}))
+	defer allowAll.Close()
+	conn, _, resp := dialWebSocket(t, allowAll.URL, "https://dashboard.example.com")
+	_ = conn.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Error(resp.Status)
+	}
+}
+
+func TestWebSocketHandlerFrameTooLarge(t *testing.T) {
+	srv := httptest.NewServer(NewWebSocketHandler(New(Options{Window: 3}), time.Hour))
+	defer srv.Close()
+	conn, r, _ := dialWebSocket(t, srv.URL, "")
+	defer func() { _ = conn.Close() }()
+
+	// masked binary frame with a 64-bit payload length of 2^63
+	frame := []byte{0x82, 0xFF, 0x80, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3, 4}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first message, then a close frame with status 1009
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(r, header); err != nil {
+			t.Fatal(err)
+		}
+		payload := make([]byte, header[1])
+		if _, err := io.ReadFull(r, payload); err != nil {
+			t.Fatal(err)
+		}
+		if header[0] == 0x88 {
+			if len(payload) != 2 || binary.BigEndian.Uint16(payload) != 1009 {
+				t.Errorf("unexpected close payload %x", payload)
+			}
+			return
+		}
+	}
+}
+
+func TestWebSocketHandlerInvalidInterval(t *testing.T) {
+	srv := httptest.NewServer(NewWebSocketHandler(New(Options{Window: 3}), 0))
+	defer srv.Close()
+	conn, _, resp := dialWebSocket(t, srv.URL, "")
+	_ = conn.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Error(resp.Status)
+	}
+}
+
+func TestWSConnWriteTimeout(t *testing.T) {
+	server, client := net.Pipe()
+	defer func() { _ = client.Close() }()
+	ws := &wsConn{conn: server, writeTimeout: 10 * time.Millisecond}
+
+	// nothing reads from the client end
+	var netErr net.Error
+	if err := ws.writeFrame(wsOpText, []byte("hello")); !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Error(err)
+	}
+}
+
+// dialWebSocket sends a WebSocket upgrade request to the server at the given URL, with the
+// given Origin header if it isn't empty, and returns the connection, a reader for it, and
+// the server's response.
+func dialWebSocket(t *testing.T, serverURL, origin string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	host := strings.TrimPrefix(serverURL, "http://")
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "GET / HTTP/1.1\r\n" +
+		"Host: " + host + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n"
+	if origin != "" {
+		req += "Origin: " + origin + "\r\n"
+	}
+	if _, err := io.WriteString(conn, req+"\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, r, resp
+}
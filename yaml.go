@@ -0,0 +1,282 @@
+package movingaverage
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a non-blank line of a YAML document, without its indentation or comment.
+type yamlLine struct {
+	number int // 1-based, for errors
+	indent int
+	text   string
+}
+
+// yamlParser parses the subset of YAML used by config files into the values encoding/json
+// would decode the equivalent JSON into: map[string]any, []any, string, bool, float64, and
+// nil. See OptionsFromConfig for the subset supported.
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+// parseYAML parses the given YAML document, returning nil if it is empty.
+func parseYAML(data []byte) (any, error) {
+	p := &yamlParser{}
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		if line == "" || line == "---" {
+			continue
+		}
+		text := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs can't be used for indentation", n+1)
+		}
+		p.lines = append(p.lines, yamlLine{number: n + 1, indent: len(line) - len(text), text: text})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+
+	retv, err := p.node(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.i].number)
+	}
+	return retv, nil
+}
+
+// node parses the block mapping or sequence at the current line, indented by indent.
+func (p *yamlParser) node(indent int) (any, error) {
+	if isYAMLSequenceItem(p.lines[p.i].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+// mapping parses the block mapping at the current line, indented by indent.
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	retv := make(map[string]any)
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && !isYAMLSequenceItem(p.lines[p.i].text) {
+		line := p.lines[p.i]
+		key, value, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a key and value, got %q", line.number, line.text)
+		}
+		k, err := parseYAMLScalar(key)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		name := fmt.Sprint(k)
+		if _, ok := retv[name]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, name)
+		}
+		p.i++
+
+		switch {
+		case value != "":
+			retv[name], err = parseYAMLValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+		case p.i < len(p.lines) && p.lines[p.i].indent > indent:
+			retv[name], err = p.node(p.lines[p.i].indent)
+		case p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLSequenceItem(p.lines[p.i].text):
+			// A sequence may be indented as much as its key
+			retv[name], err = p.sequence(indent)
+		default:
+			retv[name] = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return retv, nil
+}
+
+// sequence parses the block sequence at the current line, indented by indent.
+func (p *yamlParser) sequence(indent int) ([]any, error) {
+	retv := []any{}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLSequenceItem(p.lines[p.i].text) {
+		line := p.lines[p.i]
+		item := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		p.i++
+
+		if item == "" {
+			if p.i < len(p.lines) && p.lines[p.i].indent > indent {
+				value, err := p.node(p.lines[p.i].indent)
+				if err != nil {
+					return nil, err
+				}
+				retv = append(retv, value)
+			} else {
+				retv = append(retv, nil)
+			}
+			continue
+		}
+		if _, _, ok := splitYAMLKey(item); ok {
+			return nil, fmt.Errorf("line %d: mappings in sequences aren't supported", line.number)
+		}
+		value, err := parseYAMLValue(item)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		retv = append(retv, value)
+	}
+	return retv, nil
+}
+
+// parseYAMLValue parses the value of a key or sequence item on a single line: a flow
+// sequence of scalars, or a scalar.
+func parseYAMLValue(s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, "{"):
+		return nil, fmt.Errorf("flow mappings aren't supported")
+	case strings.HasPrefix(s, "&"), strings.HasPrefix(s, "*"), strings.HasPrefix(s, "!"):
+		return nil, fmt.Errorf("anchors, aliases, and tags aren't supported")
+	case strings.HasPrefix(s, "|"), strings.HasPrefix(s, ">"):
+		return nil, fmt.Errorf("multi-line scalars aren't supported")
+	case !strings.HasPrefix(s, "["):
+		return parseYAMLScalar(s)
+	}
+
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("unterminated flow sequence %q", s)
+	}
+	retv := []any{}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	if inner == "" {
+		return retv, nil
+	}
+	for _, item := range splitYAMLOutsideQuotes(inner, ',') {
+		item = strings.TrimSpace(item)
+		if strings.HasPrefix(item, "[") || strings.HasPrefix(item, "{") {
+			return nil, fmt.Errorf("nested flow collections aren't supported")
+		}
+		value, err := parseYAMLScalar(item)
+		if err != nil {
+			return nil, err
+		}
+		retv = append(retv, value)
+	}
+	return retv, nil
+}
+
+// parseYAMLScalar parses a quoted or plain scalar. Plain scalars are resolved per the YAML
+// core schema: null, booleans, and numbers, or else strings.
+func parseYAMLScalar(s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		retv, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", s)
+		}
+		return retv, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if isYAMLNumber(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) {
+			return f, nil
+		}
+	}
+	return s, nil
+}
+
+// isYAMLNumber returns whether the given plain scalar is a decimal number, as opposed to
+// one of the other forms strconv.ParseFloat accepts (e.g. "inf" or "0x1p-2").
+func isYAMLNumber(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if s == "" || !(s[0] >= '0' && s[0] <= '9' || s[0] == '.') {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && c != '.' && c != 'e' && c != 'E' && c != '+' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// isYAMLSequenceItem returns whether the given line is a block sequence item.
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits the given line into a mapping key and value, at the first colon
+// outside quotes which is followed by a space or ends the line.
+func splitYAMLKey(text string) (key, value string, ok bool) {
+	var quote rune
+	for i, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ':' && (i == len(text)-1 || text[i+1] == ' '):
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// splitYAMLOutsideQuotes splits the given string at each sep outside quotes.
+func splitYAMLOutsideQuotes(s string, sep rune) []string {
+	var retv []string
+	var quote rune
+	start := 0
+	for i, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			retv = append(retv, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(retv, s[start:])
+}
+
+// stripYAMLComment removes the comment, if any, from the given line: from a '#' outside
+// quotes which starts the line or follows whitespace.
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}